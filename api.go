@@ -0,0 +1,52 @@
+/*
+Copyright 2016 James DeFelice
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package log
+
+import (
+	"github.com/gologs/log/config"
+	"github.com/gologs/log/levels"
+	"github.com/gologs/log/logapi"
+)
+
+var _ = logapi.Interface(levels.Interface(nil)) // levels.Interface implements logapi.Interface
+
+// api forwards to the logging instance returned by config.Logging at the time of each call
+type api struct{}
+
+func (api) Debugf(msg string, args ...interface{}) { config.Logging().Debugf(msg, args...) }
+func (api) Debug(args ...interface{})              { config.Logging().Debug(args...) }
+func (api) Infof(msg string, args ...interface{})  { config.Logging().Infof(msg, args...) }
+func (api) Info(args ...interface{})               { config.Logging().Info(args...) }
+func (api) Warnf(msg string, args ...interface{})  { config.Logging().Warnf(msg, args...) }
+func (api) Warn(args ...interface{})               { config.Logging().Warn(args...) }
+func (api) Errorf(msg string, args ...interface{}) { config.Logging().Errorf(msg, args...) }
+func (api) Error(args ...interface{})              { config.Logging().Error(args...) }
+func (api) Fatalf(msg string, args ...interface{}) { config.Logging().Fatalf(msg, args...) }
+func (api) Fatal(args ...interface{})              { config.Logging().Fatal(args...) }
+func (api) Panicf(msg string, args ...interface{}) { config.Logging().Panicf(msg, args...) }
+func (api) Panic(args ...interface{})              { config.Logging().Panic(args...) }
+
+// API returns a logapi.Interface, for injection into libraries, that logs like the funcs of this
+// package do: via the logging instance that config.Logging returns at the time of each call, so
+// that subsequent changes to the configuration are observed.
+func API() logapi.Interface { return api{} }
+
+// FromAPI returns the levels.Interface equivalent of a logapi.Interface, or else one that
+// discards all log events if `i` is nil.
+func FromAPI(i logapi.Interface) levels.Interface {
+	return logapi.OrNop(i) // the methods are the same
+}
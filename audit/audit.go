@@ -0,0 +1,200 @@
+/*
+Copyright 2016 James DeFelice
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package audit records security-relevant events (who did what to which resource, and with
+// what outcome) separately from diagnostic log events: audit events are rendered by a dedicated
+// Marshaler and written to a dedicated Stream, but share the context, timestamp, and caller
+// plumbing of the levels engine.
+package audit
+
+import (
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gologs/log/caller"
+	"github.com/gologs/log/context"
+	"github.com/gologs/log/context/requestid"
+	"github.com/gologs/log/context/timestamp"
+	"github.com/gologs/log/diag"
+	"github.com/gologs/log/io"
+	"github.com/gologs/log/logger"
+)
+
+// Outcome describes the result of an audited action.
+type Outcome int
+
+// Outcome values
+const (
+	Success Outcome = iota
+	Failure
+	Denied
+)
+
+var outcomeNames = []string{"success", "failure", "denied"}
+
+func (o Outcome) String() string {
+	if o >= 0 && int(o) < len(outcomeNames) {
+		return outcomeNames[o]
+	}
+	return "outcome(" + strconv.Itoa(int(o)) + ")"
+}
+
+// Event is an audit record.
+type Event struct {
+	Time     time.Time
+	Caller   *caller.Caller // Caller is nil unless call tracking is enabled
+	Actor    string
+	Action   string
+	Resource string
+	Outcome  Outcome
+}
+
+// Interface records audit events. The Actor, Action, and Resource methods return a copy of the
+// receiver that populates the respective fields of subsequent events; Outcome records an event.
+//
+//	audit.Actor(user).Action("delete").Resource(path).Outcome(audit.Denied)
+type Interface interface {
+	Actor(string) Interface
+	Action(string) Interface
+	Resource(string) Interface
+	Outcome(Outcome)
+}
+
+// Marshaler renders an audit Event to a Stream; it's expected to invoke EOM after each event.
+type Marshaler func(context.Context, io.Stream, Event) error
+
+// DefaultCallerDepth is appropriate when invoking the Outcome method of an Interface directly.
+const DefaultCallerDepth = 2
+
+// Config determines how audit events are generated and where they're written.
+type Config struct {
+	Stream       io.Stream        // Stream receives audit events; defaults to io.Null
+	Marshaler    Marshaler        // Marshaler defaults to Text
+	Errors       logger.ErrorSink // Errors defaults to diag.ErrorSink
+	Context      context.Getter   // Context defaults to context.TODO
+	Clock        timestamp.Clock  // Clock defaults to time.Now
+	CallTracking caller.Tracking
+}
+
+type sink struct {
+	sync.Mutex
+	cfg Config
+	ctx context.Getter
+}
+
+type auditor struct {
+	s   *sink
+	d   context.Decorator
+	tpl Event
+}
+
+// New returns an Interface that records audit events as specified by the Config. Audit events
+// are serialized, so the Stream need not be safe for concurrent use.
+func New(cfg Config) Interface {
+	if cfg.Stream == nil {
+		cfg.Stream = io.Null()
+	}
+	if cfg.Marshaler == nil {
+		cfg.Marshaler = Text()
+	}
+	if cfg.Errors == nil {
+		cfg.Errors = diag.ErrorSink()
+	}
+	if cfg.Context == nil {
+		cfg.Context = context.TODO
+	}
+	if cfg.Clock == nil {
+		cfg.Clock = time.Now
+	}
+	return &auditor{s: &sink{
+		cfg: cfg,
+		ctx: context.NewGetter(cfg.Context, timestamp.NewDecorator(cfg.Clock)),
+	}}
+}
+
+// WithContext returns an Interface that applies the given Decorator to the context of each
+// audit event, for example to attach a request ID.
+func WithContext(i Interface, d context.Decorator) Interface {
+	a, ok := i.(*auditor)
+	if !ok || d == nil {
+		return i
+	}
+	a2 := *a
+	if a.d != nil {
+		a2.d = func(c context.Context) context.Context { return d(a.d(c)) }
+	} else {
+		a2.d = d
+	}
+	return &a2
+}
+
+func (a *auditor) Actor(s string) Interface    { a2 := *a; a2.tpl.Actor = s; return &a2 }
+func (a *auditor) Action(s string) Interface   { a2 := *a; a2.tpl.Action = s; return &a2 }
+func (a *auditor) Resource(s string) Interface { a2 := *a; a2.tpl.Resource = s; return &a2 }
+
+func (a *auditor) Outcome(o Outcome) {
+	var (
+		s = a.s
+		c = s.ctx()
+		e = a.tpl
+	)
+	if a.d != nil {
+		c = a.d(c)
+	}
+	c = caller.WithContext(s.cfg.CallTracking)(c)
+	e.Outcome = o
+	e.Time, _ = timestamp.FromContext(c)
+	if x, ok := caller.FromContext(c); ok {
+		e.Caller = &x
+	}
+	s.Lock()
+	err := s.cfg.Marshaler(c, s.cfg.Stream, e)
+	s.Unlock()
+	if err != nil {
+		s.cfg.Errors.LogError(c, logger.Entry{Format: "audit: %s %s %s", Args: []interface{}{e.Actor, e.Action, e.Resource}, Stream: s.cfg.Stream}, err)
+	}
+}
+
+// Text returns a Marshaler that renders audit events as a single line of key=value pairs, with
+// quoted values, for example:
+//
+//	time=2016-01-02T15:04:05Z actor="bob" action="delete" resource="/a" outcome=denied
+func Text() Marshaler {
+	return func(c context.Context, w io.Stream, e Event) error {
+		b := make([]byte, 0, 128)
+		b = append(b, "time="...)
+		b = e.Time.UTC().AppendFormat(b, time.RFC3339Nano)
+		b = append(b, " actor="...)
+		b = strconv.AppendQuote(b, e.Actor)
+		b = append(b, " action="...)
+		b = strconv.AppendQuote(b, e.Action)
+		b = append(b, " resource="...)
+		b = strconv.AppendQuote(b, e.Resource)
+		b = append(b, " outcome="...)
+		b = append(b, e.Outcome.String()...)
+		if id, ok := requestid.FromContext(c); ok {
+			b = append(b, " request_id="...)
+			b = strconv.AppendQuote(b, id)
+		}
+		if e.Caller != nil {
+			b = append(b, " caller="...)
+			b = strconv.AppendQuote(b, e.Caller.File+":"+strconv.Itoa(e.Caller.Line))
+		}
+		_, err := w.Write(b)
+		return w.EOM(err)
+	}
+}
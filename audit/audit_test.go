@@ -0,0 +1,51 @@
+/*
+Copyright 2016 James DeFelice
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package audit_test
+
+import (
+	"bytes"
+	"regexp"
+	"testing"
+	"time"
+
+	. "github.com/gologs/log/audit"
+	"github.com/gologs/log/caller"
+	"github.com/gologs/log/context/requestid"
+	"github.com/gologs/log/io"
+)
+
+func TestText(t *testing.T) {
+	var (
+		buf   bytes.Buffer
+		clock = func() time.Time { return time.Date(2016, 1, 2, 15, 4, 5, 0, time.UTC) }
+		a     = New(Config{
+			Stream:       io.TextStream(&buf),
+			Clock:        clock,
+			CallTracking: caller.Tracking{Enabled: true, Depth: DefaultCallerDepth},
+		})
+		bob = WithContext(a.Actor("bob"), requestid.NewDecorator("1234"))
+	)
+	bob.Action("delete").Resource(`/a "b"`).Outcome(Denied)
+	a.Action("login").Outcome(Success)
+
+	expected := regexp.MustCompile(`^` +
+		`time=2016-01-02T15:04:05Z actor="bob" action="delete" resource="/a \\"b\\"" outcome=denied request_id="1234" caller=".*/audit_test.go:\d+"\n` +
+		`time=2016-01-02T15:04:05Z actor="" action="login" resource="" outcome=success caller=".*/audit_test.go:\d+"\n$`)
+	if !expected.MatchString(buf.String()) {
+		t.Fatalf("unexpected output: %q", buf.String())
+	}
+}
@@ -0,0 +1,273 @@
+/*
+Copyright 2016 James DeFelice
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package log_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gologs/log/caller"
+	"github.com/gologs/log/config"
+	"github.com/gologs/log/context"
+	"github.com/gologs/log/encoding"
+	"github.com/gologs/log/encoding/encodingtest"
+	"github.com/gologs/log/encoding/siem"
+	"github.com/gologs/log/encoding/structured"
+	"github.com/gologs/log/io"
+	"github.com/gologs/log/io/ioutil"
+	"github.com/gologs/log/levels"
+	"github.com/gologs/log/logger"
+)
+
+func nullStream() io.Stream {
+	return &io.BufferedStream{EOMFunc: func(_ io.Buffer, err error) error { return err }}
+}
+
+func benchmarkInterface(b *testing.B, log levels.Interface) {
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		log.Infof("hello %s", "world")
+	}
+}
+
+func BenchmarkConfig_Stream(b *testing.B) {
+	benchmarkInterface(b, config.DefaultConfig.With(
+		config.Stream(nullStream()),
+		config.CallTracking(caller.Tracking{}),
+	))
+}
+
+func BenchmarkConfig_StreamWithCaller(b *testing.B) {
+	benchmarkInterface(b, config.DefaultConfig.With(
+		config.Stream(nullStream()),
+	))
+}
+
+func BenchmarkConfig_StreamWithGlogHeader(b *testing.B) {
+	benchmarkInterface(b, config.DefaultConfig.With(
+		config.Stream(nullStream()),
+		config.CallTracking(caller.Tracking{}),
+		config.Encoding(ioutil.GlogHeader()),
+	))
+}
+
+func BenchmarkConfig_Logger(b *testing.B) {
+	benchmarkInterface(b, config.DefaultConfig.With(
+		config.Logger(logger.Null()),
+		config.CallTracking(caller.Tracking{}),
+	))
+}
+
+// BenchmarkSinks contrasts the Logger- and Stream-oriented sink paths, with and without caller
+// tracking and a message prefix; its results inform the defaults of config.Production.
+func BenchmarkSinks(b *testing.B) {
+	tracking := map[bool]caller.Tracking{
+		false: {},
+		true:  {Enabled: true, Depth: config.DefaultCallerDepth},
+	}
+	sinks := []struct {
+		name  string
+		build func(t caller.Tracking, prefix bool) levels.Interface
+	}{
+		{"Logger", func(t caller.Tracking, prefix bool) levels.Interface {
+			logs := logger.Null()
+			if prefix {
+				logs = logger.WithPrefix("[db] ")(logs)
+			}
+			return config.LeveledLogger(nil, nil, logs, nil, t)
+		}},
+		{"Stream", func(t caller.Tracking, prefix bool) levels.Interface {
+			m := encoding.Format()
+			if prefix {
+				m = encoding.Format(ioutil.String("[db] "))
+			}
+			return config.LeveledStreamer(nil, nil, nullStream(), m, nil, t, logger.IgnoreErrors(), nil)
+		}},
+	}
+	for _, sink := range sinks {
+		for _, withCaller := range []bool{false, true} {
+			for _, withPrefix := range []bool{false, true} {
+				name := sink.name
+				if withCaller {
+					name += "/Caller"
+				}
+				if withPrefix {
+					name += "/Prefix"
+				}
+				logs := sink.build(tracking[withCaller], withPrefix)
+				b.Run(name, func(b *testing.B) { benchmarkInterface(b, logs) })
+			}
+		}
+	}
+}
+
+func BenchmarkConfig_Production(b *testing.B) {
+	benchmarkInterface(b, config.Production().With(
+		config.Stream(nullStream()),
+	))
+}
+
+func BenchmarkDiscard(b *testing.B) {
+	benchmarkInterface(b, levels.Discard())
+}
+
+func BenchmarkConfig_Disabled(b *testing.B) {
+	log := config.DefaultConfig.With(
+		config.Stream(nullStream()),
+		config.CallTracking(caller.Tracking{}),
+	)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		log.Debugf("hello %s", "world")
+	}
+}
+
+func BenchmarkLevels_WithLoggers(b *testing.B) {
+	logs := logger.Func(func(_ context.Context, _ string, _ ...interface{}) {})
+	benchmarkInterface(b, levels.WithLoggers(context.TODO, levels.IndexerFunc(
+		func(levels.Level) (logger.Logger, bool) { return logs, true })))
+}
+
+func BenchmarkLogger_WithStream(b *testing.B) {
+	var (
+		logs = logger.WithStream(nullStream(), encoding.Format(), logger.IgnoreErrors())
+		ctx  = context.TODO()
+	)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		logs.Logf(ctx, "hello %s", "world")
+	}
+}
+
+func BenchmarkEncoding_Prefix(b *testing.B) {
+	var (
+		s   = nullStream()
+		ctx = levels.NewContext(context.TODO(), levels.Info)
+		m   = encoding.Format(ioutil.Level(), ioutil.String(" "))
+	)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = m(ctx, s, "hello %s", "world")
+	}
+}
+
+func BenchmarkIO_BufferedStream(b *testing.B) {
+	var (
+		s   = nullStream()
+		msg = []byte("hello world")
+	)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = s.Write(msg)
+		_ = s.EOM(nil)
+	}
+}
+
+func benchmarkParallel(b *testing.B, log levels.Interface) {
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			log.Infof("hello %s", "world")
+		}
+	})
+}
+
+func BenchmarkConfig_LoggerParallel(b *testing.B) {
+	benchmarkParallel(b, config.DefaultConfig.With(
+		config.Logger(logger.Null()),
+		config.CallTracking(caller.Tracking{}),
+	))
+}
+
+func BenchmarkConfig_LoggerParallelNoGuard(b *testing.B) {
+	benchmarkParallel(b, config.DefaultConfig.With(
+		config.Logger(logger.Null()),
+		config.CallTracking(caller.Tracking{}),
+		config.Guard(config.NoGuard()),
+	))
+}
+
+func BenchmarkConfig_StreamParallel(b *testing.B) {
+	benchmarkParallel(b, config.DefaultConfig.With(
+		config.Stream(nullStream()),
+		config.CallTracking(caller.Tracking{}),
+	))
+}
+
+func BenchmarkConfig_StreamParallelSharded(b *testing.B) {
+	w := io.Sharded(nullStream(), 0, time.Millisecond, nil)
+	defer w.Close()
+	benchmarkParallel(b, config.DefaultConfig.With(
+		config.Stream(nullStream()),
+		config.CallTracking(caller.Tracking{}),
+		config.Builder(logger.WithShards(w)),
+		config.Guard(config.NoGuard()),
+	))
+}
+
+// multi-part prefixes, as generated by GlogHeader: the Iterable API vs. the Appender API
+var (
+	benchLevel = []byte("I")
+	benchTS    = []byte("0101 00:00:00.000000")
+	benchSP    = []byte(" ")
+)
+
+func BenchmarkEncoding_IterablePrefix(b *testing.B) {
+	var (
+		s = nullStream()
+		m = encoding.Format(encoding.Prefix(func(context.Context) encoding.Iterable {
+			return encoding.NewIterable(benchLevel, benchTS, benchSP)
+		}))
+	)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = m(context.TODO(), s, "hello")
+	}
+}
+
+func BenchmarkEncoding_AppendPrefix(b *testing.B) {
+	var (
+		s = nullStream()
+		m = encoding.Format(encoding.AppendPrefix(func(_ context.Context, dst []byte) []byte {
+			return append(append(append(dst, benchLevel...), benchTS...), benchSP...)
+		}))
+	)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = m(context.TODO(), s, "hello")
+	}
+}
+
+// BenchmarkEncoders compares the encoders of this module, see encodingtest.Benchmark.
+func BenchmarkEncoders(b *testing.B) {
+	encodingtest.BenchmarkAll(b, map[string]encoding.Marshaler{
+		"Format":       encoding.Format(),
+		"CachedFormat": encoding.CachedFormat(),
+		"JSON":         structured.JSON(structured.Options{}),
+		"Logfmt":       structured.Logfmt(structured.Options{}),
+		"CEF":          siem.CEF(siem.Config{Vendor: "gologs", Product: "bench", Version: "1"}),
+		"LEEF":         siem.LEEF(siem.Config{Vendor: "gologs", Product: "bench", Version: "1"}),
+	})
+}
@@ -17,7 +17,12 @@ limitations under the License.
 package caller
 
 import (
+	"path"
 	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
 
 	"github.com/gologs/log/context"
 )
@@ -34,6 +39,10 @@ type (
 	Tracking struct {
 		Enabled bool
 		Depth   int
+		// Levels, if non-zero, is a mask of the log levels (see levels.Level, whose values are
+		// bit flags) for which Caller information is captured; log events of other levels skip
+		// the stack walk. Zero selects all levels.
+		Levels int
 	}
 
 	key int
@@ -41,6 +50,7 @@ type (
 
 const (
 	callerKey key = iota
+	skipKey
 )
 
 // NewContext generates a Context annotated with Caller
@@ -52,27 +62,274 @@ func NewContext(ctx context.Context, file string, line int, funcName string) con
 	})
 }
 
+func init() {
+	context.RegisterField("caller", func(c context.Context) (interface{}, bool) { return FromContext(c) })
+}
+
 // FromContext extracts a Caller from the given Context
 func FromContext(ctx context.Context) (Caller, bool) {
+	if ctx == nil {
+		return Caller{}, false
+	}
 	x, ok := ctx.Value(callerKey).(Caller)
 	return x, ok
 }
 
-// WithContext decorates the given context by injecting the Caller if t.Enabled is true
+// WithContext decorates the given context by injecting the Caller if t.Enabled is true. A Caller
+// that's already present in the context (for example, that of a replayed log event) is retained.
 func WithContext(t Tracking) context.Decorator {
 	if !t.Enabled {
 		return context.NoDecorator()
 	}
 	return func(c context.Context) context.Context {
+		if _, ok := FromContext(c); ok {
+			return c
+		}
+		depth := t.Depth
+		if n, ok := c.Value(skipKey).(int); ok {
+			depth += n
+		}
+		if depth < 0 {
+			depth = 0
+		}
+		return context.WithValue(c, callerKey, lookup(depth))
+	}
+}
+
+// Skip returns a Context that instructs WithContext to skip `n` more frames of the call stack
+// (in addition to any frames that the given Context already skips) when it looks up the Caller.
+// It's intended for bridges that forward log events from other logging APIs, and so contribute
+// frames of their own to the call stack; see logger.WithCallDepth.
+func Skip(ctx context.Context, n int) context.Context {
+	if m, ok := ctx.Value(skipKey).(int); ok {
+		n += m
+	}
+	return context.WithValue(ctx, skipKey, n)
+}
+
+// frames caches the Caller of each program counter that's been looked up, since symbolization
+// is expensive and the number of distinct call sites that log is small.
+var frames sync.Map // map[uintptr]Caller
+
+// lookup returns the Caller of the frame that's skip frames above the caller of lookup, or of the
+// first frame above it that doesn't belong to a wrapper (see RegisterWrapper): it walks the stack
+// once per call, but symbolizes each program counter only once.
+func lookup(skip int) Caller {
+	var (
+		pcs [maxStack]uintptr
+		n   = 1
+	)
+	if hasWrappers() {
+		n = maxStack
+	}
+	n = runtime.Callers(skip+2, pcs[:n])
+	if n < 1 {
+		return Caller{File: "???", FuncName: "???"}
+	}
+	first := symbolize(pcs[0])
+	if n == 1 || !wrapper(first.FuncName) {
+		return first
+	}
+	// the frames above a wrapper are symbolized together, and aren't cached: a program counter
+	// may stand for several (inlined) frames, which CallersFrames tells apart only in context.
+	fs := runtime.CallersFrames(pcs[:n])
+	for {
+		f, more := fs.Next()
+		x := newCaller(f)
+		if !more || !wrapper(x.FuncName) {
+			return x
+		}
+	}
+}
+
+// symbolize returns the (cached) Caller of the given program counter, as returned by
+// runtime.Callers.
+func symbolize(pc uintptr) Caller {
+	if x, ok := frames.Load(pc); ok {
+		return x.(Caller)
+	}
+	f, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+	x := newCaller(f)
+	frames.Store(pc, x)
+	return x
+}
+
+func newCaller(f runtime.Frame) Caller {
+	x := Caller{File: f.File, Line: f.Line, FuncName: f.Function}
+	if x.File == "" {
+		x.File = "???"
+	}
+	if x.FuncName == "" {
+		x.FuncName = "???"
+	}
+	return x
+}
+
+var (
+	wrappersMu sync.Mutex
+	wrappers   atomic.Value // wrappers is a []string of func name prefixes, guarded by wrappersMu
+)
+
+// RegisterWrapper registers the packages, identified by import path, that wrap the logging
+// subsystem; for example a package "github.com/myorg/logwrap" that exports its own Infof func.
+// The frames of funcs that belong to these packages, or their subpackages, are skipped when
+// looking up the Caller of a log event, so that the authors of wrappers needn't adjust the
+// Tracking Depth. RegisterWrapper is typically invoked by the init func of a wrapper package.
+// The returned func undoes the registration, for example at the end of a test; invoking it more
+// than once is a noop.
+func RegisterWrapper(pkgPath ...string) (unregister func()) {
+	var added []string
+	for _, p := range pkgPath {
+		if p = strings.Trim(p, "/"); p != "" {
+			added = append(added, symbolPrefix(p))
+		}
+	}
+	wrappersMu.Lock()
+	defer wrappersMu.Unlock()
+	old, _ := wrappers.Load().([]string)
+	wrappers.Store(append(append([]string(nil), old...), added...))
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			wrappersMu.Lock()
+			defer wrappersMu.Unlock()
+			old, _ := wrappers.Load().([]string)
+			w := append([]string(nil), old...)
+			for _, a := range added {
+				for i := range w {
+					if w[i] == a {
+						w = append(w[:i], w[i+1:]...)
+						break
+					}
+				}
+			}
+			wrappers.Store(w)
+		})
+	}
+}
+
+// symbolPrefix returns the prefix of the symbol names of the funcs of a package: the linker
+// escapes the dots of the last element of its import path (for example "gopkg.in/yaml%2ev2").
+func symbolPrefix(pkgPath string) string {
+	i := strings.LastIndexByte(pkgPath, '/') + 1
+	return pkgPath[:i] + strings.Replace(pkgPath[i:], ".", "%2e", -1)
+}
+
+func hasWrappers() bool {
+	w, _ := wrappers.Load().([]string)
+	return len(w) > 0
+}
+
+// wrapper returns true if the named func belongs to a registered wrapper package, or to one of
+// its subpackages.
+func wrapper(funcName string) bool {
+	w, _ := wrappers.Load().([]string)
+	for _, p := range w {
+		if strings.HasPrefix(funcName, p) && len(funcName) > len(p) &&
+			(funcName[len(p)] == '.' || funcName[len(p)] == '/') {
+			return true
+		}
+	}
+	return false
+}
+
+// Matches returns a context predicate that returns true if the Caller of a log event was found
+// in one of the given files or packages. Patterns are slash-separated path suffixes, with or
+// without the ".go" file extension: for example "io/rotate" matches the files of a package
+// directory ".../io/rotate/" as well as the file ".../io/rotate.go".
+func Matches(patterns ...string) func(context.Context) bool {
+	return func(c context.Context) bool {
+		if c == nil {
+			return false
+		}
+		x, ok := FromContext(c)
+		if !ok {
+			return false
+		}
 		var (
-			funcName           = "???"
-			pc, file, line, ok = runtime.Caller(t.Depth)
+			file = strings.TrimSuffix(x.File, ".go")
+			dir  = path.Dir(x.File)
 		)
-		if !ok {
-			file, line = "???", 0
-		} else if f := runtime.FuncForPC(pc); f != nil {
-			funcName = f.Name()
+		for _, p := range patterns {
+			p = strings.TrimSuffix(strings.Trim(p, "/"), ".go")
+			if p != "" && (hasPathSuffix(file, p) || hasPathSuffix(dir, p)) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+func hasPathSuffix(s, suffix string) bool {
+	return s == suffix || strings.HasSuffix(s, "/"+suffix)
+}
+
+// Stack is a call stack, innermost frame first.
+type Stack []Caller
+
+// maxStack bounds the number of frames captured by CaptureStack
+const maxStack = 64
+
+// CaptureStack returns the call stack of the calling goroutine, excluding the leading frames
+// that belong to the logging subsystem itself (the packages of github.com/gologs/log, other than
+// their tests, and those registered via RegisterWrapper) and the runtime.
+func CaptureStack() Stack {
+	var (
+		pcs    [maxStack]uintptr
+		n      = runtime.Callers(2, pcs[:])
+		frames = runtime.CallersFrames(pcs[:n])
+		stack  = make(Stack, 0, n)
+	)
+	for {
+		f, more := frames.Next()
+		if (len(stack) > 0 || !internal(f.Function) && !wrapper(f.Function)) && f.Function != "runtime.goexit" {
+			stack = append(stack, Caller{File: f.File, Line: f.Line, FuncName: f.Function})
 		}
-		return NewContext(c, file, line, funcName)
+		if !more {
+			break
+		}
+	}
+	return stack
+}
+
+const modulePath = "github.com/gologs/log"
+
+// internal returns true if the named func belongs to a (non-test) package of this module
+func internal(funcName string) bool {
+	if !strings.HasPrefix(funcName, modulePath) {
+		return false
+	}
+	pkg := funcName
+	if i := strings.LastIndexByte(pkg, '/'); i >= 0 {
+		if j := strings.IndexByte(pkg[i:], '.'); j >= 0 {
+			pkg = pkg[:i+j]
+		}
+	}
+	return !strings.HasSuffix(pkg, "_test")
+}
+
+// String renders the stack one frame per line, as "func file:line".
+func (s Stack) String() string {
+	var b strings.Builder
+	for i, c := range s {
+		if i > 0 {
+			b.WriteByte('\n')
+		}
+		b.WriteString(c.FuncName)
+		b.WriteByte(' ')
+		b.WriteString(c.File)
+		b.WriteByte(':')
+		b.WriteString(strconv.Itoa(c.Line))
+	}
+	return b.String()
+}
+
+// MarshalLog renders the stack as a list of "func file:line" frames for structured encoders.
+func (s Stack) MarshalLog() interface{} {
+	frames := make([]string, len(s))
+	for i, c := range s {
+		frames[i] = c.FuncName + " " + c.File + ":" + strconv.Itoa(c.Line)
 	}
+	return frames
 }
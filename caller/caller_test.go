@@ -0,0 +1,48 @@
+/*
+Copyright 2016 James DeFelice
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package caller_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gologs/log/caller"
+	"github.com/gologs/log/context"
+)
+
+// infof stands in for the Infof func of a wrapper package
+func infof() caller.Caller {
+	c := caller.WithContext(caller.Tracking{Enabled: true, Depth: 1})(context.TODO())
+	x, _ := caller.FromContext(c)
+	return x
+}
+
+func TestRegisterWrapper(t *testing.T) {
+	if x := infof(); !strings.HasSuffix(x.FuncName, "caller_test.infof") {
+		t.Fatalf("unexpected caller %+v", x)
+	}
+	unregister := caller.RegisterWrapper("github.com/gologs/log/caller_test")
+	defer unregister()
+	if x := infof(); strings.Contains(x.FuncName, "caller_test.") {
+		t.Fatalf("expected the frames of the wrapper to be skipped instead of %+v", x)
+	}
+
+	unregister()
+	if x := infof(); !strings.HasSuffix(x.FuncName, "caller_test.infof") {
+		t.Fatalf("expected the registration to be undone instead of %+v", x)
+	}
+}
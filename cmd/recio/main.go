@@ -0,0 +1,56 @@
+/*
+Copyright 2016 James DeFelice
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Command recio decodes a RecordIO log file, read from stdin, into text written to stdout, one
+// record per line. With -encode it does the reverse. For example:
+//
+//	recio < app.log | grep ERROR
+//	recio < app.log > app.txt && vi app.txt && recio -encode < app.txt > app.log
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/gologs/log/io/recio"
+)
+
+func main() {
+	var (
+		encode = flag.Bool("encode", false, "encode lines of text into records")
+		max    = flag.Int("max", recio.DefaultMaxRecordSize, "maximum record size, in bytes")
+		quiet  = flag.Bool("q", false, "don't report corrupt data")
+		opts   = recio.Options{}
+		err    error
+	)
+	flag.Parse()
+	opts.MaxRecordSize = *max
+	if !*quiet {
+		opts.Corrupt = func(offset, n int64) {
+			fmt.Fprintf(os.Stderr, "recio: skipped %d corrupt bytes at offset %d\n", n, offset)
+		}
+	}
+	if *encode {
+		_, err = recio.Encode(os.Stdout, os.Stdin, opts)
+	} else {
+		_, err = recio.Decode(os.Stdout, os.Stdin, opts)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,79 @@
+/*
+Copyright 2016 James DeFelice
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"os"
+
+	"github.com/gologs/log/encoding"
+	"github.com/gologs/log/encoding/structured"
+	"github.com/gologs/log/io"
+	"github.com/gologs/log/io/ioutil"
+)
+
+// inContainer returns true if there are signs that the process runs in a container, in which
+// case its output is usually collected by machines rather than read by people.
+func inContainer() bool {
+	if os.Getenv("KUBERNETES_SERVICE_HOST") != "" || os.Getenv("container") != "" {
+		return true
+	}
+	for _, marker := range []string{"/.dockerenv", "/run/.containerenv"} {
+		if _, err := os.Stat(marker); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// colorful returns true unless the user or terminal opted out of colors
+func colorful() bool {
+	_, noColor := os.LookupEnv("NO_COLOR")
+	return !noColor && os.Getenv("TERM") != "dumb"
+}
+
+// Auto returns a functional Option that establishes a sink suited to the environment: if stderr
+// is a terminal (see io.IsTerminal), outside of a container, then log events are written to it as
+// text with a timestamp and level code, styled per ioutil.DefaultTheme unless NO_COLOR is set or
+// TERM is "dumb"; otherwise log events are written to stderr as JSON lines (see
+// structured.JSON), for log collectors. It's a sensible default for binaries that serve both
+// as CLIs and services.
+func Auto() Option { return AutoTheme(ioutil.DefaultTheme) }
+
+// AutoTheme is like Auto, except that text is styled per the given Theme.
+func AutoTheme(t ioutil.Theme) Option {
+	return func(c *Config) Option {
+		old := c.Sink
+		c.Sink.Stream, c.Sink.Logger = io.Stderr(), nil
+		if io.IsTerminal(os.Stderr) && !inContainer() {
+			if !colorful() {
+				t = ioutil.Theme{}
+			}
+			t = t.For(os.Stderr)
+			c.Sink.Marshaler = encoding.Format()
+			c.Sink.Decorators = encoding.Decorators{
+				ioutil.String(" "),
+				ioutil.Level(),
+				t.Styled(t.Time, ioutil.Timestamp("15:04:05.000 ")),
+				t.Color(),
+			}
+		} else {
+			c.Sink.Marshaler = structured.JSON(structured.Options{})
+			c.Sink.Decorators = nil
+		}
+		return Sink(old)
+	}
+}
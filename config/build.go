@@ -0,0 +1,69 @@
+/*
+Copyright 2016 James DeFelice
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	stdio "io"
+
+	"github.com/gologs/log/io"
+	"github.com/gologs/log/levels"
+)
+
+// Open returns a functional Option that establishes a Stream sink that's generated by `open`
+// upon Build, which reports its error and returns the func that closes it. With (and so Update,
+// Scoped, etc.) never opens the sink: it uses the configured Stream or Logger instead.
+func Open(open func() (io.Stream, error)) Option {
+	return func(c *Config) Option {
+		old := c.Sink.Open
+		c.Sink.Open = open
+		return Open(old)
+	}
+}
+
+// OpenSink is like Open, for the Stream generated per the given spec (see io.OpenSink), for
+// example "file:/var/log/app.log".
+func OpenSink(spec string) Option {
+	return Open(func() (io.Stream, error) { return io.OpenSink(spec) })
+}
+
+// Build is like With, except that it reports the failure to Open the sink, as well as configs
+// that don't Validate, instead of silently falling back. The returned cleanup func closes the
+// opened Stream, if it implements io.Closer; it should be invoked once logging is no longer
+// needed.
+func (cfg Config) Build(opt ...Option) (i levels.Interface, cleanup func(), err error) {
+	for _, o := range opt {
+		if o != nil {
+			_ = o(&cfg)
+		}
+	}
+	cleanup = func() {}
+	if cfg.Sink.Open != nil {
+		var s io.Stream
+		if s, err = cfg.Sink.Open(); err != nil {
+			return nil, nil, err
+		}
+		cfg.Sink.Stream, cfg.Sink.Logger, cfg.Sink.Open = s, nil, nil
+		if c, ok := s.(stdio.Closer); ok {
+			cleanup = func() { _ = c.Close() }
+		}
+	}
+	if err = cfg.Validate(); err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+	return cfg.With(), cleanup, nil
+}
@@ -17,13 +17,18 @@ limitations under the License.
 package config
 
 import (
+	"errors"
 	"os"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/gologs/log/caller"
 	"github.com/gologs/log/context"
+	"github.com/gologs/log/context/fields"
+	"github.com/gologs/log/context/requestid"
 	"github.com/gologs/log/context/timestamp"
+	"github.com/gologs/log/diag"
 	"github.com/gologs/log/encoding"
 	"github.com/gologs/log/io"
 	"github.com/gologs/log/levels"
@@ -48,8 +53,18 @@ func (g *lockGuard) Apply(x levels.Level, logs logger.Logger) (levels.Level, log
 }
 
 // LockGuard provides the default mutex used to guard log operations. All levels.Interface objects
-// produced by this module reference this guard instance.
-var LockGuard = levels.TransformOp((&lockGuard{}).Apply)
+// produced by this module reference this guard instance unless configured otherwise, see Guard.
+var LockGuard = NewLockGuard()
+
+// NewLockGuard generates a guard that's backed by its own mutex; useful for serializing log
+// operations per sink so that independent sinks don't contend for the same lock.
+func NewLockGuard() levels.TransformOp { return (&lockGuard{}).Apply }
+
+// NoGuard generates a guard that does not serialize log operations. It should only be used with
+// sinks that are safe for concurrent use.
+func NoGuard() levels.TransformOp {
+	return func(x levels.Level, logs logger.Logger) (levels.Level, logger.Logger) { return x, logs }
+}
 
 // LeveledStreamer generates a leveled logging interface for the given io.Stream oriented configuration.
 func LeveledStreamer(
@@ -59,27 +74,23 @@ func LeveledStreamer(
 	marshaler encoding.Marshaler,
 	t levels.TransformOps,
 	callTracking caller.Tracking,
-	errorSink chan<- error,
+	errorSink logger.ErrorSink,
 	builder logger.Builder,
 ) levels.Interface {
-	return leveledLogger(
-		ctx,
-		threshold,
-		safeBuilder(builder)(s, marshaler, errorSink),
-		t,
-		callTracking)
+	p := pipeline{ctx: ctx, threshold: threshold, t: t, callTracking: callTracking}
+	return p.streamer(s, marshaler, errorSink, builder)
 }
 
 func safeBuilder(b logger.Builder) logger.Builder {
 	if b == nil {
 		b = logger.WithStream
 	}
-	return logger.Builder(func(s io.Stream, marshaler encoding.Marshaler, errorSink chan<- error) logger.Logger {
+	return logger.Builder(func(s io.Stream, marshaler encoding.Marshaler, errorSink logger.ErrorSink) logger.Logger {
 		if s == nil {
 			s = io.SystemStream(2) // TODO(jdef) this value is probably garbage
 		}
 		if errorSink == nil {
-			errorSink = logger.IgnoreErrors()
+			errorSink = diag.ErrorSink()
 		}
 		return b(s, marshaler, errorSink)
 	})
@@ -93,23 +104,43 @@ func LeveledLogger(
 	t levels.TransformOps,
 	callTracking caller.Tracking,
 ) levels.Interface {
+	p := pipeline{ctx: ctx, threshold: threshold, t: t, callTracking: callTracking}
+	return p.logger(logs)
+}
+
+// pipeline aggregates the sink-agnostic parameters that determine how log events are processed.
+type pipeline struct {
+	ctx          context.Getter
+	threshold    levels.TransformOp
+	t            levels.TransformOps
+	callTracking caller.Tracking
+	guard        levels.TransformOp // guard defaults to LockGuard
+	clock        timestamp.Clock    // clock defaults to time.Now
+	downgrade    bool               // downgrade logs Fatal and Panic at Error, see Downgrade
+}
+
+func (p pipeline) streamer(
+	s io.Stream,
+	marshaler encoding.Marshaler,
+	errorSink logger.ErrorSink,
+	builder logger.Builder,
+) levels.Interface {
+	return p.build(safeBuilder(builder)(s, marshaler, errorSink))
+}
+
+func (p pipeline) logger(logs logger.Logger) levels.Interface {
 	if logs == nil {
 		logs = logger.SystemLogger()
 	}
-	return leveledLogger(ctx, threshold, logs, t, callTracking)
+	return p.build(logs)
 }
 
-// Clock tells the time
-var Clock = time.Now
-
-func leveledLogger(
-	ctx context.Getter,
-	threshold levels.TransformOp,
-	logs logger.Logger,
-	t levels.TransformOps,
-	callTracking caller.Tracking,
-) levels.Interface {
+func (p pipeline) build(logs logger.Logger) levels.Interface {
+	logs = flattened(logs)
 	logAt := levels.IndexerFunc(func(level levels.Level) (logger.Logger, bool) {
+		if p.downgrade && (level == levels.Fatal || level == levels.Panic) {
+			return logger.WithContext(downgrade(level), logs), true
+		}
 		return logger.WithContext(levels.DecorateContext(level), logs), true
 	})
 
@@ -119,21 +150,59 @@ func leveledLogger(
 
 	// TODO(jdef) do we really want to lock around user-specified transform ops? Users should
 	// probably be responsible for their own thread-safety.
-	t = append(t, LockGuard, safeThreshold(threshold))
-	if callTracking.Enabled {
+	t := append(p.t, safeGuard(p.guard), safeThreshold(p.threshold))
+	if p.callTracking.Enabled {
+		callTracking := p.callTracking
 		t = append(t,
 			// inject caller info into context (file/line); this is probably the best place to do it
 			// since we can predict the call-depth here and it will work for both Stream- and Logger-
 			// based approaches.
 			levels.TransformOp(func(x levels.Level, logs logger.Logger) (levels.Level, logger.Logger) {
+				if callTracking.Levels != 0 && levels.Level(callTracking.Levels)&x == 0 {
+					return x, logs
+				}
 				return x, logger.WithContext(caller.WithContext(callTracking), logs)
 			}),
 		)
 	}
-	ctx = context.NewGetter(safeContext(ctx), timestamp.NewDecorator(Clock))
+	ctx := context.NewGetter(safeContext(p.ctx), timestamp.NewDecorator(safeClock(p.clock)))
 	return levels.WithLoggers(ctx, levels.NewIndexer(logAt, nil, t...))
 }
 
+// flattened returns a Logger that flattens the context of each log event (see context.Flatten)
+// so that the lookups of the sink's decorators and encoders needn't walk its chain.
+func flattened(logs logger.Logger) logger.Logger {
+	if logger.IsNull(logs) {
+		return logs
+	}
+	return logger.Func(func(c context.Context, m string, a ...interface{}) {
+		logs.Logf(context.Flatten(c), m, a...)
+	})
+}
+
+// DowngradedKey is the key of the field that records the original level of a downgraded log
+// event, see Downgrade.
+const DowngradedKey = "downgraded_from"
+
+// downgrade annotates the context of a Fatal or Panic log event such that it's logged at Error;
+// downgraded Panic log events also record the call stack, as the "stack" field.
+func downgrade(level levels.Level) context.Decorator {
+	return func(c context.Context) context.Context {
+		c = levels.NewContext(c, levels.Error)
+		if level == levels.Panic {
+			return fields.NewContext(c, fields.F(DowngradedKey, level.String()), fields.F("stack", caller.CaptureStack()))
+		}
+		return fields.NewContext(c, fields.F(DowngradedKey, level.String()))
+	}
+}
+
+func safeClock(c timestamp.Clock) timestamp.Clock {
+	if c == nil {
+		c = time.Now
+	}
+	return c
+}
+
 func safeThreshold(t levels.TransformOp) levels.TransformOp {
 	if t == nil {
 		t = levels.MinThreshold(levels.Info)
@@ -141,6 +210,13 @@ func safeThreshold(t levels.TransformOp) levels.TransformOp {
 	return t
 }
 
+func safeGuard(g levels.TransformOp) levels.TransformOp {
+	if g == nil {
+		g = LockGuard
+	}
+	return g
+}
+
 func safeExit(fexit func(int)) func(int) {
 	if fexit == nil {
 		fexit = os.Exit
@@ -155,13 +231,50 @@ func safePanic(fpanic func(string)) func(string) {
 	return fpanic
 }
 
-func exitLogger(logs logger.Logger, fexit func(int), code int) logger.Logger {
+func exitLogger(logs logger.Logger, fexit func(int), code int, coders []ExitCoder) logger.Logger {
 	return logger.Func(func(c context.Context, m string, a ...interface{}) {
-		defer safeExit(fexit)(code)
+		defer safeExit(fexit)(exitCode(c, a, code, coders))
 		logs.Logf(c, m, a...)
 	})
 }
 
+func exitCode(c context.Context, a []interface{}, code int, coders []ExitCoder) int {
+	for _, f := range coders {
+		if x, ok := f(c, a); ok {
+			return x
+		}
+	}
+	return code
+}
+
+// ExitCoder selects the exit code for a Fatal log event, given its context and arguments.
+// It returns false if the log event isn't recognized.
+type ExitCoder func(context.Context, []interface{}) (int, bool)
+
+// ExitCodeForError generates an ExitCoder that matches log events with an error argument that
+// is, or wraps, `target`.
+func ExitCodeForError(target error, code int) ExitCoder {
+	return func(_ context.Context, a []interface{}) (int, bool) {
+		for _, x := range a {
+			if err, ok := x.(error); ok && errors.Is(err, target) {
+				return code, true
+			}
+		}
+		return 0, false
+	}
+}
+
+// ExitCodeForContext generates an ExitCoder that matches log events whose context associates
+// a (non-nil) value with `key`.
+func ExitCodeForContext(key interface{}, code int) ExitCoder {
+	return func(c context.Context, _ []interface{}) (int, bool) {
+		if c != nil && c.Value(key) != nil {
+			return code, true
+		}
+		return 0, false
+	}
+}
+
 func panicLogger(logs logger.Logger, fpanic func(string)) logger.Logger {
 	return logger.Func(func(c context.Context, m string, a ...interface{}) {
 		defer safePanic(fpanic)(m)
@@ -179,17 +292,31 @@ type StreamOrLogger struct {
 	// Decorators are applied to the Stream (never to Sink.Logger)
 	Decorators encoding.Decorators
 
+	// LoggerDecorators are applied to the sink's Logger: either Sink.Logger, or else the Logger
+	// generated by Builder for Stream.
+	LoggerDecorators logger.Decorators
+
 	// Marshals a log event to Stream, defaults to io.Printf.
 	// A Marshaler invokes Stream.EOM as the final step of processing each log event.
 	Marshaler encoding.Marshaler
 
 	// Errors receives errors as they occur upon processing streaming events
 	// (only applies when using Stream, not for Logger).
-	// Defaults to logger.IgnoreErrors().
-	Errors chan<- error
+	// Defaults to diag.ErrorSink().
+	Errors logger.ErrorSink
 
 	// Builder generates a Logger using the configured Stream, Marshaler, and Errors
 	Builder logger.Builder
+
+	// Open (optional) generates the Stream upon Config.Build, for sinks whose construction may
+	// fail; it takes precedence over Stream and Logger. With ignores it, since nothing would
+	// close the Stream. See Open.
+	Open func() (io.Stream, error)
+
+	// NoRecover, when true, lets panics raised upon marshaling a log event (for example, by a
+	// String method of a log argument) crash the process; useful for debugging. Otherwise such
+	// panics are recovered and reported to Errors, see encoding.Recover.
+	NoRecover bool
 }
 
 // Config is a complete logging configuration. Fields may be tweaked manually, or by way
@@ -213,6 +340,10 @@ type Config struct {
 	// ExitCode is passed to exit functions that are invoked upon calls to Fatalf
 	ExitCode int
 
+	// ExitCoders are consulted, in order, to select the exit code for a Fatal log event;
+	// ExitCode applies if none of them match.
+	ExitCoders []ExitCoder
+
 	// Exit, when unset, will invoke os.Exit upon calls to Fatalf
 	Exit func(int)
 
@@ -220,8 +351,19 @@ type Config struct {
 	Panic func(string)
 
 	// TransformOps allow clients to highly customize log processing based on levels. These
-	// operators are never executed concurrently.
+	// operators are never executed concurrently, unless Guard permits it.
 	TransformOps levels.TransformOps
+
+	// Guard serializes log events on their way to the sink, defaults to LockGuard.
+	// See NewLockGuard and NoGuard.
+	Guard levels.TransformOp
+
+	// Clock generates the timestamp for each log event, defaults to time.Now.
+	Clock timestamp.Clock
+
+	// Downgrade, when true, logs Fatal and Panic log events at Error instead, and neither exits
+	// nor panics. See Downgrade.
+	Downgrade bool
 }
 
 // NoPanic generates a noop panic func
@@ -234,14 +376,107 @@ var (
 	_ = &Config{Panic: NoPanic()} // NoPanic is a panic func generator
 	_ = &Config{Exit: NoExit()}   // NoExit is an exit func generator
 
-	// DefaultConfig is used to generate the initial value for Current.
+	// DefaultConfig is used to generate the initial value for Logging.
 	DefaultConfig = Porcelain()
+)
+
+type logging struct {
+	cfg   Config
+	iface levels.Interface
+}
 
-	// Logging is a logging instance constructed with default configuration:
-	// it logs everything "info" and higher ("warn", "error", ...) to logger.SystemLogger()
-	Logging = DefaultConfig.With(NoOption())
+var (
+	loggingMu sync.Mutex   // loggingMu serializes changes to current
+	current   atomic.Value // current holds a *logging
 )
 
+func init() {
+	current.Store(&logging{cfg: DefaultConfig, iface: DefaultConfig.With(NoOption())})
+}
+
+// Logging returns the logging instance used by the top-level log package. It is initially
+// constructed with default configuration: it logs everything "info" and higher ("warn",
+// "error", ...) to logger.SystemLogger(). It is safe to invoke concurrently with SetLogging
+// and Update.
+func Logging() levels.Interface { return current.Load().(*logging).iface }
+
+// Now returns the current time per the Clock of the configuration most recently established by
+// Update or Apply, see Clock.
+func Now() time.Time { return safeClock(current.Load().(*logging).cfg.Clock)() }
+
+// SetLogging atomically replaces the logging instance returned by Logging and returns the
+// instance that was replaced.
+func SetLogging(i levels.Interface) levels.Interface {
+	loggingMu.Lock()
+	defer loggingMu.Unlock()
+	old := current.Load().(*logging)
+	current.Store(&logging{cfg: old.cfg, iface: i})
+	return old.iface
+}
+
+// Update atomically replaces the logging instance returned by Logging with one that is generated
+// by applying the given Options to the configuration most recently established by Update or Apply
+// (initially DefaultConfig); instances installed via SetLogging are disregarded. It returns a
+// functional Option that, when passed to Update, restores the previous configuration.
+func Update(opt ...Option) Option { return updateLogging(false, opt...) }
+
+// Apply is like Update, except that it reconfigures the logging instance returned by Logging
+// in place: clients that already hold a reference to that instance observe the changes made
+// here. If the current instance does not support reconfiguration (see levels.Reconfigure) then
+// it's replaced instead.
+func Apply(opt ...Option) Option { return updateLogging(true, opt...) }
+
+func updateLogging(inPlace bool, opt ...Option) Option {
+	loggingMu.Lock()
+	defer loggingMu.Unlock()
+	return updateLoggingLocked(inPlace, opt...)
+}
+
+func updateLoggingLocked(inPlace bool, opt ...Option) Option {
+	var (
+		old = current.Load().(*logging)
+		cfg = old.cfg.Copy()
+	)
+	for _, o := range opt {
+		if o != nil {
+			_ = o(&cfg)
+		}
+	}
+	i := cfg.With(NoOption())
+	if inPlace && levels.Reconfigure(old.iface, i) {
+		i = old.iface
+	}
+	current.Store(&logging{cfg: cfg, iface: i})
+	return Set(old.cfg)
+}
+
+type scope struct{ undo Option }
+
+var scopes []*scope // scopes is a stack, guarded by loggingMu
+
+// Scoped applies the given Options (see Apply) and returns a func that restores the logging
+// configuration to its state prior to the call to Scoped. Scopes nest: restoring a scope also
+// restores any scopes that were established after it. Invoking restore more than once, or after
+// an enclosing scope has been restored, is a noop.
+func Scoped(opt ...Option) (restore func()) {
+	loggingMu.Lock()
+	defer loggingMu.Unlock()
+
+	s := &scope{undo: updateLoggingLocked(true, opt...)}
+	scopes = append(scopes, s)
+	return func() {
+		loggingMu.Lock()
+		defer loggingMu.Unlock()
+		for i := len(scopes) - 1; i >= 0; i-- {
+			if scopes[i] == s {
+				scopes = scopes[:i]
+				updateLoggingLocked(true, s.undo)
+				return
+			}
+		}
+	}
+}
+
 // Porcelain returns a cleanroom, configuration.
 func Porcelain() Config {
 	return Config{
@@ -253,6 +488,16 @@ func Porcelain() Config {
 	}
 }
 
+// Production returns a Porcelain configuration tuned for throughput: caller tracking is
+// disabled, because crawling the runtime call stack dominates the cost of a log event that is
+// otherwise cheap to format (see BenchmarkSinks). Re-enable it via CallTracking if needed,
+// perhaps only for the levels that warrant the cost (see caller.Tracking.Levels).
+func Production() Config {
+	cfg := Porcelain()
+	cfg.CallTracking = caller.Tracking{}
+	return cfg
+}
+
 // Option is a functional option interface for making changes to a Config
 type Option func(*Config) Option
 
@@ -293,37 +538,50 @@ func (cfg Config) WithRollback(opt ...Option) (levels.Interface, Option) {
 		}
 	}
 	// exit and panic wrappers are always applied after user ops
-	t := append(cfg.TransformOps, (&levels.Transform{
-		levels.Fatal: func(x logger.Logger) logger.Logger {
-			return exitLogger(x, cfg.Exit, cfg.ExitCode)
-		},
-		levels.Panic: func(x logger.Logger) logger.Logger {
-			return panicLogger(x, cfg.Panic)
-		},
-	}).Apply)
+	t := cfg.TransformOps
+	if !cfg.Downgrade {
+		t = append(t, (&levels.Transform{
+			levels.Fatal: func(x logger.Logger) logger.Logger {
+				return exitLogger(x, cfg.Exit, cfg.ExitCode, cfg.ExitCoders)
+			},
+			levels.Panic: func(x logger.Logger) logger.Logger {
+				return panicLogger(x, cfg.Panic)
+			},
+		}).Apply)
+	}
+	p := pipeline{
+		ctx:          cfg.Context,
+		threshold:    cfg.Threshold,
+		t:            t,
+		callTracking: cfg.CallTracking,
+		guard:        cfg.Guard,
+		clock:        cfg.Clock,
+		downgrade:    cfg.Downgrade,
+	}
+	var logs logger.Logger
 	if cfg.Sink.Stream != nil {
-		return LeveledStreamer(
-			cfg.Context,
-			cfg.Threshold,
-			cfg.Sink.Stream,
-			cfg.Sink.Decorators.Decorate(safeMarshaler(cfg.Sink.Marshaler)),
-			t,
-			cfg.CallTracking,
-			cfg.Sink.Errors,
-			cfg.Sink.Builder), rollback
-	}
-	return LeveledLogger(
-		cfg.Context,
-		cfg.Threshold,
-		cfg.Sink.Logger,
-		t,
-		cfg.CallTracking), rollback
+		marshaler := cfg.Sink.Decorators.Decorate(safeMarshaler(cfg.Sink.Marshaler))
+		if !cfg.Sink.NoRecover {
+			marshaler = encoding.Recover()(marshaler)
+		}
+		logs = safeBuilder(cfg.Sink.Builder)(cfg.Sink.Stream, marshaler, cfg.Sink.Errors)
+	} else if logs = cfg.Sink.Logger; logs == nil {
+		logs = logger.SystemLogger()
+	}
+	return p.build(cfg.Sink.LoggerDecorators.Decorate(logs)), rollback
+}
+
+// Explain describes the per-level pipeline of the TransformOps and Threshold of the config, see
+// levels.Explain; the Threshold is named "threshold".
+func (cfg Config) Explain() string {
+	return levels.Explain(append(cfg.TransformOps.Copy(), levels.Named("threshold", safeThreshold(cfg.Threshold)))...)
 }
 
 // Copy returns a deep copy of the current config
 func (cfg Config) Copy() Config {
 	clone := cfg
 	clone.Sink.Decorators = cfg.Sink.Decorators.Copy()
+	clone.Sink.LoggerDecorators = cfg.Sink.LoggerDecorators.Copy()
 	return clone
 }
 
@@ -360,6 +618,28 @@ func Level(min levels.Level) Option {
 	return Threshold(levels.MinThreshold(min))
 }
 
+// TraceOnError is a functional Option that sets a levels.TraceOnError threshold, keyed by the
+// request ID of each log event (see requestid.FromContext): events below `min` are logged only
+// if an event at Error or above is subsequently logged for the same request. It is a convenience
+// option that overrides previous calls to Threshold.
+func TraceOnError(min levels.Level, depth int) Option {
+	return Threshold(levels.TraceOnError(min, requestid.FromContext, depth))
+}
+
+// DebugFor is a functional Option that enables Debug logging for log events generated by
+// the given files or packages (see caller.Matches), in addition to the levels accepted by the
+// current Threshold. It requires CallTracking to be enabled. For example:
+//
+//	undo := config.Apply(config.DebugFor("io/rotate", "sinks/kafka"))
+func DebugFor(patterns ...string) Option {
+	return func(c *Config) Option {
+		old := c.Threshold
+		c.Threshold = levels.EnableWhen(
+			levels.MatchExact(levels.Debug), caller.Matches(patterns...), safeThreshold(old))
+		return Threshold(old)
+	}
+}
+
 // Sink is a functional configuration Option that sets the destination for log messages.
 func Sink(x StreamOrLogger) Option {
 	return func(c *Config) Option {
@@ -412,6 +692,19 @@ func ExitCode(code int) Option {
 	}
 }
 
+// ExitCodes returns a functional Option that appends the given ExitCoders to those already
+// defined for the config.
+func ExitCodes(coders ...ExitCoder) Option {
+	return func(c *Config) Option {
+		old := c.ExitCoders
+		c.ExitCoders = append(append([]ExitCoder(nil), old...), coders...)
+		return Option(func(c *Config) Option {
+			c.ExitCoders = old
+			return ExitCodes(coders...)
+		})
+	}
+}
+
 // OnPanic is a functional configuration Option that defines the behavior of Panicf after a
 // log message has been delivered to the sink.
 func OnPanic(f func(msg string)) Option {
@@ -422,6 +715,19 @@ func OnPanic(f func(msg string)) Option {
 	}
 }
 
+// Downgrade is a functional configuration Option that determines whether Fatal and Panic log
+// events are logged at Error instead, annotated with their original level (as the DowngradedKey
+// field) and, for Panic, with the call stack; they then neither exit nor panic. It's intended
+// for hosts that embed third-party code whose Fatal calls must not kill the process, and for
+// tests.
+func Downgrade(b bool) Option {
+	return func(c *Config) Option {
+		old := c.Downgrade
+		c.Downgrade = b
+		return Downgrade(old)
+	}
+}
+
 // Marshaler is a functional configuration Option that serializes log messages to an io.Stream.
 func Marshaler(m encoding.Marshaler) Option {
 	return func(c *Config) Option {
@@ -431,6 +737,16 @@ func Marshaler(m encoding.Marshaler) Option {
 	}
 }
 
+// NoRecover returns a functional Option that determines whether panics raised upon marshaling
+// log events are left unrecovered, see StreamOrLogger.NoRecover.
+func NoRecover(b bool) Option {
+	return func(c *Config) Option {
+		old := c.Sink.NoRecover
+		c.Sink.NoRecover = b
+		return NoRecover(old)
+	}
+}
+
 // Encoding returns a functional Option that appends the given encoding `Decorator`s to what's
 // currently configured.
 func Encoding(d ...encoding.Decorator) Option {
@@ -447,8 +763,26 @@ func Encoding(d ...encoding.Decorator) Option {
 	}
 }
 
+// Decorate returns a functional Option that appends the given logger `Decorator`s to what's
+// currently configured. Unlike Encoding, these apply to both Stream and Logger sinks.
+func Decorate(d ...logger.Decorator) Option {
+	return func(c *Config) Option {
+		old := c.Sink.LoggerDecorators.Copy()
+		c.Sink.LoggerDecorators = append(c.Sink.LoggerDecorators, d...)
+		return Option(func(c *Config) Option {
+			c.Sink.LoggerDecorators = old
+			return Decorate(d...)
+		})
+	}
+}
+
+// Prefix returns a functional Option that prepends the given prefix to the message of every
+// log event, for both Stream and Logger sinks. See logger.WithPrefix.
+func Prefix(prefix string) Option { return Decorate(logger.WithPrefix(prefix)) }
+
 // CallTracking returns a functional Option that determines whether logging Context is annotated
-// with a caller.Caller, and if so the "caller depth" to use when crawling the runtime call stack.
+// with a caller.Caller, and if so the "caller depth" to use when crawling the runtime call stack
+// and the levels of the log events to annotate (see caller.Tracking.Levels).
 func CallTracking(t caller.Tracking) Option {
 	return func(c *Config) Option {
 		old := c.CallTracking
@@ -457,13 +791,26 @@ func CallTracking(t caller.Tracking) Option {
 	}
 }
 
-// Errors returns a functional Option that establishes a consumer of errors generated by the
-// logging subsystem.
+// Errors returns a functional Option that establishes a chan consumer of errors generated by
+// the logging subsystem, see logger.ErrorChan.
 func Errors(es chan<- error) Option {
+	return ErrorSink(logger.ErrorChan(es))
+}
+
+// NonBlockingErrors returns a functional Option that establishes a chan consumer of errors
+// generated by the logging subsystem; errors are dropped unless the chan is ready to receive
+// them, see logger.TryErrorChan.
+func NonBlockingErrors(es chan<- error) Option {
+	return ErrorSink(logger.TryErrorChan(es))
+}
+
+// ErrorSink returns a functional Option that establishes a consumer of errors generated by the
+// logging subsystem.
+func ErrorSink(es logger.ErrorSink) Option {
 	return func(c *Config) Option {
 		old := c.Sink.Errors
 		c.Sink.Errors = es
-		return Errors(old)
+		return ErrorSink(old)
 	}
 }
 
@@ -477,6 +824,19 @@ func Builder(b logger.Builder) Option {
 	}
 }
 
+// AnnotateErrors is a functional Option that attaches the caller, call stack, and error (if
+// any) of log events at Error and above, see levels.AnnotateErrors.
+func AnnotateErrors() Option {
+	return TransformOps(levels.AnnotateErrors().Apply)
+}
+
+// AnnotateRuntime is a functional Option that attaches a snapshot of the state of the runtime
+// to log events at Fatal and above, and optionally dumps goroutine stacks to `stacks`; see
+// levels.AnnotateRuntime.
+func AnnotateRuntime(stacks io.Stream) Option {
+	return TransformOps(levels.AnnotateRuntime(stacks).Apply)
+}
+
 // TransformOps returns a functional Option that appends the given transform operators to those
 // already defined for the config.
 func TransformOps(ops ...levels.TransformOp) Option {
@@ -492,6 +852,25 @@ func TransformOps(ops ...levels.TransformOp) Option {
 	}
 }
 
+// Guard returns a functional Option that determines how log events are serialized before
+// being handed to the sink. Concurrency-safe sinks may use NoGuard to avoid locking entirely.
+func Guard(g levels.TransformOp) Option {
+	return func(c *Config) Option {
+		old := c.Guard
+		c.Guard = g
+		return Guard(old)
+	}
+}
+
+// Clock returns a functional Option that determines the source of log event timestamps.
+func Clock(c timestamp.Clock) Option {
+	return func(cfg *Config) Option {
+		old := cfg.Clock
+		cfg.Clock = c
+		return Clock(old)
+	}
+}
+
 // AddContext returns a functional Option that applies the given context decorators to the context
 // generated by the current getter.
 func AddContext(d ...context.Decorator) Option {
@@ -501,3 +880,9 @@ func AddContext(d ...context.Decorator) Option {
 		return Context(old)
 	}
 }
+
+// Hooks returns a functional Option that invokes the given hooks around the configured
+// Marshaler, see logger.Hooks. Hooks are not invoked for Logger-based sinks.
+func Hooks(h ...logger.Hook) Option {
+	return Encoding(logger.Hooks(h).Encoding())
+}
@@ -0,0 +1,599 @@
+/*
+Copyright 2016 James DeFelice
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/gologs/log/caller"
+	. "github.com/gologs/log/config"
+	"github.com/gologs/log/context"
+	"github.com/gologs/log/context/fields"
+	"github.com/gologs/log/context/requestid"
+	"github.com/gologs/log/diag"
+	"github.com/gologs/log/encoding"
+	"github.com/gologs/log/encoding/structured"
+	"github.com/gologs/log/io"
+	"github.com/gologs/log/levels"
+	"github.com/gologs/log/logger"
+	"github.com/gologs/log/logger/async"
+	"github.com/gologs/log/logtest"
+)
+
+func TestScoped(t *testing.T) {
+	var (
+		logs   = Logging()
+		expect = func(lvl levels.Level) {
+			for _, x := range []levels.Level{levels.Debug, levels.Info, levels.Warn, levels.Error} {
+				if enabled := levels.Enabled(logs, x); enabled != (x >= lvl) {
+					t.Fatalf("unexpected enabled=%v for level %v, expected threshold %v", enabled, x, lvl)
+				}
+			}
+		}
+	)
+	expect(levels.Info)
+
+	outer := Scoped(Level(levels.Warn))
+	expect(levels.Warn)
+
+	inner := Scoped(Level(levels.Debug))
+	expect(levels.Debug)
+
+	inner()
+	expect(levels.Warn)
+	inner() // noop
+	expect(levels.Warn)
+
+	inner = Scoped(Level(levels.Error))
+	expect(levels.Error)
+
+	outer() // also unwinds inner
+	expect(levels.Info)
+	inner() // noop
+	expect(levels.Info)
+}
+
+func TestExitCodes(t *testing.T) {
+	type componentKey struct{}
+	var (
+		errConfig = errors.New("bad config")
+		exited    []int
+		logs      = DefaultConfig.With(
+			Logger(logger.Null()),
+			OnExit(func(code int) { exited = append(exited, code) }),
+			ExitCode(1),
+			ExitCodes(
+				ExitCodeForError(errConfig, 2),
+				ExitCodeForContext(componentKey{}, 3),
+			),
+		)
+	)
+	logs.Fatal("oops")
+	logs.Fatalf("failed to load: %v", fmt.Errorf("wrapped: %w", errConfig))
+	levels.WithContext(logs, context.NewDecorator(componentKey{}, "db")).Fatal("dependency failed")
+	if expected := []int{1, 2, 3}; !reflect.DeepEqual(expected, exited) {
+		t.Fatalf("expected exit codes %v instead of %v", expected, exited)
+	}
+}
+
+func TestErrorSinks(t *testing.T) {
+	var (
+		got  []string
+		sink = func(name string) logger.ErrorSink {
+			return logger.ErrorSinkFunc(func(_ context.Context, e logger.Entry, _ error) {
+				got = append(got, name+":"+e.Format)
+			})
+		}
+		failing = &io.BufferedStream{EOMFunc: func(_ io.Buffer, _ error) error { return errors.New("oops") }}
+		logs    = DefaultConfig.With(
+			Stream(failing),
+			Level(levels.Debug),
+			ErrorSink(levels.ErrorSinks(sink("default"), map[levels.Level]logger.ErrorSink{
+				levels.Error: sink("error"),
+			})),
+		)
+	)
+	logs.Infof("a")
+	logs.Errorf("b")
+	if expected := []string{"default:a", "error:b"}; !reflect.DeepEqual(expected, got) {
+		t.Fatalf("expected %q instead of %q", expected, got)
+	}
+}
+
+func TestDecorate(t *testing.T) {
+	var (
+		buf    bytes.Buffer
+		output []string
+		sink   = logger.Func(func(_ context.Context, m string, a ...interface{}) {
+			output = append(output, fmt.Sprintf(m, a...))
+		})
+	)
+	DefaultConfig.With(Logger(sink), Prefix("[db] ")).Infof("hello %d", 1)
+	DefaultConfig.With(Stream(io.TextStream(&buf)), Prefix("[db] ")).Infof("hello %d", 2)
+
+	if expected := []string{"[db] hello 1"}; !reflect.DeepEqual(expected, output) {
+		t.Fatalf("expected %q instead of %q", expected, output)
+	}
+	if expected := "[db] hello 2\n"; buf.String() != expected {
+		t.Fatalf("expected %q instead of %q", expected, buf.String())
+	}
+}
+
+func TestExplain(t *testing.T) {
+	var (
+		rec   = logtest.NewRecorder()
+		audit = levels.Named("audit", levels.Broadcast(levels.MatchAtOrAbove(levels.Warn), false, rec))
+		cfg   = DefaultConfig.Copy()
+	)
+	TransformOps(
+		audit,
+		levels.Named("escalate", func(x levels.Level, logs logger.Logger) (levels.Level, logger.Logger) {
+			if x == levels.Warn {
+				x = levels.Error
+			}
+			return x, logs
+		}),
+		levels.Reject(nil),
+	)(&cfg)
+	Level(levels.Warn)(&cfg)
+	expected := `debug: audit, escalate, #3, threshold (dropped)
+info: audit, escalate, #3, threshold (dropped)
+warn: audit, escalate -> error, #3, threshold
+error: audit, escalate, #3, threshold
+fatal: audit, escalate, #3, threshold
+panic: audit, escalate, #3, threshold
+`
+	if s := cfg.Explain(); s != expected {
+		t.Fatalf("expected %q instead of %q", expected, s)
+	}
+
+	logger.SetTracing(true)
+	defer logger.SetTracing(false)
+	DefaultConfig.With(
+		Logger(logger.Null()),
+		TransformOps(audit, levels.Named("prefix", levels.Transform{levels.Error: logger.WithPrefix("!")}.Apply)),
+	).Errorf("boom")
+	entries := rec.Entries()
+	if len(entries) != 1 || entries[0].Message != "!boom" {
+		t.Fatalf("unexpected log events: %v", entries)
+	}
+	if p := logger.Path(entries[0].Context); !reflect.DeepEqual(p, []string{"prefix", "audit"}) {
+		t.Fatalf("unexpected path %q", p)
+	}
+}
+
+func TestDescribe(t *testing.T) {
+	logs := async.New(logger.Null(), async.Options{Capacity: 8})
+	defer logs.Close()
+	defer Update(Update(Logger(logs), CallTracking(caller.Tracking{Enabled: true, Depth: 2})))
+
+	d := Describe()
+	if d.Sink != "*async.Logger" || d.Settings["capacity"] != 8 || !d.CallTracking || d.CallerDepth != 2 {
+		t.Errorf("unexpected description %v", d)
+	}
+	if len(d.Pipeline) != 6 || d.Pipeline[0] != "debug: threshold (dropped)" {
+		t.Errorf("unexpected pipeline %q", d.Pipeline)
+	}
+
+	cfg := DefaultConfig.Copy()
+	Stream(io.Null())(&cfg)
+	Marshaler(encoding.Format())(&cfg)
+	Prefix("[db] ")(&cfg)
+	d = cfg.Describe()
+	if d.Marshaler != "encoding.Format" || !reflect.DeepEqual(d.LoggerDecorators, []string{"logger.WithPrefix"}) {
+		t.Errorf("unexpected description %v", d)
+	}
+
+	rec := httptest.NewRecorder()
+	Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/debug/logging", nil))
+	var served Description
+	if err := json.Unmarshal(rec.Body.Bytes(), &served); err != nil {
+		t.Fatal(err)
+	}
+	if served.Sink != "*async.Logger" {
+		t.Errorf("unexpected description %v", served)
+	}
+}
+
+func TestCallTrackingLevels(t *testing.T) {
+	var (
+		tracked = map[string]bool{}
+		logs    = DefaultConfig.With(
+			Level(levels.Debug),
+			CallTracking(caller.Tracking{
+				Enabled: true,
+				Depth:   DefaultCallerDepth,
+				Levels:  int(levels.Warn | levels.Error | levels.Fatal | levels.Panic),
+			}),
+			Logger(logger.Func(func(c context.Context, m string, _ ...interface{}) {
+				_, tracked[m] = caller.FromContext(c)
+			})),
+		)
+	)
+	logs.Debugf("debug")
+	logs.Infof("info")
+	logs.Warnf("warn")
+	logs.Errorf("error")
+	if want := map[string]bool{"debug": false, "info": false, "warn": true, "error": true}; !reflect.DeepEqual(tracked, want) {
+		t.Errorf("expected %v instead of %v", want, tracked)
+	}
+
+	cfg := DefaultConfig.Copy()
+	CallTracking(caller.Tracking{Enabled: true, Depth: 2, Levels: int(levels.Warn | levels.Error)})(&cfg)
+	if d := cfg.Describe(); !reflect.DeepEqual(d.CallerLevels, []string{"warn", "error"}) {
+		t.Errorf("unexpected caller levels %q", d.CallerLevels)
+	}
+}
+
+func TestDowngrade(t *testing.T) {
+	var (
+		got  []string
+		logs = DefaultConfig.With(
+			Downgrade(true),
+			OnExit(func(int) { t.Fatal("unexpected exit") }),
+			Logger(logger.Func(func(c context.Context, m string, a ...interface{}) {
+				lvl, _ := levels.FromContext(c)
+				ff := fields.FromContext(c)
+				from, _ := ff.Get(DowngradedKey)
+				_, stack := ff.Get("stack")
+				got = append(got, fmt.Sprintf("%v %v %v %s", lvl, from, stack, encoding.Message(m, a...)))
+			})),
+		)
+	)
+	logs.Fatalf("fatal %d", 1)
+	logs.Panic("panic")
+	logs.Errorf("error")
+	expected := []string{"error fatal false fatal 1", "error panic true panic", "error <nil> false error"}
+	if !reflect.DeepEqual(expected, got) {
+		t.Fatalf("expected %q instead of %q", expected, got)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	if err := DefaultConfig.Validate(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	cfg := DefaultConfig.Copy()
+	Stream(io.Null())(&cfg)
+	Logger(logger.Null())(&cfg)
+	CallTracking(caller.Tracking{Enabled: true, Depth: -1})(&cfg)
+	err, ok := cfg.Validate().(ValidationError)
+	if !ok || len(err) != 2 {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	cfg = DefaultConfig.Copy()
+	Logger(logger.Null())(&cfg)
+	Encoding(encoding.NoDecorator())(&cfg)
+	if err := cfg.Validate(); err == nil || !regexp.MustCompile("Sink.Decorators").MatchString(err.Error()) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	cfg.With() // never fails, see Build
+}
+
+func TestBuild(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gologs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	if _, _, err := DefaultConfig.Build(OpenSink("file:" + filepath.Join(dir, "missing", "app.log"))); err == nil {
+		t.Fatal("expected an error for a file in a missing directory")
+	}
+	if _, _, err := DefaultConfig.Build(Logger(logger.Null()), NoRecover(true)); err == nil {
+		t.Fatal("expected a validation error")
+	}
+
+	path := filepath.Join(dir, "app.log")
+	logs, cleanup, err := DefaultConfig.Build(OpenSink("file:" + path))
+	if err != nil {
+		t.Fatal(err)
+	}
+	logs.Infof("hello %d", 1)
+	cleanup()
+	if b, err := ioutil.ReadFile(path); err != nil || string(b) != "hello 1\n" {
+		t.Fatalf("unexpected log file contents %q: %v", b, err)
+	}
+}
+
+func TestWith_Open(t *testing.T) {
+	opened := 0
+	open := Open(func() (io.Stream, error) {
+		opened++
+		return io.Null(), nil
+	})
+	DefaultConfig.With(open)
+	DefaultConfig.WithRollback(open, Logger(logger.Null()))
+	if opened != 0 {
+		t.Fatalf("expected With to leave the sink unopened instead of opening it %d times", opened)
+	}
+}
+
+func TestFluent(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gologs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "app.log")
+	logs, cleanup, err := New().
+		Level(levels.Debug).
+		JSON().
+		CallTracking(false).
+		File(path).Rotate(1<<20, 2).
+		Async(16).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	logs.Debugf("hello %d", 1)
+	cleanup()
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !regexp.MustCompile(`^\{"time":"[^"]+","level":"debug","msg":"hello 1"\}\n$`).Match(b) {
+		t.Fatalf("unexpected log file contents %q", b)
+	}
+	if _, _, err := New().File(filepath.Join(dir, "missing", "app.log")).Build(); err == nil {
+		t.Fatal("expected an error for a file in a missing directory")
+	}
+	if _, cleanup, err := New().Level(levels.Debug).JSON().Build(); err != nil {
+		t.Fatalf("expected an encoder to default to a Stream sink instead of %v", err)
+	} else {
+		cleanup()
+	}
+	if cfg := New().JSON().Config(); cfg.Sink.Stream == nil || cfg.Sink.Logger != nil {
+		t.Fatalf("expected an encoder to default to a Stream sink instead of %+v", cfg.Sink)
+	}
+	if _, _, err := New().Stderr().Rotate(1<<20, 2).Build(); err == nil {
+		t.Fatal("expected an error for rotation without a file")
+	}
+}
+
+func TestErrorfE(t *testing.T) {
+	var (
+		oops    = errors.New("oops")
+		sunk    int
+		callers []caller.Caller
+		logs    = levels.E(DefaultConfig.With(
+			Stream(io.Null()),
+			Level(levels.Debug),
+			CallTracking(caller.Tracking{Enabled: true, Depth: DefaultCallerDepth - 1}),
+			Marshaler(func(c context.Context, _ io.Stream, m string, _ ...interface{}) error {
+				x, _ := caller.FromContext(c)
+				callers = append(callers, x)
+				if m == "fail" {
+					return oops
+				}
+				return nil
+			}),
+			ErrorSink(logger.ErrorSinkFunc(func(context.Context, logger.Entry, error) { sunk++ })),
+		))
+	)
+	if err := logs.ErrorfE("fail"); err != oops {
+		t.Fatalf("expected %v instead of %v", oops, err)
+	}
+	if err := logs.InfofE("ok"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if sunk != 0 {
+		t.Fatalf("expected captured errors to bypass the error sink")
+	}
+	for _, c := range callers {
+		if filepath.Base(c.File) != "config_test.go" {
+			t.Fatalf("unexpected caller %+v", c)
+		}
+	}
+}
+
+func TestTraceOnError(t *testing.T) {
+	var (
+		output []string
+		sink   = logger.Func(func(c context.Context, m string, a ...interface{}) {
+			lvl, _ := levels.FromContext(c)
+			output = append(output, lvl.String()[:1]+" "+fmt.Sprintf(m, a...))
+		})
+		logs    = DefaultConfig.With(Logger(sink), TraceOnError(levels.Info, 2))
+		request = func(id string) levels.Interface {
+			return levels.WithContext(logs, requestid.NewDecorator(id))
+		}
+		a, b = request("a"), request("b")
+	)
+	logs.Debugf("no request")
+	a.Debugf("a%d", 1)
+	b.Debugf("b%d", 1)
+	a.Debugf("a%d", 2)
+	a.Debugf("a%d", 3)
+	a.Infof("a%d", 4)
+	b.Errorf("b%d", 2)
+	a.Errorf("a%d", 5)
+	a.Errorf("a%d", 6)
+
+	expected := []string{"i a4", "d b1", "e b2", "d a2", "d a3", "e a5", "e a6"}
+	if !reflect.DeepEqual(expected, output) {
+		t.Fatalf("expected %q instead of %q", expected, output)
+	}
+}
+
+func TestDebugFor(t *testing.T) {
+	var (
+		output []string
+		sink   = logger.Func(func(_ context.Context, m string, a ...interface{}) {
+			output = append(output, fmt.Sprintf(m, a...))
+		})
+		logs = func(patterns ...string) levels.Interface {
+			return DefaultConfig.With(
+				Logger(sink),
+				CallTracking(caller.Tracking{Enabled: true, Depth: DefaultCallerDepth - 1}),
+				DebugFor(patterns...))
+		}
+	)
+	logs("config").Debugf("config %d", 1)
+	logs("config/config_test.go").Debugf("config %d", 2)
+	logs("io/rotate").Debugf("rotate %d", 1)
+	logs("io/rotate").Infof("info %d", 1)
+	logs("fig").Debugf("fig %d", 1)
+
+	expected := []string{"config 1", "config 2", "info 1"}
+	if !reflect.DeepEqual(expected, output) {
+		t.Fatalf("expected %q instead of %q", expected, output)
+	}
+}
+
+func TestAnnotateErrors(t *testing.T) {
+	var (
+		buf  bytes.Buffer
+		oops = errors.New("oops")
+		logs = DefaultConfig.With(
+			Stream(io.TextStream(&buf)),
+			Marshaler(structured.JSON(structured.Options{TimeKey: "-"})),
+			CallTracking(caller.Tracking{}),
+			AnnotateErrors(),
+		)
+		expected = regexp.MustCompile(`^` +
+			`\{"level":"info","msg":"ok"\}\n` +
+			`\{"level":"error","msg":"failed: oops","caller":"[^"]*/config_test.go:\d+",` +
+			`"stack":\["[^"]*config_test.TestAnnotateErrors [^"]*/config_test.go:\d+",[^]]*\],"error":"oops"\}\n$`)
+	)
+	logs.Infof("ok")
+	logs.Errorf("failed: %v", oops)
+	if !expected.MatchString(buf.String()) {
+		t.Fatalf("unexpected output: %s", buf.String())
+	}
+}
+
+func TestAnnotateRuntime(t *testing.T) {
+	var (
+		buf, stacks bytes.Buffer
+		logs        = DefaultConfig.With(
+			Stream(io.TextStream(&buf)),
+			Marshaler(structured.JSON(structured.Options{TimeKey: "-"})),
+			CallTracking(caller.Tracking{}),
+			OnExit(NoExit()),
+			AnnotateRuntime(io.TextStream(&stacks)),
+		)
+		expected = regexp.MustCompile(`^` +
+			`\{"level":"error","msg":"e"\}\n` +
+			`\{"level":"fatal","msg":"f","goroutines":\d+,"memstats":\{"gc_pause_total":"[^"]+",` +
+			`"heap_alloc":\d+,"heap_objects":\d+,"heap_sys":\d+,"last_gc":"[^"]+","num_gc":\d+,"sys":\d+\}\}\n$`)
+	)
+	logs.Errorf("e")
+	logs.Fatalf("f")
+	if !expected.MatchString(buf.String()) {
+		t.Fatalf("unexpected output: %s", buf.String())
+	}
+	if !bytes.Contains(stacks.Bytes(), []byte("TestAnnotateRuntime")) {
+		t.Fatalf("expected goroutine stacks instead of %q", stacks.String())
+	}
+}
+
+type boom struct{}
+
+func (boom) String() string { panic("boom") }
+
+func TestNoRecover(t *testing.T) {
+	var (
+		errs      []error
+		marshaler = func(_ context.Context, s io.Stream, _ string, a ...interface{}) error {
+			for _, x := range a {
+				if str, ok := x.(fmt.Stringer); ok {
+					_ = str.String()
+				}
+			}
+			return s.EOM(nil)
+		}
+		logs = DefaultConfig.With(
+			Stream(io.Null()),
+			Marshaler(marshaler),
+			ErrorSink(logger.ErrorSinkFunc(func(_ context.Context, _ logger.Entry, err error) {
+				errs = append(errs, err)
+			})),
+		)
+	)
+	logs.Info(boom{})
+	if len(errs) != 1 {
+		t.Fatalf("expected a reported panic instead of %v", errs)
+	}
+
+	defer func() {
+		if x := recover(); x != "boom" {
+			t.Fatalf("expected an unrecovered panic instead of %v", x)
+		}
+	}()
+	DefaultConfig.With(Stream(io.Null()), Marshaler(marshaler), NoRecover(true)).Info(boom{})
+	t.Fatalf("expected a panic")
+}
+
+func TestWatchHealth(t *testing.T) {
+	var (
+		oops   = errors.New("oops")
+		rec    = logtest.NewRecorder()
+		stream = io.Monitor(&io.BufferedStream{})
+	)
+	defer diag.SetLogger(diag.SetLogger(rec))
+	defer Update(Update(Stream(stream)))
+
+	stop := WatchHealth(time.Millisecond)
+	defer stop()
+	_ = stream.EOM(oops)
+	for len(rec.Entries()) < 1 {
+		time.Sleep(time.Millisecond)
+	}
+	_ = stream.EOM(nil)
+	for len(rec.Entries()) < 2 {
+		time.Sleep(time.Millisecond)
+	}
+	stop()
+
+	var got []string
+	for _, e := range rec.Entries() {
+		got = append(got, e.Message)
+	}
+	if expected := []string{"log stream is unhealthy: oops", "log stream has recovered"}; !reflect.DeepEqual(expected, got) {
+		t.Fatalf("expected %q instead of %q", expected, got)
+	}
+}
+
+func TestAuto(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func(stderr *os.File) { os.Stderr = stderr }(os.Stderr)
+	os.Stderr = w // not a terminal
+
+	DefaultConfig.With(Auto(), CallTracking(caller.Tracking{})).Infof("hello %d", 1)
+	w.Close()
+	b, _ := ioutil.ReadAll(r)
+	if matched, _ := regexp.Match(`^\{"time":"[^"]+","level":"info","msg":"hello 1"\}\n$`, b); !matched {
+		t.Fatalf("unexpected output %q", b)
+	}
+}
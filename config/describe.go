@@ -0,0 +1,153 @@
+/*
+Copyright 2016 James DeFelice
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"runtime"
+	"strings"
+
+	"github.com/gologs/log/levels"
+)
+
+// Describer is an optional extension of sinks, Streams and Loggers alike, that reports their
+// settings (for example, the capacity of a queue) for Describe.
+type Describer interface {
+	Describe() map[string]interface{}
+}
+
+// Description is a structured summary of a Config, intended to be logged at startup (see
+// Description.String) or served for debugging (see Handler), so that operators can verify what
+// a binary is actually configured to do. Funcs are identified by the names of the funcs that
+// generated them, such as "structured.JSON".
+type Description struct {
+	Sink             string                 `json:"sink"`                       // Sink is the type of the Stream or Logger
+	Settings         map[string]interface{} `json:"settings,omitempty"`         // Settings of the sink, see Describer
+	Marshaler        string                 `json:"marshaler,omitempty"`        // Marshaler of the Stream
+	Decorators       []string               `json:"decorators,omitempty"`       // Decorators of the Stream
+	LoggerDecorators []string               `json:"loggerDecorators,omitempty"` // LoggerDecorators of the sink
+	Builder          string                 `json:"builder,omitempty"`          // Builder of the Stream's Logger
+	Pipeline         []string               `json:"pipeline"`                   // Pipeline of each level, see Config.Explain
+	CallTracking     bool                   `json:"callTracking"`
+	CallerDepth      int                    `json:"callerDepth,omitempty"`
+	CallerLevels     []string               `json:"callerLevels,omitempty"` // CallerLevels, unless all
+	Guard            string                 `json:"guard,omitempty"`
+	ExitCode         int                    `json:"exitCode"`
+	Downgrade        bool                   `json:"downgrade,omitempty"` // Downgrade of Fatal and Panic
+}
+
+// String renders the Description as JSON.
+func (d Description) String() string {
+	b, err := json.Marshal(d)
+	if err != nil {
+		type plain Description // avoids recursion
+		return fmt.Sprintf("%+v", plain(d))
+	}
+	return string(b)
+}
+
+// funcName returns the name of the func that generated `f`, without the path of its package
+func funcName(f interface{}) string {
+	v := reflect.ValueOf(f)
+	if !v.IsValid() || v.Kind() != reflect.Func || v.IsNil() {
+		return ""
+	}
+	fn := runtime.FuncForPC(v.Pointer())
+	if fn == nil {
+		return v.Type().String()
+	}
+	name := fn.Name()
+	if i := strings.LastIndexByte(name, '/'); i >= 0 {
+		name = name[i+1:]
+	}
+	// closures are named for the funcs that generated them: pkg.Func.func1.2
+	for {
+		i := strings.LastIndexByte(name, '.')
+		if i < 0 {
+			break
+		}
+		suffix := name[i+1:]
+		if !strings.HasPrefix(suffix, "func") && strings.TrimLeft(suffix, "0123456789") != "" {
+			break
+		}
+		name = name[:i]
+	}
+	return name
+}
+
+// Describe returns a Description of the configuration.
+func (cfg Config) Describe() Description {
+	d := Description{
+		Pipeline:     strings.Split(strings.TrimSuffix(cfg.Explain(), "\n"), "\n"),
+		CallTracking: cfg.CallTracking.Enabled,
+		Guard:        funcName(cfg.Guard),
+		ExitCode:     cfg.ExitCode,
+		Downgrade:    cfg.Downgrade,
+	}
+	if d.CallTracking {
+		d.CallerDepth = cfg.CallTracking.Depth
+		if mask := levels.Level(cfg.CallTracking.Levels); mask != 0 {
+			for lvl := levels.Debug; lvl <= levels.Panic; lvl <<= 1 {
+				if mask&lvl != 0 {
+					d.CallerLevels = append(d.CallerLevels, lvl.String())
+				}
+			}
+		}
+	}
+	var sink interface{}
+	switch {
+	case cfg.Sink.Stream != nil:
+		sink = cfg.Sink.Stream
+		d.Marshaler = funcName(cfg.Sink.Marshaler)
+		for _, x := range cfg.Sink.Decorators {
+			d.Decorators = append(d.Decorators, funcName(x))
+		}
+		d.Builder = funcName(cfg.Sink.Builder)
+	case cfg.Sink.Logger != nil:
+		sink = cfg.Sink.Logger
+	}
+	if sink == nil {
+		d.Sink = "logger.SystemLogger"
+	} else {
+		d.Sink = fmt.Sprintf("%T", sink)
+	}
+	if x, ok := sink.(Describer); ok {
+		d.Settings = x.Describe()
+	}
+	for _, x := range cfg.Sink.LoggerDecorators {
+		d.LoggerDecorators = append(d.LoggerDecorators, funcName(x))
+	}
+	return d
+}
+
+// Describe returns a Description of the configuration most recently established by Update or
+// Apply (initially DefaultConfig); instances installed via SetLogging are disregarded.
+func Describe() Description { return current.Load().(*logging).cfg.Describe() }
+
+// Handler returns an http.Handler that serves the result of Describe as JSON, for example on a
+// debug endpoint.
+func Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		enc.Encode(Describe())
+	})
+}
@@ -0,0 +1,229 @@
+/*
+Copyright 2016 James DeFelice
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"bufio"
+	"bytes"
+	stdcontext "context"
+	"fmt"
+	stdio "io"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gologs/log/context"
+	"github.com/gologs/log/diag"
+	"github.com/gologs/log/encoding"
+	"github.com/gologs/log/io"
+	"github.com/gologs/log/levels"
+	"github.com/gologs/log/logger"
+)
+
+// Parse reads a declarative configuration and returns the equivalent functional Options.
+// The configuration is line-oriented: each line is either blank, a comment that begins with
+// '#', or a `key = value` setting. Supported keys are:
+//
+//	level        = debug|info|warn|error|fatal|panic
+//	exitcode     = <int>
+//	calltracking = <bool>
+//	calldepth    = <int>
+//	encoding     = <name>         (see encoding.Register)
+//	sink         = <name>[:<arg>] (see io.RegisterSink)
+//
+// The sink is opened once the configuration has been parsed; it's up to the caller to close it
+// (if it implements io.Closer) once it's no longer in use.
+func Parse(r stdio.Reader) (opts []Option, err error) {
+	opts, _, err = parse(r)
+	return
+}
+
+// parse is like Parse, and also returns the sink that it opened, if any
+func parse(r stdio.Reader) (opts []Option, sink io.Stream, err error) {
+	var (
+		scanner  = bufio.NewScanner(r)
+		lineno   int
+		depth    *int
+		enabled  *bool
+		spec     string
+		specLine int
+	)
+	for scanner.Scan() {
+		lineno++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || line[0] == '#' {
+			continue
+		}
+		i := strings.IndexByte(line, '=')
+		if i < 0 {
+			return nil, nil, fmt.Errorf("line %d: expected key = value", lineno)
+		}
+		var (
+			key   = strings.ToLower(strings.TrimSpace(line[:i]))
+			value = strings.TrimSpace(line[i+1:])
+		)
+		switch key {
+		case "level":
+			lvl, ok := levels.ParseLevel(value)
+			if !ok {
+				return nil, nil, fmt.Errorf("line %d: unknown level %q", lineno, value)
+			}
+			opts = append(opts, Level(lvl))
+		case "exitcode":
+			code, err := strconv.Atoi(value)
+			if err != nil {
+				return nil, nil, fmt.Errorf("line %d: bad exitcode: %v", lineno, err)
+			}
+			opts = append(opts, ExitCode(code))
+		case "calltracking":
+			b, err := strconv.ParseBool(value)
+			if err != nil {
+				return nil, nil, fmt.Errorf("line %d: bad calltracking: %v", lineno, err)
+			}
+			enabled = &b
+		case "calldepth":
+			d, err := strconv.Atoi(value)
+			if err != nil {
+				return nil, nil, fmt.Errorf("line %d: bad calldepth: %v", lineno, err)
+			}
+			depth = &d
+		case "encoding":
+			m, ok := encoding.Lookup(value)
+			if !ok {
+				return nil, nil, fmt.Errorf("line %d: unknown encoding %q", lineno, value)
+			}
+			opts = append(opts, Marshaler(m))
+		case "sink":
+			spec, specLine = value, lineno
+		default:
+			return nil, nil, fmt.Errorf("line %d: unknown key %q", lineno, key)
+		}
+	}
+	if err = scanner.Err(); err != nil {
+		return nil, nil, err
+	}
+	if enabled != nil || depth != nil {
+		opts = append(opts, callTracking(enabled, depth))
+	}
+	if specLine > 0 {
+		if sink, err = io.OpenSink(spec); err != nil {
+			return nil, nil, fmt.Errorf("line %d: %v", specLine, err)
+		}
+		opts = append(opts, Stream(sink))
+	}
+	return
+}
+
+// callTracking modifies only those aspects of caller.Tracking that are specified
+func callTracking(enabled *bool, depth *int) Option {
+	return func(c *Config) Option {
+		old := c.CallTracking
+		if enabled != nil {
+			c.CallTracking.Enabled = *enabled
+		}
+		if depth != nil {
+			c.CallTracking.Depth = *depth
+		}
+		return CallTracking(old)
+	}
+}
+
+// Load reads the declarative configuration file found at `path` (see Parse) and uses Apply to
+// reconfigure the current logging instance. It returns an Option that undoes the changes.
+func Load(path string) (Option, error) {
+	undo, _, err := load(path)
+	return undo, err
+}
+
+// load is like Load, and also returns the sink that it opened, if any
+func load(path string) (Option, io.Stream, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	opts, sink, err := parse(bytes.NewReader(b))
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return Apply(opts...), sink, nil
+}
+
+// Watch polls the declarative configuration file found at `path` every `interval` (if positive;
+// otherwise the file is loaded just once) and, upon detecting a change, reloads it via Load.
+// Errors encountered while reading or parsing the file are reported to `errs` (or, if nil, via
+// diag) with a context that's done once the watcher is stopped, and the current configuration
+// is left in place; see logger.ErrorChan for callers that consume errors from a chan. Settings
+// that are removed from the file retain their most recently loaded values. A sink that was
+// opened by a reload is closed once a subsequent reload replaces it. The returned func stops
+// the watcher.
+func Watch(path string, interval time.Duration, errs logger.ErrorSink) (stop func()) {
+	if errs == nil {
+		errs = logger.ErrorSinkFunc(func(_ context.Context, _ logger.Entry, err error) {
+			diag.Logf("failed to reload config: %v", err)
+		})
+	}
+	var (
+		ctx, cancel = stdcontext.WithCancel(stdcontext.Background())
+		ticker      *time.Ticker
+		lastMod     time.Time
+		lastSize    int64 = -1
+		missing     bool
+		sink        io.Stream // sink was opened by the most recent reload that specified one
+		report      = func(err error) { errs.LogError(ctx, logger.Entry{}, err) }
+	)
+	if interval > 0 {
+		ticker = time.NewTicker(interval)
+	}
+	go func() {
+		if ticker != nil {
+			defer ticker.Stop()
+		}
+		for {
+			fi, err := os.Stat(path)
+			if err != nil {
+				if !missing {
+					// don't flood errs while the file is missing
+					missing = true
+					report(err)
+				}
+			} else if fi.ModTime() != lastMod || fi.Size() != lastSize {
+				missing = false
+				lastMod, lastSize = fi.ModTime(), fi.Size()
+				_, s, err := load(path)
+				if err != nil {
+					report(err)
+				} else if s != nil {
+					if c, ok := sink.(stdio.Closer); ok {
+						_ = c.Close()
+					}
+					sink = s
+				}
+			}
+			if ticker == nil {
+				return
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	return cancel
+}
@@ -0,0 +1,208 @@
+/*
+Copyright 2016 James DeFelice
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config_test
+
+import (
+	stdio "io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/gologs/log/config"
+	"github.com/gologs/log/levels"
+	"github.com/gologs/log/logger"
+)
+
+func TestParse(t *testing.T) {
+	opts, err := Parse(strings.NewReader(`
+# a comment
+level = debug
+exitcode = 3
+calltracking = false
+`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	cfg := Porcelain()
+	for _, o := range opts {
+		o(&cfg)
+	}
+	if cfg.ExitCode != 3 {
+		t.Errorf("expected exit code 3 instead of %d", cfg.ExitCode)
+	}
+	if cfg.CallTracking.Enabled || cfg.CallTracking.Depth != DefaultCallerDepth {
+		t.Errorf("unexpected call tracking: %+v", cfg.CallTracking)
+	}
+	if !levels.Enabled(cfg.With(NoOption()), levels.Debug) {
+		t.Errorf("expected debug level to be enabled")
+	}
+
+	for _, bad := range []string{
+		"level", "level = loud", "exitcode = x", "calltracking = 2", "foo = bar",
+		"encoding = bogus", "sink = bogus", "sink = file:",
+	} {
+		if _, err = Parse(strings.NewReader(bad)); err == nil {
+			t.Errorf("expected error for %q", bad)
+		}
+	}
+}
+
+func TestParse_Sink(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gologs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "app.log")
+	opts, err := Parse(strings.NewReader("encoding = text\nsink = file:" + path + "\n"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	cfg := DefaultConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	defer cfg.Sink.Stream.(stdio.Closer).Close()
+
+	DefaultConfig.With(opts...).Info("hello")
+
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := string(b); !strings.HasSuffix(s, "hello\n") {
+		t.Fatalf("unexpected log file contents %q", s)
+	}
+}
+
+func TestWatch(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gologs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	var (
+		path = filepath.Join(dir, "log.conf")
+		errs = make(chan error, 1)
+		logs = Logging()
+	)
+	defer Apply(Set(DefaultConfig))
+
+	if err = ioutil.WriteFile(path, []byte("level = bogus\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	stop := Watch(path, 10*time.Millisecond, logger.ErrorChan(errs))
+	defer stop()
+
+	select {
+	case <-errs:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for parse error")
+	}
+
+	if err = ioutil.WriteFile(path, []byte("level = debug\nexitcode = 2\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	for deadline := time.Now().Add(5 * time.Second); !levels.Enabled(logs, levels.Debug); {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for reload")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestWatch_Once(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gologs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	var (
+		path = filepath.Join(dir, "log.conf")
+		errs = make(chan error, 1)
+	)
+	if err = ioutil.WriteFile(path, []byte("level = bogus\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	stop := Watch(path, 0, logger.ErrorChan(errs))
+	defer stop()
+
+	select {
+	case <-errs:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for parse error")
+	}
+}
+
+func TestWatch_Sink(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gologs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	var (
+		path   = filepath.Join(dir, "log.conf")
+		first  = filepath.Join(dir, "first.log")
+		second = filepath.Join(dir, "second.log")
+		errs   = make(chan error, 1)
+		logs   = Logging()
+	)
+	defer Apply(Set(DefaultConfig))
+
+	reload := func(sink string, ok func() bool) {
+		if err := ioutil.WriteFile(path, []byte("encoding = text\nsink = file:"+sink+"\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		for deadline := time.Now().Add(5 * time.Second); !ok(); {
+			select {
+			case err := <-errs:
+				t.Fatalf("unexpected reload error: %v", err)
+			default:
+			}
+			if time.Now().After(deadline) {
+				t.Fatal("timed out waiting for reload")
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+	streamOf := func() (c stdio.Closer) {
+		Apply(func(cfg *Config) Option {
+			c, _ = cfg.Sink.Stream.(stdio.Closer)
+			return NoOption()
+		})
+		return
+	}
+
+	stop := Watch(path, 10*time.Millisecond, logger.ErrorChan(errs))
+	defer stop()
+
+	reload(first, func() bool { return streamOf() != nil })
+	prev := streamOf()
+
+	reload(second, func() bool { return streamOf() != prev })
+	if err = prev.Close(); err == nil {
+		t.Fatal("expected the replaced sink to have been closed")
+	}
+	logs.Info("hello")
+}
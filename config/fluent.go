@@ -0,0 +1,174 @@
+/*
+Copyright 2016 James DeFelice
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"github.com/gologs/log/caller"
+	"github.com/gologs/log/encoding"
+	"github.com/gologs/log/encoding/structured"
+	"github.com/gologs/log/io"
+	"github.com/gologs/log/levels"
+	"github.com/gologs/log/logger"
+	"github.com/gologs/log/logger/async"
+)
+
+// Fluent is a chainable builder of logging configurations, layered on Options. For example:
+//
+//	logs, cleanup, err := config.New().
+//		Level(levels.Debug).
+//		JSON().
+//		File("/var/log/app.log").Rotate(100<<20, 5).
+//		Async(8192).
+//		Build()
+//
+// Methods apply Options in the order that they're invoked, see Option for anything else. The
+// encoders (Text, JSON, and Logfmt) write to Stderr unless another Stream sink or a File is
+// chosen.
+type Fluent struct {
+	cfg     Config
+	opts    []Option
+	file    string
+	size    int64
+	keep    int
+	rotate  bool
+	queue   int
+	isAsync bool
+}
+
+// New returns a Fluent builder that starts from DefaultConfig.
+func New() *Fluent { return From(DefaultConfig) }
+
+// From returns a Fluent builder that starts from the given Config.
+func From(cfg Config) *Fluent { return &Fluent{cfg: cfg.Copy()} }
+
+// Option appends the given Options.
+func (f *Fluent) Option(opt ...Option) *Fluent {
+	f.opts = append(f.opts, opt...)
+	return f
+}
+
+// Level logs events at or above the given Level, see Level.
+func (f *Fluent) Level(min levels.Level) *Fluent { return f.Option(Level(min)) }
+
+// Text renders log events as text, see encoding.Format.
+func (f *Fluent) Text() *Fluent { return f.Option(Marshaler(encoding.Format())) }
+
+// JSON renders log events as JSON lines, see structured.JSON.
+func (f *Fluent) JSON() *Fluent { return f.Option(Marshaler(structured.JSON(structured.Options{}))) }
+
+// Logfmt renders log events as logfmt lines, see structured.Logfmt.
+func (f *Fluent) Logfmt() *Fluent {
+	return f.Option(Marshaler(structured.Logfmt(structured.Options{})))
+}
+
+// Stderr writes log events to stderr, see io.Stderr.
+func (f *Fluent) Stderr() *Fluent { return f.Option(Stream(io.Stderr()), Logger(nil)) }
+
+// Stdout writes log events to stdout, see io.Stdout.
+func (f *Fluent) Stdout() *Fluent { return f.Option(Stream(io.Stdout()), Logger(nil)) }
+
+// File appends log events to the file at the given path, which is opened upon Build.
+func (f *Fluent) File(path string) *Fluent {
+	f.file = path
+	return f
+}
+
+// Rotate rotates the File once it would exceed `maxSize` bytes, keeping up to `keep` rotated
+// files; see io.OpenRotatingFile.
+func (f *Fluent) Rotate(maxSize int64, keep int) *Fluent {
+	f.size, f.keep, f.rotate = maxSize, keep, true
+	return f
+}
+
+// Async hands log events off to a background goroutine via a queue with the given capacity,
+// see async.New; the queue is flushed by the cleanup func returned by Build.
+func (f *Fluent) Async(capacity int) *Fluent {
+	f.queue, f.isAsync = capacity, true
+	return f
+}
+
+// CallTracking enables (or disables) call tracking at DefaultCallerDepth.
+func (f *Fluent) CallTracking(enabled bool) *Fluent {
+	return f.Option(CallTracking(caller.Tracking{Enabled: enabled, Depth: DefaultCallerDepth}))
+}
+
+// Config returns the Config established by the builder, without opening the File.
+func (f *Fluent) Config() Config {
+	cfg := f.cfg.Copy()
+	for _, o := range f.options(nil) {
+		if o != nil {
+			_ = o(&cfg)
+		}
+	}
+	return cfg
+}
+
+func (f *Fluent) options(queue **async.Logger) []Option {
+	opts := append([]Option(nil), f.opts...)
+	switch {
+	case f.file != "" && f.rotate:
+		path, size, keep := f.file, f.size, f.keep
+		opts = append(opts, Open(func() (io.Stream, error) { return io.OpenRotatingFile(path, size, keep) }))
+	case f.file != "":
+		opts = append(opts, OpenSink("file:"+f.file))
+	}
+	opts = append(opts, defaultStream)
+	if f.isAsync {
+		capacity := f.queue
+		opts = append(opts, Decorate(func(logs logger.Logger) logger.Logger {
+			if logger.IsNull(logs) {
+				return logs
+			}
+			q := async.New(logs, async.Options{Capacity: capacity})
+			if queue != nil {
+				*queue = q
+			}
+			return q
+		}))
+	}
+	return opts
+}
+
+// defaultStream writes log events to io.Stderr if an encoder was chosen without a Stream sink,
+// to which encoders apply
+func defaultStream(c *Config) Option {
+	if c.Sink.Marshaler == nil || c.Sink.Stream != nil || c.Sink.Open != nil {
+		return NoOption()
+	}
+	old := c.Sink
+	c.Sink.Stream, c.Sink.Logger = io.Stderr(), nil
+	return Sink(old)
+}
+
+// Build generates the logging interface, see Config.Build. It fails if Rotate is configured
+// without a File. The cleanup func flushes the Async queue, if any, and then closes the File.
+func (f *Fluent) Build() (levels.Interface, func(), error) {
+	if f.rotate && f.file == "" {
+		return nil, nil, ValidationError{"Rotate is configured but only applies to a File sink"}
+	}
+	var q *async.Logger
+	i, cleanup, err := f.cfg.Build(f.options(&q)...)
+	if err != nil {
+		return nil, nil, err
+	}
+	return i, func() {
+		if q != nil {
+			q.Close()
+		}
+		cleanup()
+	}, nil
+}
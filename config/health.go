@@ -0,0 +1,61 @@
+/*
+Copyright 2016 James DeFelice
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"time"
+
+	"github.com/gologs/log/diag"
+	"github.com/gologs/log/io"
+)
+
+// currentStream is a HealthChecker for the Stream of the current logging configuration; streams
+// that don't implement io.HealthChecker are considered to be healthy.
+type currentStream struct{}
+
+func (currentStream) checker() (io.HealthChecker, bool) {
+	hc, ok := current.Load().(*logging).cfg.Sink.Stream.(io.HealthChecker)
+	return hc, ok
+}
+
+func (s currentStream) Ping() error {
+	if hc, ok := s.checker(); ok {
+		return hc.Ping()
+	}
+	return nil
+}
+
+func (s currentStream) Status() error {
+	if hc, ok := s.checker(); ok {
+		return hc.Status()
+	}
+	return nil
+}
+
+// WatchHealth checks, every `interval`, the health of the Stream of the logging configuration
+// most recently established by Update or Apply (see io.HealthChecker and io.Monitor). Changes
+// are reported via diag: when the stream becomes unhealthy, and when it recovers. The returned
+// func stops watching. If `interval` isn't positive then the health isn't checked.
+func WatchHealth(interval time.Duration) (stop func()) {
+	return io.WatchHealth(currentStream{}, interval, func(err error) {
+		if err != nil {
+			diag.Logf("log stream is unhealthy: %v", err)
+		} else {
+			diag.Logf("log stream has recovered")
+		}
+	})
+}
@@ -0,0 +1,85 @@
+/*
+Copyright 2016 James DeFelice
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"os"
+	"os/signal"
+	"sync"
+
+	"github.com/gologs/log/diag"
+	"github.com/gologs/log/levels"
+)
+
+// HandleSignals adjusts the minimum log level of the current logging instance (see Apply)
+// upon receipt of signals: `more` lowers the level (more verbose), `less` raises it. Each signal
+// steps from the lowest level that the current instance has enabled, so that changes made by
+// other means are respected; `initial` is assumed if that can't be determined. Changes are
+// reported via diag. The returned func stops handling signals but leaves the level as-is. See
+// also HandleUserSignals.
+func HandleSignals(initial levels.Level, more, less os.Signal) (stop func()) {
+	var (
+		ch    = make(chan os.Signal, 1)
+		done  = make(chan struct{})
+		once  sync.Once
+		order = levels.All()
+	)
+	current := func() int {
+		if logs := Logging(); logs != nil {
+			if _, ok := logs.(levels.Enabler); ok {
+				for i, lvl := range order {
+					if levels.Enabled(logs, lvl) {
+						return i
+					}
+				}
+			}
+		}
+		for i, lvl := range order {
+			if lvl == initial {
+				return i
+			}
+		}
+		return 0
+	}
+	signal.Notify(ch, more, less)
+	go func() {
+		for {
+			select {
+			case <-done:
+				return
+			case sig := <-ch:
+				i := current()
+				switch {
+				case sig == more && i > 0:
+					i--
+				case sig == less && i < len(order)-1:
+					i++
+				default:
+					continue
+				}
+				Apply(Level(order[i]))
+				diag.Logf("log level set to %v", order[i])
+			}
+		}
+	}()
+	return func() {
+		once.Do(func() {
+			signal.Stop(ch)
+			close(done)
+		})
+	}
+}
@@ -0,0 +1,31 @@
+//go:build unix
+
+/*
+Copyright 2016 James DeFelice
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"syscall"
+
+	"github.com/gologs/log/levels"
+)
+
+// HandleUserSignals follows the convention for long-running daemons: SIGUSR1 increases the
+// verbosity of logging, SIGUSR2 decreases it. See HandleSignals.
+func HandleUserSignals(initial levels.Level) (stop func()) {
+	return HandleSignals(initial, syscall.SIGUSR1, syscall.SIGUSR2)
+}
@@ -0,0 +1,64 @@
+//go:build unix
+
+/*
+Copyright 2016 James DeFelice
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config_test
+
+import (
+	"syscall"
+	"testing"
+	"time"
+
+	. "github.com/gologs/log/config"
+	"github.com/gologs/log/diag"
+	"github.com/gologs/log/levels"
+	"github.com/gologs/log/logger"
+)
+
+func TestHandleUserSignals(t *testing.T) {
+	defer Scoped(Level(levels.Info))()
+	defer diag.SetLogger(diag.SetLogger(logger.Null()))
+
+	stop := HandleUserSignals(levels.Info)
+	defer stop()
+
+	waitFor := func(expected bool) {
+		for i := 0; levels.Enabled(Logging(), levels.Debug) != expected; i++ {
+			if i > 1000 {
+				t.Fatalf("expected debug enabled == %v", expected)
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}
+	syscall.Kill(syscall.Getpid(), syscall.SIGUSR1)
+	waitFor(true)
+	syscall.Kill(syscall.Getpid(), syscall.SIGUSR2)
+	waitFor(false)
+
+	// signals step from the current level, which may have been changed by other means
+	Apply(Level(levels.Error))
+	syscall.Kill(syscall.Getpid(), syscall.SIGUSR1)
+	for i := 0; !levels.Enabled(Logging(), levels.Warn); i++ {
+		if i > 1000 {
+			t.Fatal("expected warn to be enabled")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if levels.Enabled(Logging(), levels.Info) {
+		t.Fatal("expected info to remain disabled")
+	}
+}
@@ -0,0 +1,76 @@
+/*
+Copyright 2016 James DeFelice
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// MaxCallerDepth is the largest plausible call depth, see caller.Tracking.
+const MaxCallerDepth = 64
+
+// ValidationError lists the problems found by Config.Validate.
+type ValidationError []string
+
+// Error implements error
+func (e ValidationError) Error() string {
+	return "invalid logging config: " + strings.Join(e, "; ")
+}
+
+// Validate returns a ValidationError if the config is contradictory, for example if both a
+// Stream and a Logger are configured as the sink (in which case the Logger is ignored) or if
+// Stream-specific settings are configured for a Logger sink. Build fails for a config that
+// doesn't Validate, whereas With never does.
+//
+// Fields that are unset are not errors because they select defaults; in particular a nil Clock
+// selects time.Now. Rotation isn't a Config setting (see io.OpenRotatingFile), so Fluent.Build
+// is what reports a Rotate without a File.
+func (cfg Config) Validate() error {
+	var errs ValidationError
+	if cfg.Sink.Stream != nil && cfg.Sink.Logger != nil {
+		errs = append(errs, "both a Stream and a Logger sink are configured, the Logger is ignored")
+	}
+	if cfg.Sink.Open != nil && (cfg.Sink.Stream != nil || cfg.Sink.Logger != nil) {
+		errs = append(errs, "both Open and a Stream or Logger sink are configured, the latter is ignored")
+	}
+	if cfg.Sink.Stream == nil && cfg.Sink.Open == nil {
+		for name, set := range map[string]bool{
+			"Decorators": len(cfg.Sink.Decorators) > 0,
+			"Marshaler":  cfg.Sink.Marshaler != nil,
+			"Builder":    cfg.Sink.Builder != nil,
+			"Errors":     cfg.Sink.Errors != nil,
+			"NoRecover":  cfg.Sink.NoRecover,
+		} {
+			if set {
+				errs = append(errs, fmt.Sprintf("Sink.%s is configured but only applies to a Stream sink", name))
+			}
+		}
+	}
+	if t := cfg.CallTracking; t.Enabled && (t.Depth < 0 || t.Depth > MaxCallerDepth) {
+		errs = append(errs, fmt.Sprintf("implausible caller depth %d", t.Depth))
+	}
+	if cfg.ExitCode < 0 || cfg.ExitCode > 255 {
+		errs = append(errs, fmt.Sprintf("exit code %d is out of range", cfg.ExitCode))
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	sort.Strings(errs) // map iteration order is random
+	return errs
+}
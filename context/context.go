@@ -14,14 +14,26 @@ See the License for the specific language governing permissions and
 limitations under the License.
 */
 
-// Package context is a strict subset of the interfaces declared in the canonical
-// golang.org/x/net/context package.
+// Package context declares a Context interface that mirrors that of the standard library's
+// context package (and golang.org/x/net/context), so that any such Context may be used here.
+// Logging honors the cancellation of a Context where it would otherwise block: for example
+// upon the writes of network sinks (see logger.WithTimeout), the sends of error sinks (see
+// logger.ErrorChan), and the enqueueing of priority log events by async Loggers.
 package context
 
-// Context is a subset of the golang.org/x/net/context Context interface
+import (
+	"time"
+)
+
+// Context mirrors the golang.org/x/net/context Context interface, such that any such
+// Context (including those generated by the standard library) may be used here.
 type Context interface {
+	// Deadline returns the time at which the calling context will be canceled, if any
+	Deadline() (deadline time.Time, ok bool)
 	// Done returns a chan that closes to indicate termination of the calling context
 	Done() <-chan struct{}
+	// Err returns a non-nil error once Done has closed
+	Err() error
 	// Value returns the value for the registered key, or else nil
 	Value(key interface{}) interface{}
 }
@@ -31,7 +43,9 @@ type Getter func() Context
 
 type nullContext int
 
+func (c nullContext) Deadline() (_ time.Time, _ bool) { return }
 func (c nullContext) Done() <-chan struct{}           { return nil }
+func (c nullContext) Err() error                      { return nil }
 func (c nullContext) Value(_ interface{}) interface{} { return nil }
 
 // TODO exists to identify a place where better context is needed, but will be added later.
@@ -54,9 +68,23 @@ func (c *stateful) Value(key interface{}) interface{} {
 	return c.Context.Value(key)
 }
 
+// Link implements Link
+func (c *stateful) Link() (Context, interface{}, interface{}) { return c.Context, c.key, c.value }
+
+// Link is implemented by Contexts that, like those generated by WithValue, associate a single
+// key with a value on top of a parent Context. Flatten walks through Links to collect their
+// key/value pairs; Contexts that aren't Links end the walk.
+type Link interface {
+	Context
+	Link() (parent Context, key, value interface{})
+}
+
 // WithValue returns a Context that associates value with key. Should not modify the
-// original Context, `c`.
+// original Context, `c`; a nil `c` is treated as Background.
 func WithValue(c Context, key, value interface{}) Context {
+	if c == nil {
+		c = Background()
+	}
 	return &stateful{c, key, value}
 }
 
@@ -17,7 +17,10 @@ limitations under the License.
 package context_test
 
 import (
+	stdcontext "context"
+	"reflect"
 	"testing"
+	"time"
 
 	. "github.com/gologs/log/context"
 )
@@ -78,3 +81,221 @@ func Test_WithValue2(t *testing.T) {
 		t.Fatalf("unexpected value for jim: %q", jim)
 	}
 }
+
+func Test_Deadline(t *testing.T) {
+	ctx := Background()
+	if _, ok := ctx.Deadline(); ok {
+		t.Fatal("background context should not have a deadline")
+	}
+	if err := ctx.Err(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	deadline := time.Now().Add(time.Hour)
+	std, cancel := stdcontext.WithDeadline(stdcontext.Background(), deadline)
+	ctx = WithValue(std, "foo", "bar")
+	if d, ok := ctx.Deadline(); !ok || !d.Equal(deadline) {
+		t.Fatalf("expected deadline %v instead of %v", deadline, d)
+	}
+
+	cancel()
+	<-ctx.Done()
+	if err := ctx.Err(); err != stdcontext.Canceled {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestMemoize(t *testing.T) {
+	var (
+		calls int
+		base  = &countingContext{Context: Background(), calls: &calls}
+		ctx   = Memoize(WithValue(base, "a", 1))
+	)
+	for i := 0; i < 3; i++ {
+		if v := ctx.Value("a"); v != 1 {
+			t.Fatalf("expected 1 instead of %v", v)
+		}
+		if v := ctx.Value("b"); v != nil {
+			t.Fatalf("expected nil instead of %v", v)
+		}
+	}
+	if calls != 1 {
+		t.Fatalf("expected a single lookup of the base context instead of %d", calls)
+	}
+	if Memoize(ctx) != ctx {
+		t.Fatalf("expected memoized context to be returned as-is")
+	}
+}
+
+type countingContext struct {
+	Context
+	calls *int
+}
+
+func (c *countingContext) Value(key interface{}) interface{} {
+	*c.calls++
+	return c.Context.Value(key)
+}
+
+// deepContext generates a chain of contexts similar to that of a log event with several
+// decorators: timestamp, request ID, fields, caller, level, and so on.
+func deepContext() Context {
+	ctx := Background()
+	for i := 0; i < 8; i++ {
+		ctx = WithValue(ctx, i, i)
+	}
+	return ctx
+}
+
+func benchmarkLookups(b *testing.B, wrap func(Context) Context) {
+	ctx := deepContext()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		c := wrap(ctx)
+		for j := 0; j < 4; j++ {
+			// the keys that are added first are the deepest in the chain
+			_ = c.Value(0)
+			_ = c.Value(1)
+			_ = c.Value("missing")
+		}
+	}
+}
+
+func BenchmarkValue_Linked(b *testing.B) {
+	benchmarkLookups(b, func(c Context) Context { return c })
+}
+
+func BenchmarkValue_Memoized(b *testing.B) {
+	benchmarkLookups(b, Memoize)
+}
+
+func BenchmarkValue_Flattened(b *testing.B) {
+	benchmarkLookups(b, Flatten)
+}
+
+func TestFlatten(t *testing.T) {
+	var (
+		calls int
+		base  = &countingContext{Context: Background(), calls: &calls}
+		ctx   = base
+	)
+	var c Context = ctx
+	for i := 0; i < 10; i++ {
+		c = WithValue(c, i%6, i) // shadows earlier values of the same key
+	}
+	flat := Flatten(WithValue(Flatten(c), "x", "y"))
+	for i := 0; i < 6; i++ {
+		expected := i
+		if i < 4 {
+			expected = i + 6
+		}
+		if v := flat.Value(i); v != expected {
+			t.Fatalf("expected %v for key %d instead of %v", expected, i, v)
+		}
+	}
+	if v := flat.Value("x"); v != "y" {
+		t.Fatalf("expected y instead of %v", v)
+	}
+	if calls != 0 {
+		t.Fatalf("unexpected lookups of the base context: %d", calls)
+	}
+	if v := flat.Value("missing"); v != nil || calls != 1 {
+		t.Fatalf("expected a lookup of the base context, got %v after %d calls", v, calls)
+	}
+}
+
+// link is a Link that's implemented outside of package context, like that of package timestamp
+type link struct {
+	Context
+	key, value interface{}
+}
+
+func (c *link) Value(key interface{}) interface{} {
+	if key == c.key {
+		return c.value
+	}
+	return c.Context.Value(key)
+}
+
+func (c *link) Link() (Context, interface{}, interface{}) { return c.Context, c.key, c.value }
+
+func TestFlatten_Links(t *testing.T) {
+	var (
+		calls int
+		c     Context = &countingContext{Context: Background(), calls: &calls}
+	)
+	c = WithValue(c, "a", 1)
+	if flat := Flatten(c); flat != c {
+		t.Fatalf("expected a short chain to be returned as-is")
+	}
+	c = &link{WithValue(c, "b", 2), "c", 3}
+	c = Memoize(WithValue(WithValue(c, "d", 4), "e", 5))
+
+	// the walk continues past other implementations of Link, and the memoized context
+	flat := Flatten(c)
+	for i, key := range []string{"a", "b", "c", "d", "e"} {
+		if v := flat.Value(key); v != i+1 {
+			t.Fatalf("expected %d for key %q instead of %v", i+1, key, v)
+		}
+	}
+	if calls != 0 {
+		t.Fatalf("unexpected lookups of the base context: %d", calls)
+	}
+}
+
+func BenchmarkValue_PreFlattened(b *testing.B) {
+	flat := Flatten(deepContext())
+	benchmarkLookups(b, func(Context) Context { return flat })
+}
+
+func TestRegisterField(t *testing.T) {
+	type key int
+	var (
+		get = func(k key) Accessor {
+			return func(c Context) (interface{}, bool) {
+				v := c.Value(k)
+				return v, v != nil
+			}
+		}
+		c = WithValue(WithValue(Background(), key(1), "one"), key(2), "two")
+	)
+	RegisterField("test.b", get(2))
+	RegisterField("test.a", get(1))
+	RegisterField("test.c", get(3))
+	defer func() {
+		for _, name := range []string{"test.a", "test.b", "test.c"} {
+			RegisterField(name, nil)
+		}
+		if names := RegisteredFields(); len(names) != 0 {
+			t.Errorf("unexpected registered fields %q", names)
+		}
+	}()
+	if names := RegisteredFields(); !reflect.DeepEqual(names, []string{"test.a", "test.b", "test.c"}) {
+		t.Fatalf("unexpected registered fields %q", names)
+	}
+	expected := []Annotation{{"test.a", "one"}, {"test.b", "two"}}
+	if got := Annotations(c); !reflect.DeepEqual(got, expected) {
+		t.Fatalf("expected %v instead of %v", expected, got)
+	}
+}
+
+func TestNilContext(t *testing.T) {
+	var nilctx Context
+	for _, c := range []Context{
+		WithValue(nilctx, "foo", "bar"),
+		NewDecorator("foo", "bar")(nilctx),
+		Flatten(WithValue(nilctx, "foo", "bar")),
+		Flatten(nilctx),
+		Memoize(WithValue(nilctx, "foo", "bar")),
+	} {
+		if c == nil {
+			t.Fatalf("unexpected nil context")
+		}
+		_ = c.Value("baz")
+		_ = c.Done()
+	}
+	if x := Annotations(nilctx); len(x) != 0 {
+		t.Fatalf("unexpected annotations %v", x)
+	}
+}
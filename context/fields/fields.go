@@ -0,0 +1,206 @@
+/*
+Copyright 2016 James DeFelice
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package fields attaches structured key/value metadata to the context of log events, so that
+// encoders may render it losslessly instead of flattening it into the log message.
+package fields
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/gologs/log/context"
+)
+
+type key int
+
+const (
+	fieldsKey key = iota
+)
+
+// Field is a key/value pair.
+type Field struct {
+	Key   string
+	Value interface{}
+}
+
+// F is a convenience func that returns a Field.
+func F(key string, value interface{}) Field { return Field{key, value} }
+
+// BadKey is the key of a value that lacks one, see KV.
+const BadKey = "!BADKEY"
+
+// KV converts alternating keys and values into fields, for example KV("user", "bob", "n", 2).
+// Field elements are taken as-is, in place of a key/value pair; keys that aren't strings are
+// rendered as per fmt.Sprint, and a final key without a value becomes the value of BadKey.
+func KV(keysAndValues ...interface{}) Fields {
+	ff := make(Fields, 0, (len(keysAndValues)+1)/2)
+	for i := 0; i < len(keysAndValues); i++ {
+		if f, ok := keysAndValues[i].(Field); ok {
+			ff = append(ff, f)
+			continue
+		}
+		if i+1 == len(keysAndValues) {
+			ff = append(ff, Field{BadKey, keysAndValues[i]})
+			break
+		}
+		key, ok := keysAndValues[i].(string)
+		if !ok {
+			key = fmt.Sprint(keysAndValues[i])
+		}
+		ff = append(ff, Field{key, keysAndValues[i+1]})
+		i++
+	}
+	return ff
+}
+
+// Fields is an ordered collection of Field.
+type Fields []Field
+
+// Get returns the value of the last Field with the given key.
+func (ff Fields) Get(key string) (interface{}, bool) {
+	for i := len(ff) - 1; i >= 0; i-- {
+		if ff[i].Key == key {
+			return ff[i].Value, true
+		}
+	}
+	return nil, false
+}
+
+// FromContext returns the fields found in the provided context, in the order in which they
+// were added; the returned slice must not be modified.
+func FromContext(ctx context.Context) Fields {
+	if ctx == nil {
+		return nil
+	}
+	ff, _ := ctx.Value(fieldsKey).(Fields)
+	return ff
+}
+
+// NewContext returns a Context that contains the fields of the provided context, followed by
+// the given fields.
+func NewContext(ctx context.Context, f ...Field) context.Context {
+	if len(f) == 0 {
+		return ctx
+	}
+	var (
+		parent = FromContext(ctx)
+		ff     = make(Fields, 0, len(parent)+len(f))
+	)
+	ff = append(append(ff, parent...), f...)
+	return context.WithValue(ctx, fieldsKey, ff)
+}
+
+// NewDecorator returns a context Decorator that adds the given fields to a context.
+func NewDecorator(f ...Field) context.Decorator {
+	return func(ctx context.Context) context.Context {
+		return NewContext(ctx, f...)
+	}
+}
+
+// Policy determines how fields with duplicate keys are resolved, see Fields.Dedupe.
+type Policy int
+
+// Policy values
+const (
+	// LastWins retains only the last of the fields that share a key, at the position of the
+	// last such field.
+	LastWins Policy = iota
+	// SuffixDuplicates is like LastWins except that earlier fields that share a key are retained
+	// (at their original positions) and renamed, in order, to key_dup, key_dup2, key_dup3, ...
+	// skipping any such key that's already in use.
+	SuffixDuplicates
+	// KeepDuplicates retains all fields, as-is.
+	KeepDuplicates
+	// Strict reports a DuplicateKeyError upon encountering duplicate keys; it's intended for use
+	// by tests.
+	Strict
+)
+
+// DuplicateKeyError is reported by Dedupe, per the Strict policy.
+type DuplicateKeyError struct {
+	Key string
+}
+
+// Error implements error
+func (e *DuplicateKeyError) Error() string {
+	return "fields: duplicate key " + strconv.Quote(e.Key)
+}
+
+// Dedupe resolves duplicate keys according to the given policy. If there are no duplicates
+// then the receiver is returned, otherwise a new slice. An error is returned only by the Strict
+// policy.
+func (ff Fields) Dedupe(p Policy) (Fields, error) {
+	if p == KeepDuplicates || !ff.hasDuplicates() {
+		return ff, nil
+	}
+	var (
+		last   = make(map[string]int, len(ff)) // index of the last field with a given key
+		seen   map[string]int                  // number of suffixes tried for a given key
+		result = make(Fields, 0, len(ff))
+	)
+	for i, f := range ff {
+		last[f.Key] = i
+	}
+	if p == SuffixDuplicates {
+		seen = make(map[string]int)
+	}
+	for i, f := range ff {
+		if last[f.Key] == i {
+			result = append(result, f)
+			continue
+		}
+		switch p {
+		case Strict:
+			return nil, &DuplicateKeyError{f.Key}
+		case SuffixDuplicates:
+			for n := seen[f.Key] + 1; ; n++ {
+				key := f.Key + "_dup"
+				if n > 1 {
+					key += strconv.Itoa(n)
+				}
+				if _, taken := last[key]; !taken {
+					seen[f.Key] = n
+					last[key] = -1 // reserve it
+					result = append(result, Field{key, f.Value})
+					break
+				}
+			}
+		}
+	}
+	return result, nil
+}
+
+func (ff Fields) hasDuplicates() bool {
+	if len(ff) <= 16 {
+		for i := 1; i < len(ff); i++ {
+			for j := 0; j < i; j++ {
+				if ff[i].Key == ff[j].Key {
+					return true
+				}
+			}
+		}
+		return false
+	}
+	keys := make(map[string]struct{}, len(ff))
+	for _, f := range ff {
+		if _, ok := keys[f.Key]; ok {
+			return true
+		}
+		keys[f.Key] = struct{}{}
+	}
+	return false
+}
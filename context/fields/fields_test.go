@@ -0,0 +1,72 @@
+/*
+Copyright 2016 James DeFelice
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fields_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/gologs/log/context"
+	. "github.com/gologs/log/context/fields"
+)
+
+func TestNewContext(t *testing.T) {
+	var (
+		parent = NewContext(context.Background(), F("a", 1))
+		child  = NewDecorator(F("b", 2), F("a", 3))(parent)
+	)
+	if ff := FromContext(parent); !reflect.DeepEqual(ff, Fields{F("a", 1)}) {
+		t.Fatalf("unexpected parent fields %v", ff)
+	}
+	ff := FromContext(child)
+	if !reflect.DeepEqual(ff, Fields{F("a", 1), F("b", 2), F("a", 3)}) {
+		t.Fatalf("unexpected child fields %v", ff)
+	}
+	if v, ok := ff.Get("a"); !ok || v != 3 {
+		t.Fatalf("expected last value of a instead of %v", v)
+	}
+}
+
+func TestDedupe(t *testing.T) {
+	ff := Fields{F("a", 1), F("b", 2), F("a", 3), F("c", 4), F("a", 5)}
+	for i, tc := range []struct {
+		p        Policy
+		expected Fields
+	}{
+		{LastWins, Fields{F("b", 2), F("c", 4), F("a", 5)}},
+		{SuffixDuplicates, Fields{F("a_dup", 1), F("b", 2), F("a_dup2", 3), F("c", 4), F("a", 5)}},
+		{KeepDuplicates, ff},
+	} {
+		if got, err := ff.Dedupe(tc.p); err != nil || !reflect.DeepEqual(got, tc.expected) {
+			t.Errorf("test case %d: expected %v instead of %v: %v", i, tc.expected, got, err)
+		}
+	}
+
+	taken := Fields{F("a", 1), F("a_dup", 2), F("a", 3), F("a", 4)}
+	expected := Fields{F("a_dup2", 1), F("a_dup", 2), F("a_dup3", 3), F("a", 4)}
+	if got, _ := taken.Dedupe(SuffixDuplicates); !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %v instead of %v", expected, got)
+	}
+
+	unique := Fields{F("a", 1), F("b", 2)}
+	if got, err := unique.Dedupe(Strict); err != nil || &got[0] != &unique[0] {
+		t.Errorf("expected fields without duplicates to be returned as-is")
+	}
+	if _, err := ff.Dedupe(Strict); err == nil || err.(*DuplicateKeyError).Key != "a" {
+		t.Errorf("expected Strict to report the duplicate key instead of %v", err)
+	}
+}
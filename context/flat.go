@@ -0,0 +1,133 @@
+/*
+Copyright 2016 James DeFelice
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package context
+
+const (
+	// flatSize is the number of key/value pairs that a flattened Context stores inline
+	flatSize = 8
+	// flatMin is the number of Links below which Flatten doesn't bother: walking a short chain
+	// costs less than the allocation of a flattened Context
+	flatMin = 5
+)
+
+type pair struct{ key, value interface{} }
+
+// flatContext stores the key/value pairs of a chain of contexts in an array, innermost first
+type flatContext struct {
+	Context // Context is the first ancestor that couldn't be flattened
+	inline  [flatSize]pair
+	more    []pair
+	n       int
+}
+
+// Flatten returns a Context that answers Value lookups for the key/value pairs of the Links
+// (for example, those generated by WithValue and NewDecorator) from which `c` was derived by
+// scanning an array, instead of walking the linked chain. Lookups of other keys are delegated
+// to the nearest ancestor Context that isn't a Link. Flattening costs an allocation and a walk of
+// the chain, so chains of fewer than a handful of Links are returned as-is. The logging pipeline
+// flattens the context of each log event before it's handed to the sink (see config.With); unlike
+// Memoize, which remembers recent lookups of any Context, flattening speeds up the first lookup
+// of each key as well.
+func Flatten(c Context) Context {
+	if c == nil {
+		return Background()
+	}
+	if _, ok := c.(*flatContext); ok || links(c, flatMin) < flatMin {
+		return c
+	}
+	f := &flatContext{}
+	for {
+		switch x := c.(type) {
+		case *flatContext:
+			for i := 0; i < x.n; i++ {
+				f.add(x.at(i).key, x.at(i).value)
+			}
+			c = x.Context
+			continue
+		case *memoContext:
+			c = x.Context
+			continue
+		case Link:
+			var key, value interface{}
+			c, key, value = x.Link()
+			f.add(key, value)
+			continue
+		}
+		break
+	}
+	if c == nil {
+		c = Background()
+	}
+	f.Context = c
+	return f
+}
+
+// links returns the number of Links (counting those of a flattened Context) from which `c` was
+// derived, up to max
+func links(c Context, max int) (n int) {
+	for n < max {
+		switch x := c.(type) {
+		case *flatContext:
+			return n + x.n
+		case *memoContext:
+			c = x.Context
+		case Link:
+			c, _, _ = x.Link()
+			n++
+		default:
+			return n
+		}
+	}
+	return n
+}
+
+func (c *flatContext) add(key, value interface{}) {
+	if c.n < flatSize {
+		c.inline[c.n] = pair{key, value}
+	} else {
+		c.more = append(c.more, pair{key, value})
+	}
+	c.n++
+}
+
+func (c *flatContext) at(i int) *pair {
+	if i < flatSize {
+		return &c.inline[i]
+	}
+	return &c.more[i-flatSize]
+}
+
+func (c *flatContext) Value(key interface{}) interface{} {
+	n := c.n
+	if n > flatSize {
+		n = flatSize
+	}
+	for i := 0; i < n; i++ {
+		if c.inline[i].key == key {
+			return c.inline[i].value
+		}
+	}
+	for i := range c.more {
+		if c.more[i].key == key {
+			return c.more[i].value
+		}
+	}
+	if c.Context == nil {
+		return nil
+	}
+	return c.Context.Value(key)
+}
@@ -0,0 +1,57 @@
+/*
+Copyright 2016 James DeFelice
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package context
+
+// memoSize is the number of Value lookups remembered by a memoized Context
+const memoSize = 8
+
+// memoContext remembers the results of recent Value lookups
+type memoContext struct {
+	Context
+	keys [memoSize]interface{}
+	vals [memoSize]interface{}
+	n    int // n is the number of lookups, used to select the next slot
+}
+
+// Memoize returns a Context that remembers the results (including misses) of the most recent
+// Value lookups of distinct keys, so that repeated lookups needn't walk the chain of Contexts
+// from which `c` was derived. This is useful when many decorators and encoders consult the
+// context of a single log event. The returned Context is not safe for concurrent use, and
+// should not be retained beyond the processing of the log event.
+func Memoize(c Context) Context {
+	if _, ok := c.(*memoContext); ok || c == nil {
+		return c
+	}
+	return &memoContext{Context: c}
+}
+
+func (c *memoContext) Value(key interface{}) interface{} {
+	used := c.n
+	if used > memoSize {
+		used = memoSize
+	}
+	for i := 0; i < used; i++ {
+		if c.keys[i] == key {
+			return c.vals[i]
+		}
+	}
+	v := c.Context.Value(key)
+	i := c.n % memoSize
+	c.keys[i], c.vals[i] = key, v
+	c.n++
+	return v
+}
@@ -0,0 +1,92 @@
+/*
+Copyright 2016 James DeFelice
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package context
+
+import (
+	"sort"
+	"sync"
+)
+
+// Accessor extracts a well-known value from a Context, typically by wrapping the FromContext
+// func of the package that defines the value.
+type Accessor func(Context) (interface{}, bool)
+
+// Annotation is a named value extracted from a Context by a registered Accessor.
+type Annotation struct {
+	Name  string
+	Value interface{}
+}
+
+type accessor struct {
+	name string
+	f    Accessor
+}
+
+var accessors = struct {
+	sync.RWMutex
+	list []accessor // list is sorted by name
+}{}
+
+// RegisterField associates an Accessor with a name, so that encoders may enumerate the
+// annotations of a Context (see Annotations) without importing the packages that define them.
+// Registering a name again replaces the prior Accessor; a nil Accessor removes it. For example:
+//
+//	context.RegisterField("level", func(c context.Context) (interface{}, bool) {
+//		return levels.FromContext(c)
+//	})
+func RegisterField(name string, f Accessor) {
+	accessors.Lock()
+	defer accessors.Unlock()
+	list := accessors.list
+	i := sort.Search(len(list), func(i int) bool { return list[i].name >= name })
+	found := i < len(list) && list[i].name == name
+	switch {
+	case f == nil && found:
+		list = append(list[:i:i], list[i+1:]...)
+	case f == nil:
+	case found:
+		list = append(list[:i:i], list[i:]...)
+		list[i].f = f
+	default:
+		list = append(list[:i:i], append([]accessor{{name, f}}, list[i:]...)...)
+	}
+	accessors.list = list // copy on write: Annotations may be iterating over the prior list
+}
+
+// RegisteredFields returns the sorted names of all registered Accessors.
+func RegisteredFields() (names []string) {
+	accessors.RLock()
+	defer accessors.RUnlock()
+	for _, a := range accessors.list {
+		names = append(names, a.name)
+	}
+	return
+}
+
+// Annotations returns the values of all the registered Accessors that are present in the
+// given Context, sorted by name.
+func Annotations(c Context) (result []Annotation) {
+	accessors.RLock()
+	list := accessors.list
+	accessors.RUnlock()
+	for _, a := range list {
+		if v, ok := a.f(c); ok {
+			result = append(result, Annotation{a.name, v})
+		}
+	}
+	return
+}
@@ -0,0 +1,127 @@
+/*
+Copyright 2016 James DeFelice
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package requestid generates and propagates request identifiers for the purpose of
+// correlating log events.
+package requestid
+
+import (
+	stdcontext "context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/gologs/log/context"
+)
+
+type key int
+
+const (
+	idKey key = iota
+)
+
+// Header is the HTTP header that carries a request ID.
+const Header = "X-Request-Id"
+
+func init() {
+	context.RegisterField("request_id", func(c context.Context) (interface{}, bool) { return FromContext(c) })
+}
+
+// FromContext extracts a request ID from the provided context. Contexts generated by
+// Middleware for http.Request objects are also supported.
+func FromContext(ctx context.Context) (id string, ok bool) {
+	if ctx == nil {
+		return
+	}
+	id, ok = ctx.Value(idKey).(string)
+	return
+}
+
+// NewContext returns a Context that contains the provided request ID.
+func NewContext(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, idKey, id)
+}
+
+// NewDecorator returns a context Decorator that annotates a context with the given request ID.
+func NewDecorator(id string) context.Decorator {
+	return context.NewDecorator(idKey, id)
+}
+
+// Ensure returns a context Decorator that generates a new request ID (see New) for a context
+// only if one is not already present.
+func Ensure() context.Decorator {
+	return func(ctx context.Context) context.Context {
+		if _, ok := FromContext(ctx); ok {
+			return ctx
+		}
+		return NewContext(ctx, New())
+	}
+}
+
+var fallback uint64
+
+// New generates a random (version 4) UUID.
+func New() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		// very unlikely; fall back to something that's at least unique within this process
+		return strconv.FormatInt(time.Now().UnixNano(), 36) + "-" +
+			strconv.FormatUint(atomic.AddUint64(&fallback, 1), 36)
+	}
+	b[6] = (b[6] & 0x0f) | 0x40 // version 4
+	b[8] = (b[8] & 0x3f) | 0x80 // variant 10
+	var s [36]byte
+	hex.Encode(s[0:8], b[0:4])
+	s[8] = '-'
+	hex.Encode(s[9:13], b[4:6])
+	s[13] = '-'
+	hex.Encode(s[14:18], b[6:8])
+	s[18] = '-'
+	hex.Encode(s[19:23], b[8:10])
+	s[23] = '-'
+	hex.Encode(s[24:], b[10:])
+	return string(s[:])
+}
+
+// FromRequest returns the request ID associated with the given request: either that stored by
+// Middleware, or else the value of the request's Header.
+func FromRequest(r *http.Request) (string, bool) {
+	if id, ok := r.Context().Value(idKey).(string); ok {
+		return id, true
+	}
+	if id := r.Header.Get(Header); id != "" {
+		return id, true
+	}
+	return "", false
+}
+
+// Middleware returns an http.Handler that ensures every request is associated with a request
+// ID: the ID is reused from the request's Header if present, otherwise it's generated by New.
+// The ID is stored in the request's context (see FromContext and FromRequest) and is echoed
+// back to the client via the response Header.
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id, ok := FromRequest(r)
+		if !ok {
+			id = New()
+		}
+		w.Header().Set(Header, id)
+		next.ServeHTTP(w, r.WithContext(stdcontext.WithValue(r.Context(), idKey, id)))
+	})
+}
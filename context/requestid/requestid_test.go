@@ -0,0 +1,73 @@
+/*
+Copyright 2016 James DeFelice
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package requestid_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+
+	"github.com/gologs/log/context"
+	. "github.com/gologs/log/context/requestid"
+)
+
+var uuidPattern = regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+
+func TestNew(t *testing.T) {
+	a, b := New(), New()
+	if !uuidPattern.MatchString(a) {
+		t.Fatalf("unexpected request ID format: %q", a)
+	}
+	if a == b {
+		t.Fatalf("expected unique request IDs")
+	}
+}
+
+func TestEnsure(t *testing.T) {
+	ctx := Ensure()(context.Background())
+	id, ok := FromContext(ctx)
+	if !ok || !uuidPattern.MatchString(id) {
+		t.Fatalf("expected generated request ID instead of %q", id)
+	}
+	ctx = Ensure()(NewContext(context.Background(), "abc"))
+	if id, _ = FromContext(ctx); id != "abc" {
+		t.Fatalf("expected existing request ID instead of %q", id)
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	var (
+		seen string
+		h    = Middleware(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+			seen, _ = FromContext(r.Context())
+		}))
+		w = httptest.NewRecorder()
+		r = httptest.NewRequest("GET", "/", nil)
+	)
+	h.ServeHTTP(w, r)
+	if !uuidPattern.MatchString(seen) || w.Header().Get(Header) != seen {
+		t.Fatalf("expected generated request ID instead of %q", seen)
+	}
+
+	w = httptest.NewRecorder()
+	r.Header.Set(Header, "abc")
+	h.ServeHTTP(w, r)
+	if seen != "abc" || w.Header().Get(Header) != "abc" {
+		t.Fatalf("expected propagated request ID instead of %q", seen)
+	}
+}
@@ -31,15 +31,49 @@ const (
 // Clock functions return the current time
 type Clock func() time.Time
 
+// tsContext avoids boxing the timestamp upon construction; most contexts are
+// generated far more frequently than timestamps are read from them.
+type tsContext struct {
+	context.Context
+	t time.Time
+}
+
+func (c *tsContext) Value(key interface{}) interface{} {
+	if key == tsKey {
+		return &c.t // a pointer doesn't need to be boxed
+	}
+	return c.Context.Value(key)
+}
+
+// Link implements context.Link
+func (c *tsContext) Link() (context.Context, interface{}, interface{}) {
+	return c.Context, tsKey, &c.t
+}
+
+func init() {
+	context.RegisterField("time", func(c context.Context) (interface{}, bool) { return FromContext(c) })
+}
+
 // FromContext extracts a timestamp from the provided context.
 func FromContext(ctx context.Context) (t time.Time, ok bool) {
-	t, ok = ctx.Value(tsKey).(time.Time)
+	if ctx == nil {
+		return
+	}
+	switch x := ctx.Value(tsKey).(type) {
+	case *time.Time:
+		t, ok = *x, true
+	case time.Time:
+		t, ok = x, true
+	}
 	return
 }
 
 // NewContext returns a Context that contains the provided timestamp.
 func NewContext(ctx context.Context, t time.Time) context.Context {
-	return context.WithValue(ctx, tsKey, t)
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return &tsContext{ctx, t}
 }
 
 // NewDecorator returns a context Decorator that generates a context with a clock-
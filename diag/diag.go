@@ -0,0 +1,80 @@
+/*
+Copyright 2016 James DeFelice
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package diag is the channel through which the logging subsystem reports its own problems
+// (failing sinks, config reload failures, dropped log events), independently of the sinks that
+// it's been configured with. Diagnostics are written to stderr by default.
+package diag
+
+import (
+	"log"
+	"os"
+	"sync"
+	"sync/atomic"
+
+	"github.com/gologs/log/context"
+	"github.com/gologs/log/logger"
+)
+
+type holder struct{ logs logger.Logger }
+
+var current atomic.Value // current holds a *holder
+
+// Stderr returns the default diagnostic Logger.
+func Stderr() logger.Logger {
+	l := log.New(os.Stderr, "gologs: ", log.LstdFlags)
+	return logger.Func(func(_ context.Context, m string, a ...interface{}) {
+		l.Printf(m, a...)
+	})
+}
+
+func init() {
+	current.Store(&holder{Stderr()})
+}
+
+// SetLogger changes the destination of diagnostics and returns the previous one. A nil Logger
+// discards diagnostics. The diagnostic Logger must not itself generate diagnostics.
+func SetLogger(logs logger.Logger) (old logger.Logger) {
+	if logs == nil {
+		logs = logger.Null()
+	}
+	return current.Swap(&holder{logs}).(*holder).logs
+}
+
+// Logf reports a diagnostic.
+func Logf(m string, a ...interface{}) {
+	current.Load().(*holder).logs.Logf(context.Background(), m, a...)
+}
+
+// ErrorSink returns a logger.ErrorSink that reports errors as diagnostics. Consecutive
+// occurrences of the same error message are reported only once, so that a persistently failing
+// sink doesn't flood the diagnostic channel.
+func ErrorSink() logger.ErrorSink {
+	var (
+		mu   sync.Mutex
+		last string
+	)
+	return logger.ErrorSinkFunc(func(_ context.Context, e logger.Entry, err error) {
+		msg := err.Error()
+		mu.Lock()
+		dup := msg == last
+		last = msg
+		mu.Unlock()
+		if !dup {
+			Logf("failed to log event %q: %v", e.Format, err)
+		}
+	})
+}
@@ -0,0 +1,54 @@
+/*
+Copyright 2016 James DeFelice
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package diag_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	. "github.com/gologs/log/diag"
+	"github.com/gologs/log/logger"
+	"github.com/gologs/log/logtest"
+)
+
+func TestErrorSink(t *testing.T) {
+	rec := logtest.NewRecorder()
+	defer SetLogger(SetLogger(rec))
+
+	var (
+		es  = ErrorSink()
+		foo = errors.New("foo")
+	)
+	es.LogError(nil, logger.Entry{Format: "a"}, foo)
+	es.LogError(nil, logger.Entry{Format: "b"}, foo) // duplicate
+	es.LogError(nil, logger.Entry{Format: "c"}, errors.New("bar"))
+	es.LogError(nil, logger.Entry{Format: "d"}, foo)
+
+	var got []string
+	for _, e := range rec.Entries() {
+		got = append(got, e.Message)
+	}
+	expected := []string{
+		`failed to log event "a": foo`,
+		`failed to log event "c": bar`,
+		`failed to log event "d": foo`,
+	}
+	if !reflect.DeepEqual(expected, got) {
+		t.Fatalf("expected %q instead of %q", expected, got)
+	}
+}
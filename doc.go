@@ -19,4 +19,10 @@ limitations under the License.
 //
 //     log.Info("hello world")
 //
+// Funcs with an "f" suffix, such as Infof, format their args according to a message format, as
+// per fmt.Sprintf; the others, such as Info, render their args as per fmt.Sprint and never
+// interpret them as a format. To have "go vet" check the formats of log calls, run it as:
+//
+//     go vet -printf.funcs=Debugf,Infof,Warnf,Errorf,Fatalf,Panicf,Logf ./...
+//
 package log
@@ -0,0 +1,230 @@
+/*
+Copyright 2016 James DeFelice
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package encoding
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/gologs/log/context"
+	"github.com/gologs/log/io"
+)
+
+// segment is a fragment of a parsed format string: literal text followed by (at most) one verb.
+type segment struct {
+	lit    string // literal text that precedes the verb, "%%" has already been unescaped
+	verb   byte   // zero for a trailing literal
+	spec   string // the complete verb specification, for example "%-5d"
+	simple bool   // true if spec has no flags, width, or precision
+}
+
+// parsedFormat is the cached representation of a format string.
+type parsedFormat struct {
+	segs   []segment
+	nverbs int
+	ok     bool // false if the format uses features that we don't bother to parse
+}
+
+// FormatCache caches parsed format strings. It is safe for concurrent use.
+type FormatCache struct {
+	// MaxEntries limits the number of cached format strings; zero means DefaultFormatCacheSize.
+	MaxEntries int
+
+	mu      sync.RWMutex
+	entries map[string]*parsedFormat
+}
+
+// DefaultFormatCacheSize is the default limit for the number of cached format strings.
+const DefaultFormatCacheSize = 1024
+
+func (fc *FormatCache) lookup(m string) *parsedFormat {
+	fc.mu.RLock()
+	p, ok := fc.entries[m]
+	fc.mu.RUnlock()
+	if ok {
+		return p
+	}
+	p = parseFormat(m)
+
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+	max := fc.MaxEntries
+	if max <= 0 {
+		max = DefaultFormatCacheSize
+	}
+	if fc.entries == nil {
+		fc.entries = make(map[string]*parsedFormat)
+	}
+	if len(fc.entries) < max {
+		fc.entries[m] = p
+	}
+	return p
+}
+
+func parseFormat(m string) *parsedFormat {
+	var (
+		p   = &parsedFormat{ok: true}
+		lit []byte
+	)
+	for i := 0; i < len(m); {
+		c := m[i]
+		if c != '%' {
+			lit = append(lit, c)
+			i++
+			continue
+		}
+		if i+1 < len(m) && m[i+1] == '%' {
+			lit = append(lit, '%')
+			i += 2
+			continue
+		}
+		start := i
+		i++
+		for i < len(m) && strings.IndexByte("+-# 0", m[i]) >= 0 {
+			i++
+		}
+		for i < len(m) && (m[i] == '.' || (m[i] >= '0' && m[i] <= '9')) {
+			i++
+		}
+		if i >= len(m) || m[i] == '*' || m[i] == '[' || m[i] >= utf8.RuneSelf {
+			// missing verbs, argument indexes, and the like are left to fmt
+			return &parsedFormat{}
+		}
+		p.segs = append(p.segs, segment{
+			lit:    string(lit),
+			verb:   m[i],
+			spec:   m[start : i+1],
+			simple: i == start+1,
+		})
+		p.nverbs++
+		lit = lit[:0]
+		i++
+	}
+	if len(lit) > 0 {
+		p.segs = append(p.segs, segment{lit: string(lit)})
+	}
+	return p
+}
+
+type formatBuffer struct {
+	bytes.Buffer
+	scratch [24]byte
+}
+
+var formatBuffers = sync.Pool{New: func() interface{} { return new(formatBuffer) }}
+
+// appendArg attempts to render the given arg without resorting to fmt; returns false if it
+// cannot.
+func (b *formatBuffer) appendArg(verb byte, arg interface{}) bool {
+	switch verb {
+	case 's', 'v':
+		switch x := arg.(type) {
+		case string:
+			b.WriteString(x)
+			return true
+		case []byte:
+			if verb == 's' {
+				b.Write(x)
+				return true
+			}
+		}
+	}
+	switch verb {
+	case 'd', 'v':
+		switch x := arg.(type) {
+		case int:
+			b.Write(strconv.AppendInt(b.scratch[:0], int64(x), 10))
+		case int8:
+			b.Write(strconv.AppendInt(b.scratch[:0], int64(x), 10))
+		case int16:
+			b.Write(strconv.AppendInt(b.scratch[:0], int64(x), 10))
+		case int32:
+			b.Write(strconv.AppendInt(b.scratch[:0], int64(x), 10))
+		case int64:
+			b.Write(strconv.AppendInt(b.scratch[:0], x, 10))
+		case uint:
+			b.Write(strconv.AppendUint(b.scratch[:0], uint64(x), 10))
+		case uint8:
+			b.Write(strconv.AppendUint(b.scratch[:0], uint64(x), 10))
+		case uint16:
+			b.Write(strconv.AppendUint(b.scratch[:0], uint64(x), 10))
+		case uint32:
+			b.Write(strconv.AppendUint(b.scratch[:0], uint64(x), 10))
+		case uint64:
+			b.Write(strconv.AppendUint(b.scratch[:0], x, 10))
+		default:
+			return false
+		}
+		return true
+	case 't':
+		if x, ok := arg.(bool); ok {
+			b.Write(strconv.AppendBool(b.scratch[:0], x))
+			return true
+		}
+	}
+	return false
+}
+
+// Fprintf formats according to the (cached) format specifier and writes to w. Verbs that are
+// not understood by the cache, and mismatched argument counts, are handled by fmt.
+func (fc *FormatCache) Fprintf(w io.Stream, m string, a ...interface{}) (int, error) {
+	p := fc.lookup(m)
+	if !p.ok || p.nverbs != len(a) {
+		return fmt.Fprintf(w, m, a...)
+	}
+	b := formatBuffers.Get().(*formatBuffer)
+	defer func() {
+		b.Reset()
+		formatBuffers.Put(b)
+	}()
+	k := 0
+	for i := range p.segs {
+		seg := &p.segs[i]
+		b.WriteString(seg.lit)
+		if seg.verb == 0 {
+			continue
+		}
+		if !seg.simple || !b.appendArg(seg.verb, a[k]) {
+			fmt.Fprintf(&b.Buffer, seg.spec, a[k])
+		}
+		k++
+	}
+	return w.Write(b.Bytes())
+}
+
+// CachedFormat returns a Marshaler that behaves like Format but caches the parsed form of
+// each format string, which avoids much of the overhead of fmt for frequently logged messages.
+// The cache is shared by all calls to the returned Marshaler. An EOM signal is sent after every
+// log message.
+func CachedFormat(d ...Decorator) Marshaler {
+	fc := &FormatCache{}
+	return Decorators(d).Decorate(Marshaler(
+		func(_ context.Context, w io.Stream, m string, a ...interface{}) (err error) {
+			a = formatArgs(a)
+			if m != "" {
+				_, err = fc.Fprintf(w, m, a...)
+			} else {
+				_, err = fmt.Fprint(w, a...)
+			}
+			err = w.EOM(err)
+			return
+		}))
+}
@@ -0,0 +1,106 @@
+/*
+Copyright 2016 James DeFelice
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package encoding_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	. "github.com/gologs/log/encoding"
+	"github.com/gologs/log/io"
+)
+
+func TestCachedFormat(t *testing.T) {
+	var (
+		capture string
+		b       = &io.BufferedStream{
+			EOMFunc: func(buf io.Buffer, e error) error {
+				capture = buf.String()
+				return e
+			},
+		}
+		m = CachedFormat()
+	)
+	for i, tc := range []struct {
+		m string
+		a []interface{}
+	}{
+		{"", []interface{}{1, "a", 2}},
+		{"plain", nil},
+		{"100%%", nil},
+		{"%s and %v", []interface{}{"foo", "bar"}},
+		{"%d %d %d %d", []interface{}{1, int8(-2), uint(3), int64(-4)}},
+		{"%v %t", []interface{}{uint64(5), true}},
+		{"%s", []interface{}{[]byte("bytes")}},
+		{"%v", []interface{}{[]byte("bytes")}},
+		{"%s", []interface{}{errors.New("an error")}},
+		{"%s", []interface{}{1}},
+		{"%5d|%-5s|%.2f", []interface{}{1, "a", 3.14159}},
+		{"%q %x", []interface{}{"quoted", 255}},
+		{"%d %d", []interface{}{1}},
+		{"%d", []interface{}{1, 2}},
+		{"%[2]d %[1]d", []interface{}{1, 2}},
+		{"%*d", []interface{}{3, 1}},
+		{"trailing %", nil},
+		{"%d%%", []interface{}{50}},
+	} {
+		// run each case twice to exercise the cache
+		for j := 0; j < 2; j++ {
+			if err := m(nil, b, tc.m, tc.a...); err != nil {
+				t.Fatalf("test case %d: unexpected error: %v", i, err)
+			}
+			// values are rendered per RegisterValue, as by Format
+			if expected := Message(tc.m, tc.a...); capture != expected {
+				t.Errorf("test case %d: expected %q instead of %q", i, expected, capture)
+			}
+		}
+	}
+}
+
+func TestFormatCache_MaxEntries(t *testing.T) {
+	var (
+		fc = &FormatCache{MaxEntries: 1}
+		b  = &io.BufferedStream{}
+	)
+	for i := 0; i < 3; i++ {
+		_, err := fc.Fprintf(b, fmt.Sprintf("%d:%%d", i), i)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if s := b.String(); s != "0:01:12:2" {
+		t.Fatalf("unexpected output: %q", s)
+	}
+}
+
+func BenchmarkFormat(b *testing.B) {
+	benchmarkMarshaler(b, Format())
+}
+
+func BenchmarkCachedFormat(b *testing.B) {
+	benchmarkMarshaler(b, CachedFormat())
+}
+
+func benchmarkMarshaler(b *testing.B, m Marshaler) {
+	s := &io.BufferedStream{}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = m(nil, s, "user %s logged in after %d attempts", "jdef", 3)
+	}
+}
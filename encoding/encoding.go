@@ -18,6 +18,7 @@ package encoding
 
 import (
 	"fmt"
+	"sync"
 
 	"github.com/gologs/log/context"
 	"github.com/gologs/log/io"
@@ -64,11 +65,24 @@ func (dd Decorators) Copy() (clone Decorators) {
 	return
 }
 
+// Message renders the message of a log event. Print-style log events, those with an empty
+// message format (see logger.Print), are rendered by fmt.Sprint: '%' in their args is never
+// interpreted. Other log events are rendered by fmt.Sprintf.
+func Message(m string, a ...interface{}) string {
+	a = formatArgs(a)
+	if m == "" {
+		return fmt.Sprint(a...)
+	}
+	return fmt.Sprintf(m, a...)
+}
+
 // Format returns a Marshaler that uses fmt Print and Printf to format
-// log writes to streams. An EOM signal is sent after every log message.
+// log writes to streams, rendering values per RegisterValue. An EOM signal
+// is sent after every log message.
 func Format(d ...Decorator) Marshaler {
 	return Decorators(d).Decorate(Marshaler(
 		func(_ context.Context, w io.Stream, m string, a ...interface{}) (err error) {
+			a = formatArgs(a)
 			if m != "" {
 				_, err = fmt.Fprintf(w, m, a...)
 			} else {
@@ -79,17 +93,19 @@ func Format(d ...Decorator) Marshaler {
 		}))
 }
 
-// Iterable generates a byte slice; returns a nil Iterable when finished.
+// Iterable generates a byte slice; returns a nil Iterable when finished. Prefer Appender, which
+// doesn't allocate per log event.
 type Iterable func() ([]byte, Iterable)
 
-// NewIterable generates an Iterable that iterates over the given byte slices.
-func NewIterable(b ...[]byte) Iterable {
-	if len(b) == 0 {
-		return nil
-	}
-	return func() ([]byte, Iterable) {
-		return b[0], NewIterable(b[1:]...)
+// NewIterable generates an Iterable that iterates over the given byte slices. The Iterables of
+// all of the slices are allocated up front, so iterating (any number of times) doesn't allocate:
+// generate them once and reuse them, rather than per log event.
+func NewIterable(b ...[]byte) (it Iterable) {
+	for i := len(b) - 1; i >= 0; i-- {
+		x, next := b[i], it
+		it = func() ([]byte, Iterable) { return x, next }
 	}
+	return
 }
 
 // Singular generates an Iterable for a single buffer; more efficient if you'll only
@@ -103,8 +119,46 @@ func Singular(b []byte) Iterable {
 	}
 }
 
+// Appender appends bytes, typically derived from the context of a log event, to `dst` and
+// returns the extended buffer.
+type Appender func(c context.Context, dst []byte) []byte
+
+// maxScratch bounds the capacity of buffers that are returned to the scratch pool
+const maxScratch = 1 << 12
+
+var scratch = sync.Pool{New: func() interface{} {
+	b := make([]byte, 0, 64)
+	return &b
+}}
+
+// AppendPrefix returns a stream Decorator that outputs the bytes generated by the Appender for
+// each stream operation. Buffers are pooled, so multi-part prefixes may be rendered without
+// allocating per log event.
+func AppendPrefix(f Appender) Decorator {
+	if f == nil {
+		return NoDecorator()
+	}
+	return func(op Marshaler) Marshaler {
+		return func(c context.Context, s io.Stream, m string, a ...interface{}) (err error) {
+			bp := scratch.Get().(*[]byte)
+			b := f(c, (*bp)[:0])
+			if len(b) > 0 {
+				_, err = s.Write(b)
+			}
+			if cap(b) <= maxScratch {
+				*bp = b[:0]
+				scratch.Put(bp)
+			}
+			if err == nil {
+				err = op(c, s, m, a...)
+			}
+			return
+		}
+	}
+}
+
 // Prefix returns a stream Decorator that outputs a prefix blob for each stream
-// operation.
+// operation. It's retained for compatibility: AppendPrefix is more efficient.
 func Prefix(prefixf func(context.Context) Iterable) Decorator {
 	if prefixf == nil {
 		return NoDecorator()
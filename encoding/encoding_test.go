@@ -17,7 +17,11 @@ limitations under the License.
 package encoding_test
 
 import (
+	"bytes"
+	"fmt"
+	"net"
 	"testing"
+	"time"
 
 	"github.com/gologs/log/context"
 	. "github.com/gologs/log/encoding"
@@ -109,8 +113,9 @@ func TestPrefix(t *testing.T) {
 			return nil
 		},
 	}
+	it := NewIterable([]byte("b"), []byte("ar"))
 	d = Prefix(func(_ context.Context) Iterable {
-		return NewIterable([]byte("bar"))
+		return it // reused, per log event
 	})
 	err = Format(d)(nil, b, "foo")
 	if err != nil {
@@ -129,6 +134,29 @@ func TestPrefix(t *testing.T) {
 	}
 }
 
+func TestAppendPrefix(t *testing.T) {
+	var (
+		capture string
+		b       = &io.BufferedStream{
+			EOMFunc: func(buf io.Buffer, e error) error {
+				capture = buf.String()
+				return e
+			},
+		}
+		d = AppendPrefix(func(_ context.Context, dst []byte) []byte {
+			return append(append(dst, "bar"...), ' ')
+		})
+	)
+	for i := 0; i < 2; i++ { // buffers are reused
+		if err := Format(d)(nil, b, "foo%d", i); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if expected := fmt.Sprintf("bar foo%d", i); capture != expected {
+			t.Fatalf("expected %q instead of %q", expected, capture)
+		}
+	}
+}
+
 func TestWithContext(t *testing.T) {
 	var (
 		n   = NullMarshaler()
@@ -156,3 +184,112 @@ func TestWithContext(t *testing.T) {
 		t.Fatalf("unexpected foo: %q", foo)
 	}
 }
+
+func TestRegisterValue(t *testing.T) {
+	type celsius float64
+	for i, tc := range []struct {
+		v        interface{}
+		expected string
+	}{
+		{1200 * time.Millisecond, "1.2s"},
+		{[]byte("hi"), "aGk="},
+		{net.ParseIP("::ffff:10.0.0.1"), "10.0.0.1"},
+		{celsius(21.5), "21.5"},
+		{nil, "<nil>"},
+	} {
+		if s := Sprint(tc.v); s != tc.expected {
+			t.Errorf("test case %d: expected %q instead of %q", i, tc.expected, s)
+		}
+	}
+
+	RegisterValue([]byte(nil), HexBytes)
+	RegisterValue(celsius(0), func(v interface{}) string { return fmt.Sprintf("%.1f°C", v) })
+	defer RegisterValue([]byte(nil), Base64Bytes)
+	defer RegisterValue(celsius(0), nil)
+
+	if s := Sprint([]byte("hi")); s != "6869" {
+		t.Errorf("expected hex instead of %q", s)
+	}
+	if s := Sprint(celsius(21.5)); s != "21.5°C" {
+		t.Errorf("expected custom format instead of %q", s)
+	}
+
+	a := []interface{}{[]byte("hi"), celsius(21.5)}
+	for i, tc := range []struct {
+		m        string
+		expected string
+	}{
+		{"", "6869 21.5°C"},
+		{"%v at %6v", "6869 at 21.5°C"},
+		{"%s at %.0f", "hi at 22"},
+	} {
+		for _, m := range []Marshaler{Format(), CachedFormat()} {
+			var capture string
+			s := &io.BufferedStream{EOMFunc: func(b io.Buffer, err error) error {
+				capture = b.String()
+				return err
+			}}
+			if err := m(nil, s, tc.m, a...); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if capture != tc.expected {
+				t.Errorf("test case %d: expected %q instead of %q", i, tc.expected, capture)
+			}
+		}
+		if s := Message(tc.m, a...); s != tc.expected {
+			t.Errorf("test case %d: expected %q instead of %q", i, tc.expected, s)
+		}
+	}
+	if _, ok := a[0].([]byte); !ok {
+		t.Errorf("expected the args to be left as-is")
+	}
+}
+
+type boom struct{}
+
+func (boom) String() string { panic("boom") }
+
+func TestRecover(t *testing.T) {
+	var (
+		buf  bytes.Buffer
+		s    = io.NewBuffered(io.TextStream(&buf))
+		strs = Recover()(func(_ context.Context, s io.Stream, m string, a ...interface{}) error {
+			s.Write([]byte(m))
+			for _, x := range a {
+				if str, ok := x.(fmt.Stringer); ok {
+					s.Write([]byte(" " + str.String()))
+				} else {
+					s.Write([]byte(fmt.Sprint(" ", x)))
+				}
+			}
+			return s.EOM(nil)
+		})
+	)
+	if err := strs(nil, s, "a", time.Second); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	err := strs(nil, s, "b", time.Second, boom{})
+	if pe, ok := err.(*PanicError); !ok || pe.Value != "boom" {
+		t.Fatalf("expected a PanicError instead of %#v", err)
+	}
+	if expected := "a 1s\nb 1s %!v(PANIC=String method: boom)\n"; buf.String() != expected {
+		t.Fatalf("expected %q instead of %q", expected, buf.String())
+	}
+}
+
+func TestMessage(t *testing.T) {
+	for i, tc := range []struct {
+		m        string
+		a        []interface{}
+		expected string
+	}{
+		{"", []interface{}{"100%", "d"}, "100%d"},
+		{"", []interface{}{"%d", 1}, "%d1"},
+		{"%d%%", []interface{}{100}, "100%"},
+		{"hello", nil, "hello"},
+	} {
+		if s := Message(tc.m, tc.a...); s != tc.expected {
+			t.Errorf("test case %d: expected %q instead of %q", i, tc.expected, s)
+		}
+	}
+}
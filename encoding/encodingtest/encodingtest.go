@@ -0,0 +1,97 @@
+/*
+Copyright 2016 James DeFelice
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package encodingtest provides benchmarks for Marshalers, so that the performance of encoders
+// can be evaluated consistently: for example, against the encoders that this module provides.
+package encodingtest
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gologs/log/context"
+	"github.com/gologs/log/context/fields"
+	"github.com/gologs/log/context/timestamp"
+	"github.com/gologs/log/encoding"
+	"github.com/gologs/log/io"
+	"github.com/gologs/log/levels"
+)
+
+// Sizes are the lengths, in bytes, of the messages of the log events generated by Benchmark.
+var Sizes = []int{16, 256, 4096}
+
+// Fields are attached to the context of the log events generated by the "fields" variants of
+// Benchmark, see fields.FromContext.
+var Fields = []fields.Field{
+	fields.F("user", "jdef"),
+	fields.F("attempts", 3),
+	fields.F("elapsed", 1500*time.Millisecond),
+	fields.F("ok", true),
+}
+
+// Benchmark runs a sub-benchmark of the Marshaler for each of Sizes, for log events with and
+// without Fields. Allocations are reported, as is the number of bytes encoded per log event
+// ("B/event"). Log events are at levels.Info and have a timestamp, but no caller.
+func Benchmark(b *testing.B, m encoding.Marshaler) {
+	ctx := levels.NewContext(timestamp.NewContext(context.Background(), time.Date(2016, 1, 2, 15, 4, 5, 0, time.UTC)), levels.Info)
+	for _, withFields := range []bool{false, true} {
+		c := ctx
+		if withFields {
+			c = fields.NewContext(ctx, Fields...)
+		}
+		for _, size := range Sizes {
+			name := strconv.Itoa(size)
+			if withFields {
+				name += "/fields"
+			}
+			msg := strings.Repeat("x", size)
+			b.Run(name, func(b *testing.B) { benchmark(b, m, c, msg) })
+		}
+	}
+}
+
+func benchmark(b *testing.B, m encoding.Marshaler, c context.Context, msg string) {
+	var (
+		n int64
+		s = &io.BufferedStream{EOMFunc: func(buf io.Buffer, err error) error {
+			n += int64(buf.Len())
+			return err
+		}}
+	)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = m(c, s, "request %s", msg)
+	}
+	b.StopTimer()
+	b.ReportMetric(float64(n)/float64(b.N), "B/event")
+}
+
+// BenchmarkAll runs Benchmark for each of the named Marshalers, in order of their names.
+func BenchmarkAll(b *testing.B, marshalers map[string]encoding.Marshaler) {
+	names := make([]string, 0, len(marshalers))
+	for name := range marshalers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		m := marshalers[name]
+		b.Run(name, func(b *testing.B) { Benchmark(b, m) })
+	}
+}
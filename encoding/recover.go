@@ -0,0 +1,92 @@
+/*
+Copyright 2016 James DeFelice
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package encoding
+
+import (
+	"fmt"
+
+	"github.com/gologs/log/context"
+	"github.com/gologs/log/io"
+)
+
+// PanicError is reported by the Marshalers generated by Recover when marshaling a log event
+// panics.
+type PanicError struct {
+	Value interface{} // Value is the value recovered from the panic
+}
+
+func (e *PanicError) Error() string { return fmt.Sprintf("marshaler panic: %v", e.Value) }
+
+// Recover returns a Decorator that recovers panics of the decorated Marshaler, typically raised
+// by a String or Error method of a log argument. The partially written log event is terminated
+// via Stream.EOM, and the log event is marshaled once more with the offending arguments replaced
+// by placeholders (in the style of package fmt, for example "%!v(PANIC=String method: boom)").
+// A *PanicError is returned so that the panic is reported to the ErrorSink. If the second
+// attempt panics as well then the log event is dropped.
+func Recover() Decorator {
+	return func(op Marshaler) Marshaler {
+		return func(c context.Context, s io.Stream, m string, a ...interface{}) (err error) {
+			x, err := tryMarshal(op, c, s, m, a)
+			if x == nil {
+				return err
+			}
+			_ = s.EOM(&PanicError{x}) // discard whatever was written
+			if y, err := tryMarshal(op, c, s, m, placeholders(a)); y != nil {
+				return s.EOM(&PanicError{y})
+			} else if err != nil {
+				return err
+			}
+			return &PanicError{x}
+		}
+	}
+}
+
+func tryMarshal(op Marshaler, c context.Context, s io.Stream, m string, a []interface{}) (x interface{}, err error) {
+	defer func() { x = recover() }()
+	err = op(c, s, m, a...)
+	return
+}
+
+// placeholders returns a copy of `a` in which the args with panicking String or Error methods
+// are replaced by strings describing the panic.
+func placeholders(a []interface{}) []interface{} {
+	b := make([]interface{}, len(a))
+	for i, v := range a {
+		b[i] = v
+		switch x := v.(type) {
+		case error:
+			if p, ok := panics("Error", func() { _ = x.Error() }); ok {
+				b[i] = p
+			}
+		case fmt.Stringer:
+			if p, ok := panics("String", func() { _ = x.String() }); ok {
+				b[i] = p
+			}
+		}
+	}
+	return b
+}
+
+func panics(method string, f func()) (p string, ok bool) {
+	defer func() {
+		if x := recover(); x != nil {
+			p, ok = fmt.Sprintf("%%!v(PANIC=%s method: %v)", method, x), true
+		}
+	}()
+	f()
+	return
+}
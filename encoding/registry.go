@@ -0,0 +1,64 @@
+/*
+Copyright 2016 James DeFelice
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package encoding
+
+import (
+	"sort"
+	"sync"
+)
+
+// Factory generates a Marshaler.
+type Factory func() Marshaler
+
+var registry = struct {
+	sync.RWMutex
+	factories map[string]Factory
+}{factories: map[string]Factory{
+	"text":   func() Marshaler { return Format() },
+	"cached": func() Marshaler { return CachedFormat() },
+}}
+
+// Register associates a Marshaler Factory with a name so that declarative configurations may
+// refer to it. Registering a name again replaces the prior Factory. Built-in names are "text"
+// (see Format) and "cached" (see CachedFormat).
+func Register(name string, f Factory) {
+	registry.Lock()
+	defer registry.Unlock()
+	registry.factories[name] = f
+}
+
+// Lookup generates a Marshaler using the Factory registered with the given name.
+func Lookup(name string) (Marshaler, bool) {
+	registry.RLock()
+	f, ok := registry.factories[name]
+	registry.RUnlock()
+	if !ok {
+		return nil, false
+	}
+	return f(), true
+}
+
+// Registered returns the sorted names of all registered Marshaler factories.
+func Registered() (names []string) {
+	registry.RLock()
+	defer registry.RUnlock()
+	for name := range registry.factories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return
+}
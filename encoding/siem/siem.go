@@ -0,0 +1,212 @@
+/*
+Copyright 2016 James DeFelice
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package siem provides Marshalers that render log events in the formats ingested by security
+// information and event management systems: ArcSight CEF and QRadar LEEF.
+package siem
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/gologs/log/context"
+	"github.com/gologs/log/context/fields"
+	"github.com/gologs/log/context/timestamp"
+	"github.com/gologs/log/encoding"
+	"github.com/gologs/log/io"
+	"github.com/gologs/log/levels"
+)
+
+// Config determines the header and extension attributes of the rendered log events.
+type Config struct {
+	Vendor  string
+	Product string
+	Version string
+
+	// EventID returns the event class (CEF) or event ID (LEEF) of a log event; by default it's
+	// the format string of the log event, so that events generated by the same call site share
+	// an ID.
+	EventID func(c context.Context, format string) string
+
+	// Severity maps levels to severity values; defaults to DefaultSeverity.
+	Severity func(levels.Level) int
+
+	// Mapping renames the fields of log events (see fields.FromContext) to extension keys, for
+	// example {"user": "suser"}. Fields that aren't mapped retain their own names, unless
+	// DropUnmapped is true. Fields with duplicate keys are resolved via fields.LastWins.
+	Mapping      map[string]string
+	DropUnmapped bool
+}
+
+// DefaultSeverity maps levels to the CEF severity scale, 0 (lowest) to 10 (highest).
+func DefaultSeverity(lvl levels.Level) int {
+	switch lvl {
+	case levels.Debug:
+		return 1
+	case levels.Info:
+		return 3
+	case levels.Warn:
+		return 5
+	case levels.Error:
+		return 7
+	case levels.Fatal:
+		return 9
+	case levels.Panic:
+		return 10
+	}
+	return 0
+}
+
+func (cfg *Config) eventID(c context.Context, m string) string {
+	if cfg.EventID != nil {
+		return cfg.EventID(c, m)
+	}
+	return m
+}
+
+func (cfg *Config) severity(c context.Context) int {
+	lvl, _ := levels.FromContext(c)
+	if cfg.Severity != nil {
+		return cfg.Severity(lvl)
+	}
+	return DefaultSeverity(lvl)
+}
+
+// extensions invokes f for each field to be rendered, in order
+func (cfg *Config) extensions(c context.Context, f func(key string, value interface{})) {
+	ff, _ := fields.FromContext(c).Dedupe(fields.LastWins)
+	for _, x := range ff {
+		key, ok := cfg.Mapping[x.Key]
+		if !ok {
+			if cfg.DropUnmapped {
+				continue
+			}
+			key = x.Key
+		}
+		f(key, x.Value)
+	}
+}
+
+// escape appends s to b, escaping the given chars with a backslash; newlines are rendered as
+// "\n" and carriage returns as "\r".
+func escape(b []byte, s, chars string) []byte {
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '\n':
+			b = append(b, '\\', 'n')
+		case c == '\r':
+			b = append(b, '\\', 'r')
+		case strings.IndexByte(chars, c) >= 0:
+			b = append(b, '\\', c)
+		default:
+			b = append(b, c)
+		}
+	}
+	return b
+}
+
+// key appends an extension key; only alphanumeric chars (and '_') are permitted
+func key(b []byte, s string) []byte {
+	for i := 0; i < len(s); i++ {
+		if c := s[i]; c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_' {
+			b = append(b, c)
+		} else {
+			b = append(b, '_')
+		}
+	}
+	return b
+}
+
+func write(w io.Stream, b []byte) error {
+	_, err := w.Write(b)
+	return w.EOM(err)
+}
+
+// CEF returns a Marshaler that renders log events in ArcSight Common Event Format:
+//
+//	CEF:0|Vendor|Product|Version|EventClassID|Name|Severity|rt=... msg=... key=value
+//
+// The Name is the formatted log message. The timestamp of the log event, if any, is rendered as
+// `rt` (epoch milliseconds) and the message is repeated as `msg`, followed by the fields of the
+// log event.
+func CEF(cfg Config) encoding.Marshaler {
+	const header, ext = `\|`, `\=`
+	return func(c context.Context, w io.Stream, m string, a ...interface{}) error {
+		msg := encoding.Message(m, a...)
+		b := make([]byte, 0, 256)
+		b = append(b, "CEF:0|"...)
+		for _, s := range []string{cfg.Vendor, cfg.Product, cfg.Version, cfg.eventID(c, m), msg} {
+			b = append(escape(b, s, header), '|')
+		}
+		b = strconv.AppendInt(b, int64(cfg.severity(c)), 10)
+		b = append(b, '|')
+		if ts, ok := timestamp.FromContext(c); ok {
+			b = strconv.AppendInt(append(b, "rt="...), ts.UnixNano()/1e6, 10)
+			b = append(b, ' ')
+		}
+		b = escape(append(b, "msg="...), msg, ext)
+		cfg.extensions(c, func(k string, v interface{}) {
+			b = append(key(append(b, ' '), k), '=')
+			b = escape(b, encoding.Sprint(v), ext)
+		})
+		return write(w, b)
+	}
+}
+
+// LEEFTimeFormat is the default LEEF `devTime` layout.
+const LEEFTimeFormat = "Jan 02 2006 15:04:05"
+
+// LEEF returns a Marshaler that renders log events in QRadar Log Event Extended Format 1.0,
+// with tab-delimited attributes:
+//
+//	LEEF:1.0|Vendor|Product|Version|EventID|sev=...	devTime=...	msg=...	key=value
+func LEEF(cfg Config) encoding.Marshaler {
+	const header = `\|`
+	return func(c context.Context, w io.Stream, m string, a ...interface{}) error {
+		b := make([]byte, 0, 256)
+		b = append(b, "LEEF:1.0|"...)
+		for _, s := range []string{cfg.Vendor, cfg.Product, cfg.Version, cfg.eventID(c, m)} {
+			b = append(escape(b, s, header), '|')
+		}
+		b = strconv.AppendInt(append(b, "sev="...), int64(cfg.severity(c)), 10)
+		if ts, ok := timestamp.FromContext(c); ok {
+			b = ts.AppendFormat(append(b, "\tdevTime="...), LEEFTimeFormat)
+		}
+		b = leefValue(append(b, "\tmsg="...), encoding.Message(m, a...))
+		cfg.extensions(c, func(k string, v interface{}) {
+			b = append(key(append(b, '\t'), k), '=')
+			b = leefValue(b, encoding.Sprint(v))
+		})
+		return write(w, b)
+	}
+}
+
+// leefValue appends an attribute value; tabs (the delimiter) are rendered as "\t"
+func leefValue(b []byte, s string) []byte {
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; c {
+		case '\t':
+			b = append(b, '\\', 't')
+		case '\n':
+			b = append(b, '\\', 'n')
+		case '\r':
+			b = append(b, '\\', 'r')
+		default:
+			b = append(b, c)
+		}
+	}
+	return b
+}
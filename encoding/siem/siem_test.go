@@ -0,0 +1,74 @@
+/*
+Copyright 2016 James DeFelice
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package siem_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gologs/log/context"
+	"github.com/gologs/log/context/fields"
+	"github.com/gologs/log/context/timestamp"
+	. "github.com/gologs/log/encoding/siem"
+	"github.com/gologs/log/io"
+	"github.com/gologs/log/levels"
+)
+
+func TestMarshalers(t *testing.T) {
+	var (
+		cfg = Config{
+			Vendor:  "Acme|Corp",
+			Product: "gologs",
+			Version: "1.0",
+			Mapping: map[string]string{"user": "suser"},
+		}
+		c = fields.NewContext(
+			levels.NewContext(
+				timestamp.NewContext(context.Background(), time.Date(2016, 1, 2, 15, 4, 5, 0, time.UTC)),
+				levels.Warn),
+			fields.F("user", "bob"), fields.F("query", "a=b\tc"))
+		dropped = cfg
+	)
+	dropped.DropUnmapped = true
+	for i, tc := range []struct {
+		cfg      Config
+		leef     bool
+		expected string
+	}{
+		{cfg, false, `CEF:0|Acme\|Corp|gologs|1.0|login %s|login bob|5|rt=1451747045000 msg=login bob suser=bob query=a\=b	c`},
+		{dropped, false, `CEF:0|Acme\|Corp|gologs|1.0|login %s|login bob|5|rt=1451747045000 msg=login bob suser=bob`},
+		{cfg, true, "LEEF:1.0|Acme\\|Corp|gologs|1.0|login %s|sev=5\tdevTime=Jan 02 2016 15:04:05\tmsg=login bob\tsuser=bob\tquery=a=b\\tc"},
+	} {
+		var (
+			capture string
+			s       = &io.BufferedStream{EOMFunc: func(b io.Buffer, err error) error {
+				capture = b.String()
+				return err
+			}}
+			m = CEF(tc.cfg)
+		)
+		if tc.leef {
+			m = LEEF(tc.cfg)
+		}
+		if err := m(c, s, "login %s", "bob"); err != nil {
+			t.Fatalf("test case %d: unexpected error: %v", i, err)
+		}
+		if capture != tc.expected {
+			t.Errorf("test case %d: expected %q instead of %q", i, tc.expected, capture)
+		}
+	}
+}
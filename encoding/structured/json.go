@@ -0,0 +1,137 @@
+/*
+Copyright 2016 James DeFelice
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package structured
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gologs/log/encoding"
+)
+
+// JSON returns a Marshaler that renders each log event as a single-line JSON object.
+func JSON(opts Options) encoding.Marshaler {
+	opts = opts.withDefaults()
+	e := newEncoder(opts)
+	return marshaler(opts, format{
+		begin: func(b []byte) []byte { return append(b, '{') },
+		end:   func(b []byte) []byte { return append(b, '}') },
+		field: func(b []byte, i int, key string, value interface{}) []byte {
+			if i > 0 {
+				b = append(b, ',')
+			}
+			b = append(appendJSONString(b, key), ':')
+			return e.appendJSON(b, value, 0)
+		},
+	})
+}
+
+func (e *encoder) appendJSON(b []byte, v interface{}, depth int) []byte {
+	if lm, ok := v.(LogMarshaler); ok {
+		if depth >= e.maxDepth {
+			return appendJSONString(b, truncated)
+		}
+		v, depth = lm.MarshalLog(), depth+1
+	}
+	if s, ok := encoding.FormatValue(v); ok {
+		return appendJSONString(b, s)
+	}
+	switch x := v.(type) {
+	case nil:
+		return append(b, "null"...)
+	case string:
+		return appendJSONString(b, x)
+	case bool:
+		return strconv.AppendBool(b, x)
+	case int:
+		return strconv.AppendInt(b, int64(x), 10)
+	case int32:
+		return strconv.AppendInt(b, int64(x), 10)
+	case int64:
+		return strconv.AppendInt(b, x, 10)
+	case uint:
+		return strconv.AppendUint(b, uint64(x), 10)
+	case uint32:
+		return strconv.AppendUint(b, uint64(x), 10)
+	case uint64:
+		return strconv.AppendUint(b, x, 10)
+	case float64:
+		return appendJSONFloat(b, x, 64)
+	case float32:
+		return appendJSONFloat(b, float64(x), 32)
+	case time.Time:
+		return append(appendTime(append(b, '"'), x), '"')
+	case error:
+		return appendJSONString(b, x.Error())
+	case json.Marshaler:
+		if buf, err := json.Marshal(x); err == nil {
+			return append(b, buf...)
+		}
+		return appendJSONString(b, fmt.Sprint(v))
+	case fmt.Stringer:
+		return appendJSONString(b, x.String())
+	}
+	return e.appendReflect(b, reflect.ValueOf(v), depth)
+}
+
+func appendJSONFloat(b []byte, f float64, bits int) []byte {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		// NaN and +/-Inf aren't representable in JSON
+		return appendJSONString(b, strconv.FormatFloat(f, 'g', -1, bits))
+	}
+	return strconv.AppendFloat(b, f, 'g', -1, bits)
+}
+
+const hex = "0123456789abcdef"
+
+func appendJSONString(b []byte, s string) []byte {
+	b = append(b, '"')
+	for i := 0; i < len(s); {
+		c := s[i]
+		if c < utf8.RuneSelf {
+			switch {
+			case c == '"' || c == '\\':
+				b = append(b, '\\', c)
+			case c == '\n':
+				b = append(b, '\\', 'n')
+			case c == '\r':
+				b = append(b, '\\', 'r')
+			case c == '\t':
+				b = append(b, '\\', 't')
+			case c < 0x20:
+				b = append(b, '\\', 'u', '0', '0', hex[c>>4], hex[c&0xf])
+			default:
+				b = append(b, c)
+			}
+			i++
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if r == utf8.RuneError && size == 1 {
+			b = append(b, `�`...)
+		} else {
+			b = append(b, s[i:i+size]...)
+		}
+		i += size
+	}
+	return append(b, '"')
+}
@@ -0,0 +1,109 @@
+/*
+Copyright 2016 James DeFelice
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package structured
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gologs/log/encoding"
+)
+
+// Logfmt returns a Marshaler that renders each log event as a line of space-separated
+// key=value pairs. Values that contain spaces, quotes, or '=' are quoted.
+func Logfmt(opts Options) encoding.Marshaler {
+	opts = opts.withDefaults()
+	var (
+		e    = newEncoder(opts)
+		noop = func(b []byte) []byte { return b }
+	)
+	return marshaler(opts, format{
+		begin: noop,
+		end:   noop,
+		field: func(b []byte, i int, key string, value interface{}) []byte {
+			if i > 0 {
+				b = append(b, ' ')
+			}
+			b = appendLogfmtKey(b, key)
+			return e.appendLogfmt(append(b, '='), value)
+		},
+	})
+}
+
+func appendLogfmtKey(b []byte, key string) []byte {
+	for i := 0; i < len(key); i++ {
+		if c := key[i]; c <= ' ' || c == '=' || c == '"' {
+			b = append(b, '_')
+		} else {
+			b = append(b, c)
+		}
+	}
+	return b
+}
+
+// appendLogfmt renders nested values (see LogMarshaler) as quoted JSON
+func (e *encoder) appendLogfmt(b []byte, v interface{}) []byte {
+	if lm, ok := v.(LogMarshaler); ok {
+		v = lm.MarshalLog()
+	}
+	if s, ok := encoding.FormatValue(v); ok {
+		return appendLogfmtString(b, s)
+	}
+	var s string
+	switch x := v.(type) {
+	case nil:
+		return append(b, "null"...)
+	case string:
+		s = x
+	case bool:
+		return strconv.AppendBool(b, x)
+	case int:
+		return strconv.AppendInt(b, int64(x), 10)
+	case int64:
+		return strconv.AppendInt(b, x, 10)
+	case uint64:
+		return strconv.AppendUint(b, x, 10)
+	case float64:
+		return strconv.AppendFloat(b, x, 'g', -1, 64)
+	case time.Time:
+		return appendTime(b, x)
+	case error:
+		s = x.Error()
+	case fmt.Stringer:
+		s = x.String()
+	default:
+		if nested(v) {
+			s = string(e.appendJSON(nil, v, 0))
+		} else {
+			s = fmt.Sprint(v)
+		}
+	}
+	return appendLogfmtString(b, s)
+}
+
+func appendLogfmtString(b []byte, s string) []byte {
+	if s == "" || strings.IndexFunc(s, needsQuote) >= 0 {
+		return strconv.AppendQuote(b, s)
+	}
+	return append(b, s...)
+}
+
+func needsQuote(r rune) bool {
+	return r <= ' ' || r == '=' || r == '"' || r == '\\' || r == 0x7f
+}
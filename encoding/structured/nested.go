@@ -0,0 +1,208 @@
+/*
+Copyright 2016 James DeFelice
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package structured
+
+import (
+	"encoding/base64"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gologs/log/encoding"
+)
+
+// Default limits for nested values, see Options.
+const (
+	DefaultMaxDepth    = 5
+	DefaultMaxElements = 100
+)
+
+// truncated is rendered in place of values that exceed the configured limits
+const truncated = "..."
+
+// LogMarshaler is implemented by types that control their own structured representation: the
+// value returned by MarshalLog is rendered instead of the receiver. It may be a primitive, or a
+// map, slice, or struct of such values.
+type LogMarshaler interface {
+	MarshalLog() interface{}
+}
+
+// encoder renders nested values as JSON, within limits
+type encoder struct {
+	maxDepth, maxElems int
+}
+
+func newEncoder(opts Options) *encoder {
+	return &encoder{maxDepth: opts.MaxDepth, maxElems: opts.MaxElements}
+}
+
+// nested returns true if v is (or points to) a map, slice, array, or struct
+func nested(v interface{}) bool {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	switch rv.Kind() {
+	case reflect.Map, reflect.Slice, reflect.Array, reflect.Struct:
+		return true
+	}
+	return false
+}
+
+func (e *encoder) appendReflect(b []byte, rv reflect.Value, depth int) []byte {
+	switch rv.Kind() {
+	case reflect.Invalid:
+		return append(b, "null"...)
+	case reflect.Ptr, reflect.Interface:
+		if rv.IsNil() {
+			return append(b, "null"...)
+		}
+		return e.appendElem(b, rv.Elem(), depth)
+	case reflect.Bool:
+		return strconv.AppendBool(b, rv.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.AppendInt(b, rv.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.AppendUint(b, rv.Uint(), 10)
+	case reflect.Float32:
+		return appendJSONFloat(b, rv.Float(), 32)
+	case reflect.Float64:
+		return appendJSONFloat(b, rv.Float(), 64)
+	case reflect.String:
+		return appendJSONString(b, rv.String())
+	case reflect.Map, reflect.Slice, reflect.Array, reflect.Struct:
+		if rv.Kind() == reflect.Slice && rv.IsNil() || rv.Kind() == reflect.Map && rv.IsNil() {
+			return append(b, "null"...)
+		}
+		if depth >= e.maxDepth {
+			return appendJSONString(b, truncated)
+		}
+		switch rv.Kind() {
+		case reflect.Map:
+			return e.appendMap(b, rv, depth+1)
+		case reflect.Struct:
+			return e.appendStruct(b, rv, depth+1)
+		}
+		if rv.Kind() == reflect.Slice && rv.Type().Elem().Kind() == reflect.Uint8 {
+			// consistent with encoding/json
+			return appendJSONString(b, base64.StdEncoding.EncodeToString(rv.Bytes()))
+		}
+		return e.appendSlice(b, rv, depth+1)
+	}
+	if rv.CanInterface() {
+		return appendJSONString(b, encoding.Sprint(rv.Interface()))
+	}
+	return appendJSONString(b, rv.Type().String())
+}
+
+// appendElem renders a nested element, honoring interfaces such as LogMarshaler
+func (e *encoder) appendElem(b []byte, rv reflect.Value, depth int) []byte {
+	if rv.CanInterface() {
+		return e.appendJSON(b, rv.Interface(), depth)
+	}
+	return e.appendReflect(b, rv, depth)
+}
+
+func (e *encoder) appendSlice(b []byte, rv reflect.Value, depth int) []byte {
+	b = append(b, '[')
+	n := rv.Len()
+	for i := 0; i < n; i++ {
+		if i > 0 {
+			b = append(b, ',')
+		}
+		if i == e.maxElems {
+			b = appendJSONString(b, truncated)
+			break
+		}
+		b = e.appendElem(b, rv.Index(i), depth)
+	}
+	return append(b, ']')
+}
+
+func (e *encoder) appendMap(b []byte, rv reflect.Value, depth int) []byte {
+	type entry struct {
+		key string
+		val reflect.Value
+	}
+	entries := make([]entry, 0, rv.Len())
+	for it := rv.MapRange(); it.Next(); {
+		k := it.Key()
+		if k.Kind() == reflect.String {
+			entries = append(entries, entry{k.String(), it.Value()})
+		} else if k.CanInterface() {
+			entries = append(entries, entry{encoding.Sprint(k.Interface()), it.Value()})
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].key < entries[j].key })
+	b = append(b, '{')
+	for i, x := range entries {
+		if i > 0 {
+			b = append(b, ',')
+		}
+		if i == e.maxElems {
+			b = append(appendJSONString(b, truncated), ':')
+			b = strconv.AppendInt(b, int64(len(entries)-i), 10)
+			break
+		}
+		b = append(appendJSONString(b, x.key), ':')
+		b = e.appendElem(b, x.val, depth)
+	}
+	return append(b, '}')
+}
+
+// appendStruct renders the exported fields of a struct, honoring the names and "omitempty"
+// options of `json` tags; fields tagged "-" are omitted. Maps and structs that exceed the
+// element limit are rendered with a final "..." key whose value counts the omitted elements.
+func (e *encoder) appendStruct(b []byte, rv reflect.Value, depth int) []byte {
+	var (
+		t = rv.Type()
+		n int
+	)
+	b = append(b, '{')
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if sf.PkgPath != "" {
+			continue // unexported
+		}
+		name := sf.Name
+		if tag, ok := sf.Tag.Lookup("json"); ok {
+			if tag == "-" {
+				continue
+			}
+			opts := strings.Split(tag, ",")
+			if opts[0] != "" {
+				name = opts[0]
+			}
+			if len(opts) > 1 && opts[1] == "omitempty" && rv.Field(i).IsZero() {
+				continue
+			}
+		}
+		if n > 0 {
+			b = append(b, ',')
+		}
+		if n == e.maxElems {
+			b = append(appendJSONString(b, truncated), ':')
+			b = strconv.AppendInt(b, int64(t.NumField()-i), 10)
+			break
+		}
+		b = append(appendJSONString(b, name), ':')
+		b = e.appendElem(b, rv.Field(i), depth)
+		n++
+	}
+	return append(b, '}')
+}
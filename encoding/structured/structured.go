@@ -0,0 +1,275 @@
+/*
+Copyright 2016 James DeFelice
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package structured provides Marshalers that render log events as sets of key/value pairs:
+// the timestamp, level, message, and caller of each event, followed by the fields found in the
+// context of the event (see fields.FromContext). Importing this package registers the "json"
+// and "logfmt" encodings (see encoding.Register).
+package structured
+
+import (
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gologs/log/caller"
+	"github.com/gologs/log/context"
+	"github.com/gologs/log/context/fields"
+	"github.com/gologs/log/context/timestamp"
+	"github.com/gologs/log/encoding"
+	"github.com/gologs/log/io"
+	"github.com/gologs/log/io/ioutil"
+	"github.com/gologs/log/levels"
+)
+
+func init() {
+	encoding.Register("json", func() encoding.Marshaler { return JSON(Options{}) })
+	encoding.Register("logfmt", func() encoding.Marshaler { return Logfmt(Options{}) })
+}
+
+// LevelFormat renders a level as a value, typically a string or a number.
+type LevelFormat func(levels.Level) interface{}
+
+// LevelName renders levels by their lowercase names, for example "info".
+func LevelName() LevelFormat { return func(lvl levels.Level) interface{} { return lvl.String() } }
+
+// LevelCode renders levels by their single-letter codes, for example "I".
+func LevelCode() LevelFormat {
+	return func(lvl levels.Level) interface{} {
+		if s := lvl.String(); len(s) > 0 && lvl&(lvl-1) == 0 && lvl.Severity() > 0 {
+			return string(s[0] - 'a' + 'A')
+		}
+		return "?"
+	}
+}
+
+// LevelSyslog renders levels as syslog severity numbers, see ioutil.SyslogSeverity.
+func LevelSyslog() LevelFormat {
+	return func(lvl levels.Level) interface{} { return ioutil.SyslogSeverity(lvl) }
+}
+
+// LevelMap renders levels using the given mapping; unmapped levels are rendered by name.
+func LevelMap(m map[levels.Level]interface{}) LevelFormat {
+	return func(lvl levels.Level) interface{} {
+		if v, ok := m[lvl]; ok {
+			return v
+		}
+		return lvl.String()
+	}
+}
+
+// LevelField renders the level of a log event as the value of Key.
+type LevelField struct {
+	Key    string
+	Format LevelFormat
+}
+
+// Options determine the keys of the rendered log events. Empty keys select the defaults; a key
+// of "-" omits the respective value.
+type Options struct {
+	TimeKey    string // TimeKey defaults to "time"
+	MessageKey string // MessageKey defaults to "msg"
+	CallerKey  string // CallerKey defaults to "caller"
+
+	// Levels determine how the level of a log event is rendered, for example both by name and
+	// by number; defaults to {"level", LevelName()}.
+	Levels []LevelField
+
+	// MaxDepth limits the depth of nested values (maps, slices, structs, and LogMarshalers)
+	// and MaxElements limits the number of elements of each; they default to DefaultMaxDepth
+	// and DefaultMaxElements.
+	MaxDepth    int
+	MaxElements int
+
+	// Annotations, when true, renders the registered annotations of the context of each log
+	// event (see context.RegisterField), such as "request_id", other than the time, level, and
+	// caller which are rendered as specified above.
+	Annotations bool
+
+	// Duplicates determines how keys that occur more than once are resolved, including fields
+	// that collide with the time, level, message, and caller keys; defaults to fields.LastWins.
+	// Per fields.Strict, the DuplicateKeyError is returned by the marshaler.
+	Duplicates fields.Policy
+
+	// Order lists keys that are rendered first, in the given order. Other keys are rendered
+	// afterwards: sorted by name if SortKeys is true, otherwise in the order that they're
+	// generated (time, level, message, caller, and then fields).
+	Order    []string
+	SortKeys bool
+
+	rank map[string]int
+}
+
+func orDefault(key, def string) string {
+	switch key {
+	case "":
+		return def
+	case "-":
+		return ""
+	}
+	return key
+}
+
+func (o Options) withDefaults() Options {
+	o.TimeKey = orDefault(o.TimeKey, "time")
+	o.MessageKey = orDefault(o.MessageKey, "msg")
+	o.CallerKey = orDefault(o.CallerKey, "caller")
+	if o.MaxDepth <= 0 {
+		o.MaxDepth = DefaultMaxDepth
+	}
+	if o.MaxElements <= 0 {
+		o.MaxElements = DefaultMaxElements
+	}
+	if o.Levels == nil {
+		o.Levels = []LevelField{{"level", LevelName()}}
+	}
+	if len(o.Order) > 0 {
+		o.rank = make(map[string]int, len(o.Order))
+		for i, k := range o.Order {
+			if _, ok := o.rank[k]; !ok {
+				o.rank[k] = i
+			}
+		}
+	}
+	return o
+}
+
+// kvs implements sort.Interface, ordering keys as specified by Options
+type kvs struct {
+	x    fields.Fields
+	opts *Options
+}
+
+func (s kvs) Len() int      { return len(s.x) }
+func (s kvs) Swap(i, j int) { s.x[i], s.x[j] = s.x[j], s.x[i] }
+func (s kvs) Less(i, j int) bool {
+	ri, iok := s.opts.rank[s.x[i].Key]
+	rj, jok := s.opts.rank[s.x[j].Key]
+	switch {
+	case iok && jok:
+		return ri < rj
+	case iok != jok:
+		return iok
+	}
+	return s.opts.SortKeys && s.x[i].Key < s.x[j].Key
+}
+
+func (o *Options) ordered() bool { return o.rank != nil || o.SortKeys }
+
+// format renders a set of key/value pairs
+type format struct {
+	begin, end func(b []byte) []byte
+	field      func(b []byte, i int, key string, value interface{}) []byte
+}
+
+const maxScratch = 1 << 12
+
+var (
+	scratch = sync.Pool{New: func() interface{} {
+		b := make([]byte, 0, 256)
+		return &b
+	}}
+	scratchKVs = sync.Pool{New: func() interface{} {
+		x := make(fields.Fields, 0, 16)
+		return &x
+	}}
+)
+
+// release returns pairs to the scratch pool
+func release(pp *fields.Fields, pairs fields.Fields) {
+	for i := range pairs {
+		pairs[i] = fields.Field{} // don't retain values
+	}
+	if cap(pairs) <= maxScratch {
+		*pp = pairs[:0]
+		scratchKVs.Put(pp)
+	}
+}
+
+// builtin annotations are rendered according to Options
+var builtin = map[string]bool{"time": true, "level": true, "caller": true}
+
+// marshaler expects Options to have been initialized via withDefaults
+func marshaler(opts Options, f format) encoding.Marshaler {
+	return func(c context.Context, w io.Stream, m string, a ...interface{}) (err error) {
+		var (
+			pp    = scratchKVs.Get().(*fields.Fields)
+			pairs = (*pp)[:0]
+		)
+		add := func(key string, value interface{}) {
+			if key != "" {
+				pairs = append(pairs, fields.Field{Key: key, Value: value})
+			}
+		}
+		if c != nil {
+			if ts, ok := timestamp.FromContext(c); ok {
+				add(opts.TimeKey, ts)
+			}
+			if lvl, ok := levels.FromContext(c); ok {
+				for _, lf := range opts.Levels {
+					if lf.Format != nil {
+						add(lf.Key, lf.Format(lvl))
+					}
+				}
+			}
+		}
+		add(opts.MessageKey, encoding.Message(m, a...))
+		if c != nil {
+			if x, ok := caller.FromContext(c); ok {
+				add(opts.CallerKey, x.File+":"+strconv.Itoa(x.Line))
+			}
+			if opts.Annotations {
+				for _, x := range context.Annotations(c) {
+					if !builtin[x.Name] {
+						add(x.Name, x.Value)
+					}
+				}
+			}
+			for _, x := range fields.FromContext(c) {
+				add(x.Key, x.Value)
+			}
+		}
+		out, err := pairs.Dedupe(opts.Duplicates)
+		if err != nil {
+			release(pp, pairs)
+			return w.EOM(err)
+		}
+		if opts.ordered() {
+			sort.Stable(kvs{out, &opts})
+		}
+		var (
+			bp = scratch.Get().(*[]byte)
+			b  = f.begin((*bp)[:0])
+		)
+		for i := range out {
+			b = f.field(b, i, out[i].Key, out[i].Value)
+		}
+		release(pp, pairs)
+		b = f.end(b)
+		_, err = w.Write(b)
+		if cap(b) <= maxScratch {
+			*bp = b[:0]
+			scratch.Put(bp)
+		}
+		return w.EOM(err)
+	}
+}
+
+// appendTime renders timestamps as UTC, RFC 3339 with nanoseconds
+func appendTime(b []byte, t time.Time) []byte {
+	return t.UTC().AppendFormat(b, time.RFC3339Nano)
+}
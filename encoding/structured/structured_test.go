@@ -0,0 +1,215 @@
+/*
+Copyright 2016 James DeFelice
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package structured_test
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gologs/log/context"
+	"github.com/gologs/log/context/fields"
+	"github.com/gologs/log/context/requestid"
+	"github.com/gologs/log/context/timestamp"
+	"github.com/gologs/log/encoding"
+	. "github.com/gologs/log/encoding/structured"
+	"github.com/gologs/log/io"
+	"github.com/gologs/log/levels"
+)
+
+func marshal(t *testing.T, m encoding.Marshaler, c context.Context, msg string, a ...interface{}) string {
+	t.Helper()
+	var (
+		capture string
+		s       = &io.BufferedStream{EOMFunc: func(b io.Buffer, err error) error {
+			capture = b.String()
+			return err
+		}}
+	)
+	if err := m(c, s, msg, a...); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return capture
+}
+
+func testContext() context.Context {
+	return fields.NewContext(
+		levels.NewContext(
+			timestamp.NewContext(context.Background(), time.Date(2016, 1, 2, 15, 4, 5, 0, time.UTC)),
+			levels.Warn),
+		fields.F("user", "bob smith"), fields.F("n", 3), fields.F("err", errors.New(`bad "x"`)))
+}
+
+func TestMarshalers(t *testing.T) {
+	c := testContext()
+	for i, tc := range []struct {
+		m        encoding.Marshaler
+		expected string
+	}{
+		{
+			JSON(Options{}),
+			`{"time":"2016-01-02T15:04:05Z","level":"warn","msg":"hello 1","user":"bob smith","n":3,"err":"bad \"x\""}`,
+		},
+		{
+			Logfmt(Options{}),
+			`time=2016-01-02T15:04:05Z level=warn msg="hello 1" user="bob smith" n=3 err="bad \"x\""`,
+		},
+		{
+			JSON(Options{TimeKey: "-", Levels: []LevelField{{"lvl", LevelCode()}, {"severity", LevelSyslog()}}}),
+			`{"lvl":"W","severity":4,"msg":"hello 1","user":"bob smith","n":3,"err":"bad \"x\""}`,
+		},
+		{
+			Logfmt(Options{TimeKey: "-", MessageKey: "message", Levels: []LevelField{
+				{"level", LevelMap(map[levels.Level]interface{}{levels.Warn: "WARNING"})}}}),
+			`level=WARNING message="hello 1" user="bob smith" n=3 err="bad \"x\""`,
+		},
+	} {
+		if got := marshal(t, tc.m, c, "hello %d", 1); got != tc.expected {
+			t.Errorf("test case %d: expected %s instead of %s", i, tc.expected, got)
+		}
+	}
+}
+
+func TestRegistered(t *testing.T) {
+	for _, name := range []string{"json", "logfmt"} {
+		if _, ok := encoding.Lookup(name); !ok {
+			t.Errorf("expected %q encoding to be registered", name)
+		}
+	}
+}
+
+func TestOrder(t *testing.T) {
+	c := testContext()
+	for i, tc := range []struct {
+		opts     Options
+		expected string
+	}{
+		{Options{SortKeys: true}, `err="bad \"x\"" level=warn msg=hello n=3 time=2016-01-02T15:04:05Z user="bob smith"`},
+		{Options{Order: []string{"msg", "user"}}, `msg=hello user="bob smith" time=2016-01-02T15:04:05Z level=warn n=3 err="bad \"x\""`},
+		{Options{Order: []string{"level", "msg"}, SortKeys: true}, `level=warn msg=hello err="bad \"x\"" n=3 time=2016-01-02T15:04:05Z user="bob smith"`},
+	} {
+		if got := marshal(t, Logfmt(tc.opts), c, "hello"); got != tc.expected {
+			t.Errorf("test case %d: expected %s instead of %s", i, tc.expected, got)
+		}
+	}
+}
+
+type account struct {
+	ID     int               `json:"id"`
+	Tags   []string          `json:"tags,omitempty"`
+	Attrs  map[string]string `json:"attrs"`
+	Secret string            `json:"-"`
+	Owner  *account          `json:"owner,omitempty"`
+	hidden bool
+}
+
+type secret string
+
+func (s secret) MarshalLog() interface{} { return map[string]int{"len": len(s)} }
+
+func TestNested(t *testing.T) {
+	var (
+		parent = &account{ID: 1}
+		child  = account{ID: 2, Tags: []string{"a", "b"}, Attrs: map[string]string{"y": "2", "x": "1"}, Secret: "s", Owner: parent}
+		c      = fields.NewContext(context.Background(),
+			fields.F("acct", child),
+			fields.F("pw", secret("hunter2")),
+			fields.F("nums", []int{1, 2, 3, 4}),
+			fields.F("deep", [][][]int{{{1}}}),
+			fields.F("took", 1500*time.Millisecond),
+		)
+	)
+	for i, tc := range []struct {
+		m        encoding.Marshaler
+		expected string
+	}{
+		{
+			JSON(Options{}),
+			`{"msg":"m","acct":{"id":2,"tags":["a","b"],"attrs":{"x":"1","y":"2"},"owner":{"id":1,"attrs":null}},"pw":{"len":7},"nums":[1,2,3,4],"deep":[[[1]]],"took":"1.5s"}`,
+		},
+		{
+			JSON(Options{MaxDepth: 2, MaxElements: 3}),
+			`{"msg":"m","acct":{"id":2,"tags":["a","b"],"attrs":{"x":"1","y":"2"},"...":2},"pw":{"len":7},"nums":[1,2,3,"..."],"deep":[["..."]],"took":"1.5s"}`,
+		},
+		{
+			Logfmt(Options{}),
+			`msg=m acct="{\"id\":2,\"tags\":[\"a\",\"b\"],\"attrs\":{\"x\":\"1\",\"y\":\"2\"},\"owner\":{\"id\":1,\"attrs\":null}}" pw="{\"len\":7}" nums=[1,2,3,4] deep=[[[1]]] took=1.5s`,
+		},
+	} {
+		if got := marshal(t, tc.m, c, "m"); got != tc.expected {
+			t.Errorf("test case %d: expected %s instead of %s", i, tc.expected, got)
+		}
+	}
+}
+
+func TestDuplicates(t *testing.T) {
+	c := fields.NewContext(context.Background(), fields.F("msg", "field"), fields.F("a", 1), fields.F("a", 2))
+	for i, tc := range []struct {
+		p        fields.Policy
+		expected string
+	}{
+		{fields.LastWins, `msg=field a=2`},
+		{fields.SuffixDuplicates, `msg_dup=hello msg=field a_dup=1 a=2`},
+		{fields.KeepDuplicates, `msg=hello msg=field a=1 a=2`},
+	} {
+		if got := marshal(t, Logfmt(Options{Duplicates: tc.p}), c, "hello"); got != tc.expected {
+			t.Errorf("test case %d: expected %s instead of %s", i, tc.expected, got)
+		}
+	}
+	s := &io.BufferedStream{EOMFunc: func(_ io.Buffer, err error) error { return err }}
+	if err := Logfmt(Options{Duplicates: fields.Strict})(c, s, "hello"); err == nil {
+		t.Errorf("expected an error for duplicate keys")
+	}
+}
+
+func TestAnnotations(t *testing.T) {
+	c := requestid.NewContext(levels.NewContext(context.Background(), levels.Info), "1234")
+	if got, expected := marshal(t, JSON(Options{Annotations: true}), c, "m"), `{"level":"info","msg":"m","request_id":"1234"}`; got != expected {
+		t.Errorf("expected %s instead of %s", expected, got)
+	}
+	if got, expected := marshal(t, JSON(Options{}), c, "m"), `{"level":"info","msg":"m"}`; got != expected {
+		t.Errorf("expected %s instead of %s", expected, got)
+	}
+}
+
+func TestNilContext(t *testing.T) {
+	for _, m := range []encoding.Marshaler{
+		JSON(Options{Annotations: true}),
+		Logfmt(Options{Annotations: true}),
+	} {
+		if s := marshal(t, m, nil, "hello"); s == "" {
+			t.Fatalf("expected output for a nil context")
+		}
+	}
+}
+
+func FuzzJSON(f *testing.F) {
+	f.Add("hello", "key", "value")
+	f.Add("%d %s", "", "\x00\"\\")
+	m := JSON(Options{TimeKey: "-"})
+	f.Fuzz(func(t *testing.T, msg, key, value string) {
+		var c context.Context // nil
+		if key != "" {
+			c = fields.NewContext(c, fields.F(key, value))
+		}
+		var x map[string]interface{}
+		if err := json.Unmarshal([]byte(marshal(t, m, c, msg)), &x); err != nil {
+			t.Fatalf("invalid JSON: %v", err)
+		}
+	})
+}
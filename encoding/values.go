@@ -0,0 +1,132 @@
+/*
+Copyright 2016 James DeFelice
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package encoding
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ValueFormatter renders a value, typically that of a structured field, as a string.
+type ValueFormatter func(interface{}) string
+
+var (
+	formattersMu sync.Mutex
+	formatters   atomic.Value // formatters holds a map[reflect.Type]ValueFormatter; copy on write
+)
+
+func init() {
+	formatters.Store(map[reflect.Type]ValueFormatter{
+		reflect.TypeOf(time.Duration(0)): func(v interface{}) string { return v.(time.Duration).String() },
+		reflect.TypeOf([]byte(nil)):      Base64Bytes,
+		reflect.TypeOf(net.IP(nil)):      func(v interface{}) string { return v.(net.IP).String() },
+	})
+}
+
+// RegisterValue associates a ValueFormatter with the type of `example`, replacing any formatter
+// previously registered for the type; a nil formatter removes the registration. Encoders that
+// render the values of structured fields consult these formatters before falling back to their
+// own defaults. Built-in formatters render time.Duration as "1.2s", []byte as base64 (see
+// Base64Bytes and HexBytes), and net.IP in its canonical form.
+func RegisterValue(example interface{}, f ValueFormatter) {
+	t := reflect.TypeOf(example)
+	formattersMu.Lock()
+	defer formattersMu.Unlock()
+	var (
+		old = formatters.Load().(map[reflect.Type]ValueFormatter)
+		m   = make(map[reflect.Type]ValueFormatter, len(old)+1)
+	)
+	for k, v := range old {
+		m[k] = v
+	}
+	if f == nil {
+		delete(m, t)
+	} else {
+		m[t] = f
+	}
+	formatters.Store(m)
+}
+
+// FormatValue renders v using the ValueFormatter registered for its type, if any.
+func FormatValue(v interface{}) (string, bool) {
+	if v == nil {
+		return "", false
+	}
+	f, ok := formatters.Load().(map[reflect.Type]ValueFormatter)[reflect.TypeOf(v)]
+	if !ok {
+		return "", false
+	}
+	return f(v), true
+}
+
+// Sprint renders v using the ValueFormatter registered for its type, or else fmt.Sprint.
+func Sprint(v interface{}) string {
+	if s, ok := FormatValue(v); ok {
+		return s
+	}
+	return fmt.Sprint(v)
+}
+
+// formattedValue renders per its ValueFormatter for the %v verb, and as-is for other verbs
+type formattedValue struct {
+	v interface{}
+	f ValueFormatter
+}
+
+// Format implements fmt.Formatter
+func (x formattedValue) Format(s fmt.State, verb rune) {
+	if verb == 'v' && !s.Flag('#') && !s.Flag('+') {
+		fmt.Fprintf(s, fmt.FormatString(s, 's'), x.f(x.v))
+		return
+	}
+	fmt.Fprintf(s, fmt.FormatString(s, verb), x.v)
+}
+
+// formatArgs returns the args of a log event with the values that have a registered
+// ValueFormatter wrapped, so that Print (and the %v verb of Printf) renders them accordingly.
+// Returns `a` itself if there are no such values; otherwise a copy, so as not to modify `a`.
+func formatArgs(a []interface{}) []interface{} {
+	m := formatters.Load().(map[reflect.Type]ValueFormatter)
+	var out []interface{}
+	for i, v := range a {
+		if v == nil {
+			continue
+		}
+		if f, ok := m[reflect.TypeOf(v)]; ok {
+			if out == nil {
+				out = append([]interface{}(nil), a...)
+			}
+			out[i] = formattedValue{v, f}
+		}
+	}
+	if out == nil {
+		return a
+	}
+	return out
+}
+
+// Base64Bytes is a ValueFormatter that renders a []byte using standard base64 encoding.
+func Base64Bytes(v interface{}) string { return base64.StdEncoding.EncodeToString(v.([]byte)) }
+
+// HexBytes is a ValueFormatter that renders a []byte using hexadecimal encoding.
+func HexBytes(v interface{}) string { return hex.EncodeToString(v.([]byte)) }
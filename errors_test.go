@@ -0,0 +1,103 @@
+/*
+Copyright 2016 James DeFelice
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package log_test
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"regexp"
+	"testing"
+
+	. "github.com/gologs/log"
+	"github.com/gologs/log/config"
+	"github.com/gologs/log/context"
+	"github.com/gologs/log/context/fields"
+	"github.com/gologs/log/encoding"
+	"github.com/gologs/log/encoding/structured"
+	"github.com/gologs/log/io"
+	"github.com/gologs/log/levels"
+	"github.com/gologs/log/logger"
+)
+
+func TestErrorErr(t *testing.T) {
+	var (
+		buf      bytes.Buffer
+		oops     = errors.New("oops")
+		expected = regexp.MustCompile(`^` +
+			`\{"level":"error","msg":"100% failed","caller":"[^"]*/errors_test.go:\d+","error":"oops","n":1\}\n` +
+			`\{"level":"warn","msg":"retrying","caller":"[^"]*/errors_test.go:\d+"\}\n$`)
+	)
+	restore := config.Update(
+		config.Stream(io.TextStream(&buf)),
+		config.Marshaler(structured.JSON(structured.Options{TimeKey: "-"})))
+	defer config.Update(restore)
+
+	ErrorErr(oops, "100% failed", fields.F("n", 1))
+	WarnErr(nil, "retrying")
+	if !expected.MatchString(buf.String()) {
+		t.Fatalf("unexpected output: %s", buf.String())
+	}
+
+	// implementations of levels.Interface that don't support InterfaceErr render the error and
+	// fields as part of the message
+	var (
+		output []string
+		logs   = struct{ levels.Interface }{config.DefaultConfig.With(config.Logger(logger.Func(
+			func(_ context.Context, m string, a ...interface{}) {
+				output = append(output, encoding.Message(m, a...))
+			})))}
+	)
+	levels.Err(logs).ErrorErr(oops, "100% failed", fields.F("n", 1))
+	if expected := []string{"100% failed: oops n=1"}; !reflect.DeepEqual(expected, output) {
+		t.Fatalf("expected %q instead of %q", expected, output)
+	}
+}
+
+func TestInfow(t *testing.T) {
+	var (
+		buf      bytes.Buffer
+		expected = regexp.MustCompile(`^` +
+			`\{"level":"info","msg":"100% done","caller":"[^"]*/errors_test.go:\d+","user":"bob","n":2,"!BADKEY":true\}\n` +
+			`\{"level":"error","msg":"failed","caller":"[^"]*/errors_test.go:\d+","error":"oops"\}\n$`)
+	)
+	restore := config.Update(
+		config.Stream(io.TextStream(&buf)),
+		config.Marshaler(structured.JSON(structured.Options{TimeKey: "-"})))
+	defer config.Update(restore)
+
+	Infow("100% done", "user", "bob", fields.F("n", 2), true)
+	Debugw("not logged", "n", 3)
+	Errorw("failed", "error", "oops")
+	if !expected.MatchString(buf.String()) {
+		t.Fatalf("unexpected output: %s", buf.String())
+	}
+
+	// implementations of levels.Interface that don't support InterfaceW render the fields as
+	// part of the message
+	var (
+		output []string
+		logs   = struct{ levels.Interface }{config.DefaultConfig.With(config.Logger(logger.Func(
+			func(_ context.Context, m string, a ...interface{}) {
+				output = append(output, encoding.Message(m, a...))
+			})))}
+	)
+	levels.W(logs).Warnw("retrying", "attempt", 2)
+	if expected := []string{"retrying attempt=2"}; !reflect.DeepEqual(expected, output) {
+		t.Fatalf("expected %q instead of %q", expected, output)
+	}
+}
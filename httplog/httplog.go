@@ -0,0 +1,219 @@
+/*
+Copyright 2016 James DeFelice
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package httplog provides HTTP server middleware that generates access logs.
+package httplog
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/gologs/log/context/requestid"
+	"github.com/gologs/log/levels"
+)
+
+// Record describes a completed HTTP request.
+type Record struct {
+	Method    string
+	URI       string // URI is the request URI, as sent by the client
+	Proto     string
+	RemoteIP  string
+	User      string // User is the basic-auth user name, if any
+	Referer   string
+	UserAgent string
+	RequestID string
+	Start     time.Time
+	Latency   time.Duration
+	Status    int
+	Bytes     int64
+	Request   *http.Request
+}
+
+// Formatter renders a Record as a log message.
+type Formatter func(*Record) string
+
+// Default renders a Record as, for example:
+//
+//	GET /index.html 200 1234B 1.2ms 10.0.0.1
+func Default(r *Record) string {
+	return fmt.Sprintf("%s %s %d %dB %v %s", r.Method, r.URI, r.Status, r.Bytes, r.Latency, r.RemoteIP)
+}
+
+func dash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+// CommonLogFormat renders a Record per the Apache Common Log Format, for example:
+//
+//	10.0.0.1 - frank [10/Oct/2000:13:55:36 -0700] "GET /apache_pb.gif HTTP/1.0" 200 2326
+func CommonLogFormat(r *Record) string {
+	return fmt.Sprintf("%s - %s [%s] %q %d %d",
+		dash(r.RemoteIP),
+		dash(r.User),
+		r.Start.Format("02/Jan/2006:15:04:05 -0700"),
+		r.Method+" "+r.URI+" "+r.Proto,
+		r.Status,
+		r.Bytes)
+}
+
+// CombinedLogFormat renders a Record per the Apache Combined Log Format, which extends the
+// CommonLogFormat with the referer and user agent of the request.
+func CombinedLogFormat(r *Record) string {
+	return fmt.Sprintf("%s %q %q", CommonLogFormat(r), dash(r.Referer), dash(r.UserAgent))
+}
+
+// LevelByStatus logs 5xx responses at levels.Error, 4xx at levels.Warn, and everything else at
+// levels.Info.
+func LevelByStatus(status int) levels.Level {
+	switch {
+	case status >= 500:
+		return levels.Error
+	case status >= 400:
+		return levels.Warn
+	default:
+		return levels.Info
+	}
+}
+
+// Options customize the behavior of Middleware.
+type Options struct {
+	// Format renders completed requests, defaults to Default.
+	Format Formatter
+	// Level determines the log level of completed requests, defaults to LevelByStatus.
+	Level func(status int) levels.Level
+	// LogStart, when true, generates a levels.Debug log event as each request begins.
+	LogStart bool
+	// Clock tells the time, defaults to time.Now.
+	Clock func() time.Time
+}
+
+// Middleware returns a func that wraps http.Handler objects such that every request generates
+// an access log event via `i`. Log events are annotated with the request's ID, see requestid.
+func Middleware(i levels.Interface, opts Options) func(http.Handler) http.Handler {
+	if opts.Format == nil {
+		opts.Format = Default
+	}
+	if opts.Level == nil {
+		opts.Level = LevelByStatus
+	}
+	if opts.Clock == nil {
+		opts.Clock = time.Now
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var (
+				start = opts.Clock()
+				logs  = i
+				id, _ = requestid.FromRequest(r)
+				rw    = &responseWriter{ResponseWriter: w}
+			)
+			if id != "" {
+				logs = levels.WithContext(i, requestid.NewDecorator(id))
+			}
+			if opts.LogStart {
+				logs.Debugf("%s %s started", r.Method, r.RequestURI)
+			}
+
+			next.ServeHTTP(rw, r)
+
+			if rw.status == 0 {
+				rw.status = http.StatusOK
+			}
+			rec := &Record{
+				Method:    r.Method,
+				URI:       r.RequestURI,
+				Proto:     r.Proto,
+				RemoteIP:  remoteIP(r.RemoteAddr),
+				Referer:   r.Referer(),
+				UserAgent: r.UserAgent(),
+				RequestID: id,
+				Start:     start,
+				Latency:   opts.Clock().Sub(start),
+				Status:    rw.status,
+				Bytes:     rw.bytes,
+				Request:   r,
+			}
+			if u, _, ok := r.BasicAuth(); ok {
+				rec.User = u
+			}
+			logAt(logs, opts.Level(rec.Status), opts.Format(rec))
+		})
+	}
+}
+
+func remoteIP(addr string) string {
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		return host
+	}
+	return addr
+}
+
+func logAt(logs levels.Interface, lvl levels.Level, msg string) {
+	switch lvl {
+	case levels.Debug:
+		logs.Debug(msg)
+	case levels.Info:
+		logs.Info(msg)
+	case levels.Warn:
+		logs.Warn(msg)
+	case levels.Error:
+		logs.Error(msg)
+	case levels.Fatal:
+		logs.Fatal(msg)
+	case levels.Panic:
+		logs.Panic(msg)
+	default:
+		logs.Info(msg)
+	}
+}
+
+// responseWriter tracks the status code and number of bytes written for a response.
+type responseWriter struct {
+	http.ResponseWriter
+	status int
+	bytes  int64
+}
+
+func (w *responseWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *responseWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	n, err := w.ResponseWriter.Write(b)
+	w.bytes += int64(n)
+	return n, err
+}
+
+// Flush implements http.Flusher, if supported by the wrapped http.ResponseWriter.
+func (w *responseWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap supports http.ResponseController
+func (w *responseWriter) Unwrap() http.ResponseWriter { return w.ResponseWriter }
@@ -0,0 +1,89 @@
+/*
+Copyright 2016 James DeFelice
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package httplog_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gologs/log/context/requestid"
+	. "github.com/gologs/log/httplog"
+	"github.com/gologs/log/levels"
+	"github.com/gologs/log/logtest"
+)
+
+func TestMiddleware(t *testing.T) {
+	var (
+		now   = time.Date(2000, time.October, 10, 13, 55, 36, 0, time.FixedZone("", -7*3600))
+		clock = func() time.Time { now = now.Add(time.Millisecond); return now }
+		rec   = logtest.NewRecorder()
+		h     = requestid.Middleware(Middleware(rec.Interface(), Options{
+			Format:   CombinedLogFormat,
+			LogStart: true,
+			Clock:    clock,
+		})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/missing" {
+				http.NotFound(w, r)
+				return
+			}
+			w.Write([]byte("hello"))
+		})))
+	)
+	r := httptest.NewRequest("GET", "/index.html", nil)
+	r.RemoteAddr = "10.0.0.1:1234"
+	r.SetBasicAuth("frank", "secret")
+	r.Header.Set("User-Agent", "test")
+	r.Header.Set(requestid.Header, "abc")
+	h.ServeHTTP(httptest.NewRecorder(), r)
+
+	if err := rec.Expect(levels.Debug, "GET /index.html started"); err != nil {
+		t.Fatal(err)
+	}
+	const expected = `10.0.0.1 - frank [10/Oct/2000:13:55:36 -0700] "GET /index.html HTTP/1.1" 200 5 "-" "test"`
+	e, ok := rec.Find(levels.Info, expected)
+	if !ok {
+		t.Fatalf("missing log event: %v", rec.Expect(levels.Info, expected))
+	}
+	if id, _ := requestid.FromContext(e.Context); id != "abc" {
+		t.Errorf("expected request ID abc instead of %q", id)
+	}
+
+	rec.Reset()
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/missing", nil))
+	if err := rec.Expect(levels.Warn, `"GET /missing HTTP/1.1" 404 19`); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDefault(t *testing.T) {
+	s := Default(&Record{
+		Method:   "GET",
+		URI:      "/",
+		Status:   500,
+		Bytes:    3,
+		Latency:  time.Millisecond,
+		RemoteIP: "10.0.0.1",
+	})
+	if s != "GET / 500 3B 1ms 10.0.0.1" {
+		t.Fatalf("unexpected output: %q", s)
+	}
+	if lvl := LevelByStatus(500); lvl != levels.Error {
+		t.Fatalf("unexpected level %v", lvl)
+	}
+}
@@ -0,0 +1,113 @@
+/*
+Copyright 2016 James DeFelice
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package io
+
+import (
+	"bytes"
+	"sync"
+	"time"
+)
+
+// BatchStream coalesces log events and writes them to the underlying stream in batches, each
+// batch as a single Write followed by EOM. A batch is written once it reaches a byte threshold,
+// when the flush interval elapses, or upon an explicit call to Flush. The write side (Write,
+// EOM) must be serialized by the caller, as for other buffering streams; Flush and Close may be
+// invoked concurrently.
+type BatchStream struct {
+	cur      bytes.Buffer // cur is the log event that's currently being written
+	s        Stream
+	maxBytes int
+	onError  func(error)
+
+	mu    sync.Mutex
+	batch bytes.Buffer
+
+	done chan struct{}
+	once sync.Once
+	wg   sync.WaitGroup
+}
+
+// Batch returns a BatchStream that writes batches of at least `maxBytes` (if positive) to `s`,
+// and flushes partial batches every `interval` (if positive). Errors encountered by interval
+// flushes are reported to `onError`, if not nil. Close should be invoked to stop the interval
+// flusher and write any remaining log events.
+func Batch(s Stream, maxBytes int, interval time.Duration, onError func(error)) *BatchStream {
+	b := &BatchStream{s: s, maxBytes: maxBytes, onError: onError, done: make(chan struct{})}
+	if interval > 0 {
+		b.wg.Add(1)
+		go b.flushEvery(interval)
+	}
+	return b
+}
+
+func (b *BatchStream) flushEvery(interval time.Duration) {
+	defer b.wg.Done()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-b.done:
+			return
+		case <-ticker.C:
+			if err := b.Flush(); err != nil && b.onError != nil {
+				b.onError(err)
+			}
+		}
+	}
+}
+
+// Write implements Stream
+func (b *BatchStream) Write(p []byte) (int, error) { return b.cur.Write(p) }
+
+// EOM implements Stream; the current log event is discarded if `err` is not nil.
+func (b *BatchStream) EOM(err error) error {
+	defer b.cur.Reset()
+	if err != nil {
+		return err
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.batch.Write(b.cur.Bytes())
+	if b.maxBytes > 0 && b.batch.Len() >= b.maxBytes {
+		return b.flushLocked()
+	}
+	return nil
+}
+
+// Flush writes all pending log events to the underlying stream.
+func (b *BatchStream) Flush() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.flushLocked()
+}
+
+func (b *BatchStream) flushLocked() error {
+	if b.batch.Len() == 0 {
+		return nil
+	}
+	defer b.batch.Reset()
+	_, err := b.s.Write(b.batch.Bytes())
+	return b.s.EOM(err)
+}
+
+// Close stops the interval flusher and then flushes pending log events. It's safe to invoke
+// Close multiple times.
+func (b *BatchStream) Close() error {
+	b.once.Do(func() { close(b.done) })
+	b.wg.Wait()
+	return b.Flush()
+}
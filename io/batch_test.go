@@ -0,0 +1,90 @@
+/*
+Copyright 2016 James DeFelice
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package io_test
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/gologs/log/io"
+)
+
+type batchRecorder struct {
+	BufferedStream
+	mu      sync.Mutex
+	batches []string
+}
+
+func newBatchRecorder() *batchRecorder {
+	r := &batchRecorder{}
+	r.EOMFunc = func(b Buffer, err error) error {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.batches = append(r.batches, b.String())
+		return err
+	}
+	return r
+}
+
+func (r *batchRecorder) Batches() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.batches...)
+}
+
+func TestBatch(t *testing.T) {
+	var (
+		r = newBatchRecorder()
+		s = Batch(r, 8, 0, nil)
+	)
+	for _, m := range []string{"abc", "def", "ghi", "jk"} {
+		s.Write([]byte(m))
+		if err := s.EOM(nil); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if expected := []string{"abcdefghi"}; !reflect.DeepEqual(expected, r.Batches()) {
+		t.Fatalf("expected %q instead of %q", expected, r.Batches())
+	}
+	if err := s.Close(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := []string{"abcdefghi", "jk"}; !reflect.DeepEqual(expected, r.Batches()) {
+		t.Fatalf("expected %q instead of %q", expected, r.Batches())
+	}
+}
+
+func TestBatch_Interval(t *testing.T) {
+	var (
+		r = newBatchRecorder()
+		s = Batch(r, 0, time.Millisecond, nil)
+	)
+	defer s.Close()
+	s.Write([]byte("foo"))
+	s.EOM(nil)
+	for i := 0; len(r.Batches()) == 0; i++ {
+		if i > 1000 {
+			t.Fatal("timed out waiting for interval flush")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if expected := []string{"foo"}; !reflect.DeepEqual(expected, r.Batches()) {
+		t.Fatalf("expected %q instead of %q", expected, r.Batches())
+	}
+}
@@ -0,0 +1,133 @@
+/*
+Copyright 2016 James DeFelice
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package io
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net"
+	"sync"
+)
+
+var (
+	// ErrClosed is returned by a DialStream for log events written after it was closed.
+	ErrClosed = errors.New("stream is closed")
+
+	// ErrNamedPipeUnsupported is returned by NamedPipe on platforms other than Windows; use
+	// UnixStream instead.
+	ErrNamedPipeUnsupported = errors.New("named pipes are not supported on this platform")
+)
+
+// Dialer connects to the destination of a DialStream.
+type Dialer func() (io.WriteCloser, error)
+
+// DialStream buffers each log event and, upon EOM, writes it to a connection that's established
+// on demand: initially, and again after a write fails (for example, because a local collector
+// was restarted). A failed write is retried once over a new connection. DialStream implements
+// HealthChecker. Writes and EOMs are not safe for concurrent use, like other buffering streams,
+// but Ping, Status, and Close may be invoked concurrently with them.
+type DialStream struct {
+	buf    bytes.Buffer
+	dial   Dialer
+	mu     sync.Mutex // mu guards the fields below
+	conn   io.WriteCloser
+	err    error // err is the outcome of the most recent log event, or Ping
+	closed bool
+}
+
+// Dial returns a DialStream that writes log events to connections established by `dial`.
+func Dial(dial Dialer) *DialStream { return &DialStream{dial: dial} }
+
+// UnixStream returns a DialStream that writes log events to the Unix domain (stream) socket at
+// `path`, for example that of a local fluent-bit or vector collector.
+func UnixStream(path string) *DialStream {
+	return Dial(func() (io.WriteCloser, error) { return net.Dial("unix", path) })
+}
+
+// Write implements Stream
+func (d *DialStream) Write(b []byte) (int, error) { return d.buf.Write(b) }
+
+// EOM implements Stream
+func (d *DialStream) EOM(err error) error {
+	defer d.buf.Reset()
+	if err != nil {
+		return err
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for attempt := 0; attempt < 2; attempt++ {
+		if err = d.connectLocked(); err != nil {
+			break
+		}
+		if _, err = d.conn.Write(d.buf.Bytes()); err == nil {
+			break
+		}
+		d.disconnectLocked()
+	}
+	d.err = err
+	return err
+}
+
+func (d *DialStream) connectLocked() (err error) {
+	switch {
+	case d.closed:
+		return ErrClosed
+	case d.conn == nil:
+		d.conn, err = d.dial()
+	}
+	return
+}
+
+func (d *DialStream) disconnectLocked() {
+	if d.conn != nil {
+		_ = d.conn.Close()
+		d.conn = nil
+	}
+}
+
+// Ping implements HealthChecker; it reconnects if there's no connection.
+func (d *DialStream) Ping() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.conn == nil || d.err != nil {
+		d.disconnectLocked()
+		d.err = d.connectLocked()
+	}
+	return d.err
+}
+
+// Status implements HealthChecker
+func (d *DialStream) Status() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.err
+}
+
+// Close closes the connection, if any; subsequent log events fail with ErrClosed.
+func (d *DialStream) Close() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.closed = true
+	d.err = ErrClosed
+	if d.conn == nil {
+		return nil
+	}
+	err := d.conn.Close()
+	d.conn = nil
+	return err
+}
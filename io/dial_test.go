@@ -0,0 +1,123 @@
+/*
+Copyright 2016 James DeFelice
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package io_test
+
+import (
+	"bytes"
+	"errors"
+	stdio "io"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	. "github.com/gologs/log/io"
+)
+
+type conn struct {
+	bytes.Buffer
+	broken bool
+}
+
+func (c *conn) Write(b []byte) (int, error) {
+	if c.broken {
+		return 0, errors.New("broken pipe")
+	}
+	return c.Buffer.Write(b)
+}
+
+func (c *conn) Close() error { return nil }
+
+func TestDial(t *testing.T) {
+	var (
+		conns   []*conn
+		refused = errors.New("connection refused")
+		fail    = true
+		s       = Dial(func() (stdio.WriteCloser, error) {
+			if fail {
+				return nil, refused
+			}
+			c := &conn{}
+			conns = append(conns, c)
+			return c, nil
+		})
+		write = func(msg string) error {
+			s.Write([]byte(msg))
+			return s.EOM(nil)
+		}
+	)
+	if err := write("a"); err != refused || s.Status() != refused {
+		t.Fatalf("expected %v instead of %v", refused, err)
+	}
+	fail = false
+	if err := s.Ping(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := write("b"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	conns[0].broken = true // reconnects, and retries
+	if err := write("c"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(conns) != 2 || conns[0].String() != "b" || conns[1].String() != "c" {
+		t.Fatalf("unexpected connections %+v", conns)
+	}
+	s.Close()
+	if err := write("d"); err != ErrClosed {
+		t.Fatalf("expected %v instead of %v", ErrClosed, err)
+	}
+}
+
+func TestUnixStream(t *testing.T) {
+	if runtime.GOOS == "windows" || runtime.GOOS == "plan9" {
+		t.Skip("unix sockets are not supported")
+	}
+	dir, err := ioutil.TempDir("", "unix")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "collector.sock")
+
+	l, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	received := make(chan []byte)
+	go func() {
+		c, err := l.Accept()
+		if err != nil {
+			return
+		}
+		b, _ := ioutil.ReadAll(c)
+		received <- b
+	}()
+
+	s := UnixStream(path)
+	s.Write([]byte("hello\n"))
+	if err := s.EOM(nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	s.Close()
+	if b := <-received; string(b) != "hello\n" {
+		t.Fatalf("unexpected output %q", b)
+	}
+}
@@ -0,0 +1,103 @@
+/*
+Copyright 2016 James DeFelice
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package io
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// HealthChecker is an optional extension of Stream, typically implemented by streams that write
+// to a remote destination. Implementations must be safe for concurrent use, since health is
+// usually checked by a goroutine other than the one that's logging (see WatchHealth).
+type HealthChecker interface {
+	// Ping actively probes the destination of the stream; it returns nil if it's reachable.
+	Ping() error
+	// Status returns the error of the most recent log event (or Ping); nil if it succeeded.
+	Status() error
+}
+
+type lastError struct{ err error }
+
+// MonitoredStream is a Stream that implements HealthChecker by tracking the outcome of each log
+// event, see Monitor.
+type MonitoredStream struct {
+	Stream
+	status atomic.Value // status holds a lastError
+}
+
+// Monitor returns a MonitoredStream that passes all calls through to `s`, recording the error
+// returned by each EOM. Its Ping delegates to `s` if that's a HealthChecker, otherwise it
+// reports the Status.
+func Monitor(s Stream) *MonitoredStream {
+	m := &MonitoredStream{Stream: s}
+	m.status.Store(lastError{})
+	return m
+}
+
+// EOM implements Stream
+func (m *MonitoredStream) EOM(err error) error {
+	err = m.Stream.EOM(err)
+	m.status.Store(lastError{err})
+	return err
+}
+
+// Ping implements HealthChecker
+func (m *MonitoredStream) Ping() error {
+	hc, ok := m.Stream.(HealthChecker)
+	if !ok {
+		return m.Status()
+	}
+	err := hc.Ping()
+	m.status.Store(lastError{err})
+	return err
+}
+
+// Status implements HealthChecker
+func (m *MonitoredStream) Status() error { return m.status.Load().(lastError).err }
+
+// WatchHealth invokes Ping every `interval` and reports changes of health to `f`: the error
+// once the stream becomes unhealthy, and nil once it has recovered (for example, after
+// reconnecting). The stream is initially assumed to be healthy. It returns a func that stops
+// watching. If `interval` isn't positive then Ping is never invoked.
+func WatchHealth(hc HealthChecker, interval time.Duration, f func(error)) (stop func()) {
+	if interval <= 0 {
+		return func() {}
+	}
+	var (
+		done   = make(chan struct{})
+		once   sync.Once
+		ticker = time.NewTicker(interval)
+	)
+	go func() {
+		defer ticker.Stop()
+		healthy := true
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+			}
+			if err := hc.Ping(); (err == nil) != healthy {
+				healthy = err == nil
+				f(err)
+			}
+		}
+	}()
+	return func() { once.Do(func() { close(done) }) }
+}
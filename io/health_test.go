@@ -0,0 +1,85 @@
+/*
+Copyright 2016 James DeFelice
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package io_test
+
+import (
+	"errors"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	. "github.com/gologs/log/io"
+)
+
+func TestMonitor(t *testing.T) {
+	var (
+		oops = errors.New("oops")
+		s    = Monitor(&BufferedStream{}) // EOM returns the given error
+	)
+	if err := s.Status(); err != nil {
+		t.Fatalf("unexpected status %v", err)
+	}
+	_ = s.EOM(oops)
+	if err := s.Ping(); err != oops {
+		t.Fatalf("expected %v instead of %v", oops, err)
+	}
+	_ = s.EOM(nil)
+	if err := s.Status(); err != nil {
+		t.Fatalf("unexpected status %v", err)
+	}
+}
+
+type pinger struct {
+	results []error
+	i       int32
+}
+
+func (p *pinger) Ping() error {
+	i := int(atomic.AddInt32(&p.i, 1)) - 1
+	if i < len(p.results) {
+		return p.results[i]
+	}
+	return nil
+}
+
+func (p *pinger) Status() error { return nil }
+
+func TestWatchHealth(t *testing.T) {
+	var (
+		oops = errors.New("oops")
+		p    = &pinger{results: []error{nil, oops, oops, nil, nil, oops}}
+		ch   = make(chan error, 10)
+		stop = WatchHealth(p, time.Millisecond, func(err error) { ch <- err })
+		got  []error
+	)
+	for len(got) < 3 {
+		got = append(got, <-ch)
+	}
+	stop()
+	stop() // noop
+	if expected := []error{oops, nil, oops}; !reflect.DeepEqual(expected, got) {
+		t.Fatalf("expected %v instead of %v", expected, got)
+	}
+}
+
+func TestWatchHealth_NoInterval(t *testing.T) {
+	p := &pinger{results: []error{errors.New("oops")}}
+	stop := WatchHealth(p, 0, func(err error) { t.Errorf("unexpected health change: %v", err) })
+	time.Sleep(10 * time.Millisecond)
+	stop()
+}
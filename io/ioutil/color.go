@@ -0,0 +1,213 @@
+/*
+Copyright 2016 James DeFelice
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ioutil
+
+import (
+	stdio "io"
+	"strconv"
+	"strings"
+
+	"github.com/gologs/log/context"
+	"github.com/gologs/log/encoding"
+	"github.com/gologs/log/io"
+	"github.com/gologs/log/levels"
+)
+
+// Style is a sequence of ANSI SGR parameters, such as "1;31" (bold red). Styles may be combined
+// via With; the empty Style renders text as-is.
+type Style string
+
+// Common Style instances; see also Color256 and RGB.
+const (
+	Bold      Style = "1"
+	Dim       Style = "2"
+	Italic    Style = "3"
+	Underline Style = "4"
+	Black     Style = "30"
+	Red       Style = "31"
+	Green     Style = "32"
+	Yellow    Style = "33"
+	Blue      Style = "34"
+	Magenta   Style = "35"
+	Cyan      Style = "36"
+	White     Style = "37"
+	Gray      Style = "90" // bright black
+)
+
+// Color256 returns a Style for the foreground color `n` of the 256-color palette.
+func Color256(n uint8) Style { return Style("38;5;" + strconv.Itoa(int(n))) }
+
+// RGB returns a Style for a 24-bit ("truecolor") foreground color.
+func RGB(r, g, b uint8) Style {
+	return Style("38;2;" + strconv.Itoa(int(r)) + ";" + strconv.Itoa(int(g)) + ";" + strconv.Itoa(int(b)))
+}
+
+// With returns a Style that combines `s` with the given Styles, for example Bold.With(Red).
+func (s Style) With(more ...Style) Style {
+	p := make([]string, 0, len(more)+1)
+	for _, x := range append([]Style{s}, more...) {
+		if x != "" {
+			p = append(p, string(x))
+		}
+	}
+	return Style(strings.Join(p, ";"))
+}
+
+func (s Style) seq() []byte {
+	if s == "" {
+		return nil
+	}
+	return []byte("\x1b[" + string(s) + "m")
+}
+
+// DefaultColors are the ANSI SGR parameters with which Color renders log events of each level.
+var DefaultColors = map[levels.Level]string{
+	levels.Debug: string(Gray),
+	levels.Info:  "",
+	levels.Warn:  string(Yellow),
+	levels.Error: string(Red),
+	levels.Fatal: string(Bold.With(Red)),
+	levels.Panic: string(Bold.With(Magenta)),
+}
+
+// Theme determines the styles with which log events are rendered on terminals, see Theme.Color
+// and Theme.Styled.
+type Theme struct {
+	Levels map[levels.Level]Style // Levels style entire log events, by level
+	Time   Style                  // Time styles timestamps, see Theme.Styled
+}
+
+// DefaultTheme renders log events in DefaultColors, with dim timestamps.
+var DefaultTheme = Theme{
+	Levels: map[levels.Level]Style{
+		levels.Debug: Gray,
+		levels.Warn:  Yellow,
+		levels.Error: Red,
+		levels.Fatal: Bold.With(Red),
+		levels.Panic: Bold.With(Magenta),
+	},
+	Time: Dim,
+}
+
+// For returns the Theme if `w` is a terminal that renders colors (see io.EnableColor, which it
+// invokes, so that Windows consoles render colors too), otherwise the zero Theme, which renders
+// log events as-is.
+func (t Theme) For(w stdio.Writer) Theme {
+	if !io.EnableColor(w) {
+		return Theme{}
+	}
+	return t
+}
+
+func (t Theme) seqs() map[levels.Level][]byte {
+	seqs := make(map[levels.Level][]byte, len(t.Levels))
+	for lvl, s := range t.Levels {
+		if s != "" {
+			seqs[lvl] = s.seq()
+		}
+	}
+	return seqs
+}
+
+var colorReset = []byte("\x1b[0m")
+
+// colorStream resets the color of a log event upon EOM
+type colorStream struct{ io.Stream }
+
+func (s colorStream) EOM(err error) error {
+	if err == nil {
+		_, err = s.Stream.Write(colorReset)
+	}
+	return s.Stream.EOM(err)
+}
+
+// Color returns an encoding.Decorator that renders log events in the styles of their levels.
+// Levels without a style are rendered as-is.
+func (t Theme) Color() encoding.Decorator {
+	seqs := t.seqs()
+	if len(seqs) == 0 {
+		return encoding.NoDecorator()
+	}
+	return func(op encoding.Marshaler) encoding.Marshaler {
+		return func(c context.Context, w io.Stream, m string, a ...interface{}) error {
+			lvl, _ := levels.FromContext(c)
+			seq, ok := seqs[lvl]
+			if !ok {
+				return op(c, w, m, a...)
+			}
+			if _, err := w.Write(seq); err != nil {
+				return w.EOM(err)
+			}
+			return op(c, colorStream{w}, m, a...)
+		}
+	}
+}
+
+// Styled returns an encoding.Decorator that renders the prefix written by `d` (for example, a
+// timestamp) in Style `s`, after which the style of the level (see Theme.Color) is restored.
+// It should be listed before the Theme.Color decorator, so that it's invoked after it.
+func (t Theme) Styled(s Style, d encoding.Decorator) encoding.Decorator {
+	if s == "" {
+		return d
+	}
+	var (
+		seq  = s.seq()
+		seqs = t.seqs()
+	)
+	return func(op encoding.Marshaler) encoding.Marshaler {
+		restore := d(func(c context.Context, w io.Stream, m string, a ...interface{}) error {
+			_, err := w.Write(colorReset)
+			if lvl, ok := levels.FromContext(c); ok && err == nil && seqs[lvl] != nil {
+				_, err = w.Write(seqs[lvl])
+			}
+			if err != nil {
+				return w.EOM(err)
+			}
+			return op(c, w, m, a...)
+		})
+		return func(c context.Context, w io.Stream, m string, a ...interface{}) error {
+			if _, err := w.Write(seq); err != nil {
+				return w.EOM(err)
+			}
+			return restore(c, w, m, a...)
+		}
+	}
+}
+
+// Color returns an encoding.Decorator that renders log events in the colors of their levels,
+// given as ANSI SGR parameters such as "31" (red); nil selects DefaultColors. Levels without a
+// color (or with an empty one) are rendered as-is. Use it for terminals only, see
+// io.IsTerminal, or else ColorFor. See Theme for finer control.
+func Color(colors map[levels.Level]string) encoding.Decorator {
+	if colors == nil {
+		colors = DefaultColors
+	}
+	t := Theme{Levels: make(map[levels.Level]Style, len(colors))}
+	for lvl, p := range colors {
+		t.Levels[lvl] = Style(p)
+	}
+	return t.Color()
+}
+
+// ColorFor is like Color if `w` is a terminal that renders colors, see io.EnableColor (which it
+// invokes, so that Windows consoles render colors too); otherwise log events are rendered as-is.
+func ColorFor(w stdio.Writer, colors map[levels.Level]string) encoding.Decorator {
+	if !io.EnableColor(w) {
+		return encoding.NoDecorator()
+	}
+	return Color(colors)
+}
@@ -18,8 +18,10 @@ package ioutil
 
 import (
 	"github.com/gologs/log/context"
+	"github.com/gologs/log/context/requestid"
 	"github.com/gologs/log/context/timestamp"
 	"github.com/gologs/log/encoding"
+	"github.com/gologs/log/io"
 	"github.com/gologs/log/levels"
 )
 
@@ -27,39 +29,49 @@ import (
 // header to every log message. Requires call tracking to be enabled.
 func GlogHeader() encoding.Decorator {
 	//TODO(jdef) obviously this isn't done yet. The point is not to emulate everything in glog or
-	// any other system. The goal is to ensure that we can (somewhat) efficiently chain prefixes
-	// when neeed using Iterable.
-	var (
-		buf = make(buffer, 20)
-		sp  = []byte(" ")
-	)
-	return encoding.Prefix(func(c context.Context) encoding.Iterable {
-		lvl := level(c)
-		glogTimestamp(c, buf)
-		return encoding.NewIterable(
-			lvl, buf, sp,
-		)
+	// any other system. The goal is to ensure that we can (somewhat) efficiently chain prefixes.
+	return encoding.AppendPrefix(func(c context.Context, b []byte) []byte {
+		b = append(b, level(c)...)
+		b, _ = appendGlogTimestamp(c, b)
+		return append(b, ' ')
 	})
 }
 
-var levelCodes = map[levels.Level][]byte{
-	levels.Debug: []byte("D"),
-	levels.Info:  []byte("I"),
-	levels.Warn:  []byte("W"),
-	levels.Error: []byte("E"),
-	levels.Fatal: []byte("F"),
-	levels.Panic: []byte("P"),
-}
+var (
+	levelCodes = map[levels.Level][]byte{
+		levels.Debug: []byte("D"),
+		levels.Info:  []byte("I"),
+		levels.Warn:  []byte("W"),
+		levels.Error: []byte("E"),
+		levels.Fatal: []byte("F"),
+		levels.Panic: []byte("P"),
+	}
+
+	unknownLevel = []byte("?")
+)
 
 // Level generates a stream encoding.Prefix decorator that prepends a level code
 // label to every log message.
 func Level() encoding.Decorator {
-	return encoding.Prefix(func(c context.Context) encoding.Iterable {
-		return encoding.Singular(level(c))
+	return encoding.AppendPrefix(func(c context.Context, b []byte) []byte {
+		return append(b, level(c)...)
 	})
 }
 
-var unknownLevel = []byte("?")
+// SplitLevels returns an encoding.Decorator that diverts log events at or above `min` to `s`,
+// for example Warn and above to io.Stderr() while the configured stream is io.Stdout(). Since it
+// replaces the stream of the log event, it should be the last of the decorators (and so, the
+// first to be invoked), otherwise prefixes that were already written end up in the wrong stream.
+func SplitLevels(min levels.Level, s io.Stream) encoding.Decorator {
+	return func(op encoding.Marshaler) encoding.Marshaler {
+		return func(c context.Context, w io.Stream, m string, a ...interface{}) error {
+			if lvl, ok := levels.FromContext(c); ok && lvl.Severity() >= min.Severity() {
+				w = s
+			}
+			return op(c, w, m, a...)
+		}
+	}
+}
 
 func level(c context.Context) (result []byte) {
 	result = unknownLevel
@@ -71,61 +83,93 @@ func level(c context.Context) (result []byte) {
 	return
 }
 
+// SyslogSeverity returns the syslog severity (RFC 5424) that corresponds to the given level:
+// 7 (debug) for Debug through 1 (alert) for Panic. Unknown levels map to 5 (notice).
+func SyslogSeverity(lvl levels.Level) int {
+	switch lvl {
+	case levels.Debug:
+		return 7
+	case levels.Info:
+		return 6
+	case levels.Warn:
+		return 4
+	case levels.Error:
+		return 3
+	case levels.Fatal:
+		return 2
+	case levels.Panic:
+		return 1
+	}
+	return 5
+}
+
+// Priority generates a stream encoding.Prefix decorator that prepends a "<N>" priority prefix,
+// where N is the SyslogSeverity of the level of every log message. This is the sd-daemon
+// protocol understood by systemd-journald for services that log to stderr, see
+// https://www.freedesktop.org/software/systemd/man/sd-daemon.html
+func Priority() encoding.Decorator {
+	return encoding.AppendPrefix(func(c context.Context, b []byte) []byte {
+		lvl, _ := levels.FromContext(c)
+		return append(b, '<', digits[SyslogSeverity(lvl)], '>')
+	})
+}
+
 // Timestamp generates a stream encoding.Prefix decorator that prepends a timestamp
 // to every log message. The format of the timestamp is determined by the `layout` parameter.
-// See time.Time.Format.
+// See time.Time.Format, and FormatTimestamp for additional rendering options.
 func Timestamp(layout string) encoding.Decorator {
-	return encoding.Prefix(func(c context.Context) (it encoding.Iterable) {
-		if ts, ok := timestamp.FromContext(c); ok {
-			it = encoding.Singular([]byte(ts.Format(layout)))
-		}
-		return
-	})
+	return FormatTimestamp(Layout(layout))
 }
 
 // String generates a stream encoding.Prefix decorator that prepends the given string to every
 // log message.
 func String(s string) encoding.Decorator {
-	b := []byte(s)
-	return encoding.Prefix(func(c context.Context) encoding.Iterable { return encoding.Singular(b) })
+	return encoding.AppendPrefix(func(_ context.Context, b []byte) []byte {
+		return append(b, s...)
+	})
 }
 
 // GlogTimestamp generates a stream encoding.Prefix decorator that prepends a timestamp
 // to every log message in the "glog" format.
 // see https://github.com/golang/glog/
 func GlogTimestamp() encoding.Decorator {
-	// the formatting of this implemented was copy/pasted/hacked from the glog project
-	buf := make(buffer, 20)
-	return encoding.Prefix(func(c context.Context) (it encoding.Iterable) {
-		if glogTimestamp(c, buf) {
-			it = encoding.Singular(buf)
-		}
-		return
+	return encoding.AppendPrefix(func(c context.Context, b []byte) []byte {
+		b, _ = appendGlogTimestamp(c, b)
+		return b
 	})
 }
 
-func glogTimestamp(c context.Context, buf buffer) bool {
+// appendGlogTimestamp appends the timestamp of the context, if any, to b.
+func appendGlogTimestamp(c context.Context, b []byte) ([]byte, bool) {
+	const width = 20
 	ts, ok := timestamp.FromContext(c)
-	if ok {
-		// Avoid Fprintf, for speed. The format is so simple that we can do it quickly by hand.
-		// It's worth about 3X. Fprintf is hard.
-		var (
-			_, month, day        = ts.Date()
-			hour, minute, second = ts.Clock()
-		)
-		// mmdd hh:mm:ss.uuuuuu
-		buf.twoDigits(0, int(month))
-		buf.twoDigits(2, day)
-		buf[4] = ' '
-		buf.twoDigits(5, hour)
-		buf[7] = ':'
-		buf.twoDigits(8, minute)
-		buf[10] = ':'
-		buf.twoDigits(11, second)
-		buf[13] = '.'
-		buf.nDigits(6, 14, ts.Nanosecond()/1000, '0')
+	if !ok {
+		return b, false
+	}
+	n := len(b)
+	for i := 0; i < width; i++ {
+		b = append(b, 0)
 	}
-	return ok
+	buf := buffer(b[n:])
+	// the formatting of this implemented was copy/pasted/hacked from the glog project
+	// Avoid Fprintf, for speed. The format is so simple that we can do it quickly by hand.
+	// It's worth about 3X. Fprintf is hard.
+	var (
+		_, month, day        = ts.Date()
+		hour, minute, second = ts.Clock()
+	)
+	// mmdd hh:mm:ss.uuuuuu
+	buf.twoDigits(0, int(month))
+	buf.twoDigits(2, day)
+	buf[4] = ' '
+	buf.twoDigits(5, hour)
+	buf[7] = ':'
+	buf.twoDigits(8, minute)
+	buf[10] = ':'
+	buf.twoDigits(11, second)
+	buf[13] = '.'
+	buf.nDigits(6, 14, ts.Nanosecond()/1000, '0')
+	return b, true
 }
 
 // buffer and related helper funcs were copied the glog project
@@ -155,3 +199,14 @@ func (buf buffer) nDigits(n, i, d int, pad byte) {
 		buf[i+j] = pad
 	}
 }
+
+// RequestID generates a stream encoding.Prefix decorator that prepends the request ID, if any,
+// found in the context of a log message, for example "[1234] ". See requestid.FromContext.
+func RequestID() encoding.Decorator {
+	return encoding.AppendPrefix(func(c context.Context, b []byte) []byte {
+		if id, ok := requestid.FromContext(c); ok {
+			b = append(append(append(b, '['), id...), ']', ' ')
+		}
+		return b
+	})
+}
@@ -0,0 +1,122 @@
+/*
+Copyright 2016 James DeFelice
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ioutil_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/gologs/log/context"
+	"github.com/gologs/log/encoding"
+	"github.com/gologs/log/io"
+	. "github.com/gologs/log/io/ioutil"
+	"github.com/gologs/log/levels"
+)
+
+func TestPriority(t *testing.T) {
+	for i, tc := range []struct {
+		c        context.Context
+		expected string
+	}{
+		{levels.NewContext(context.Background(), levels.Debug), "<7>msg"},
+		{levels.NewContext(context.Background(), levels.Info), "<6>msg"},
+		{levels.NewContext(context.Background(), levels.Warn), "<4>msg"},
+		{levels.NewContext(context.Background(), levels.Error), "<3>msg"},
+		{levels.NewContext(context.Background(), levels.Fatal), "<2>msg"},
+		{levels.NewContext(context.Background(), levels.Panic), "<1>msg"},
+		{context.Background(), "<5>msg"},
+	} {
+		var (
+			capture string
+			s       = &io.BufferedStream{EOMFunc: func(b io.Buffer, err error) error {
+				capture = b.String()
+				return err
+			}}
+		)
+		if err := encoding.Format(Priority())(tc.c, s, "msg"); err != nil {
+			t.Fatalf("test case %d: unexpected error: %v", i, err)
+		}
+		if capture != tc.expected {
+			t.Errorf("test case %d: expected %q instead of %q", i, tc.expected, capture)
+		}
+	}
+}
+
+func TestSplitLevels(t *testing.T) {
+	var (
+		out, errs bytes.Buffer
+		op        = encoding.Format(Level(), SplitLevels(levels.Warn, io.TextStream(&errs)))
+		s         = io.TextStream(&out)
+	)
+	for _, lvl := range []levels.Level{levels.Debug, levels.Info, levels.Warn, levels.Error} {
+		op(levels.NewContext(context.Background(), lvl), s, "msg")
+	}
+	if expected := "Dmsg\nImsg\n"; out.String() != expected {
+		t.Errorf("expected %q instead of %q", expected, out.String())
+	}
+	if expected := "Wmsg\nEmsg\n"; errs.String() != expected {
+		t.Errorf("expected %q instead of %q", expected, errs.String())
+	}
+}
+
+func TestColor(t *testing.T) {
+	var (
+		buf bytes.Buffer
+		op  = encoding.Format(Level(), Color(nil))
+		s   = io.TextStream(&buf)
+	)
+	for _, lvl := range []levels.Level{levels.Info, levels.Error} {
+		op(levels.NewContext(context.Background(), lvl), s, "msg")
+	}
+	if expected := "Imsg\n\x1b[31mEmsg\x1b[0m\n"; buf.String() != expected {
+		t.Errorf("expected %q instead of %q", expected, buf.String())
+	}
+}
+
+func TestColorFor(t *testing.T) {
+	var (
+		buf bytes.Buffer
+		op  = encoding.Format(Level(), ColorFor(&buf, nil))
+	)
+	op(levels.NewContext(context.Background(), levels.Error), io.TextStream(&buf), "msg")
+	if expected := "Emsg\n"; buf.String() != expected {
+		t.Errorf("expected %q instead of %q", expected, buf.String())
+	}
+}
+
+func TestTheme(t *testing.T) {
+	var (
+		buf   bytes.Buffer
+		theme = Theme{
+			Levels: map[levels.Level]Style{levels.Error: Bold.With(RGB(255, 0, 0))},
+			Time:   Color256(244),
+		}
+		op = encoding.Format(String(" "), Level(), theme.Styled(theme.Time, String("12:00")), theme.Color())
+		s  = io.TextStream(&buf)
+	)
+	for _, lvl := range []levels.Level{levels.Info, levels.Error} {
+		op(levels.NewContext(context.Background(), lvl), s, "msg")
+	}
+	expected := "\x1b[38;5;244m12:00\x1b[0mI msg\n" +
+		"\x1b[1;38;2;255;0;0m\x1b[38;5;244m12:00\x1b[0m\x1b[1;38;2;255;0;0mE msg\x1b[0m\n"
+	if buf.String() != expected {
+		t.Errorf("expected %q instead of %q", expected, buf.String())
+	}
+	if theme.For(&buf).Levels != nil {
+		t.Errorf("expected the zero Theme for a non-terminal")
+	}
+}
@@ -0,0 +1,101 @@
+/*
+Copyright 2016 James DeFelice
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ioutil
+
+import (
+	"strconv"
+
+	"github.com/gologs/log/context"
+	"github.com/gologs/log/context/fields"
+	"github.com/gologs/log/encoding"
+)
+
+// maxSDName is the maximum length of an RFC 5424 SD-NAME
+const maxSDName = 32
+
+// StructuredData generates a stream encoding.Prefix decorator that prepends an RFC 5424
+// structured-data element, followed by a space, to every log message. The element is identified
+// by `sdID` (for example "exampleSDID@32473") and carries the fields found in the context of the
+// log message (see fields.FromContext), duplicates resolved via fields.LastWins:
+//
+//	[exampleSDID@32473 iut="3" eventSource="Application"]
+//
+// Parameter values are escaped as required by RFC 5424; characters that aren't permitted in
+// parameter names are replaced with '_'. If there are no fields then the NILVALUE "-" is
+// rendered instead.
+func StructuredData(sdID string) encoding.Decorator {
+	id := sdName(nil, sdID)
+	return encoding.AppendPrefix(func(c context.Context, b []byte) []byte {
+		ff, _ := fields.FromContext(c).Dedupe(fields.LastWins)
+		if len(ff) == 0 {
+			return append(b, '-', ' ')
+		}
+		b = append(append(b, '['), id...)
+		for _, f := range ff {
+			b = append(sdName(append(b, ' '), f.Key), '=', '"')
+			b = append(sdValue(b, f.Value), '"')
+		}
+		return append(b, ']', ' ')
+	})
+}
+
+// sdName appends an SD-NAME: 1-32 printable US-ASCII chars, except '=', ' ', ']', and '"'
+func sdName(b []byte, s string) []byte {
+	if s == "" {
+		return append(b, '_')
+	}
+	if len(s) > maxSDName {
+		s = s[:maxSDName]
+	}
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c <= ' ' || c > '~' || c == '=' || c == ']' || c == '"':
+			b = append(b, '_')
+		default:
+			b = append(b, c)
+		}
+	}
+	return b
+}
+
+// sdValue appends a PARAM-VALUE, escaping '"', '\', and ']' with a backslash
+func sdValue(b []byte, v interface{}) []byte {
+	var s string
+	switch x := v.(type) {
+	case string:
+		s = x
+	case int:
+		return strconv.AppendInt(b, int64(x), 10)
+	case int64:
+		return strconv.AppendInt(b, x, 10)
+	case uint64:
+		return strconv.AppendUint(b, x, 10)
+	case bool:
+		return strconv.AppendBool(b, x)
+	default:
+		s = encoding.Sprint(v)
+	}
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; c {
+		case '"', '\\', ']':
+			b = append(b, '\\', c)
+		default:
+			b = append(b, c)
+		}
+	}
+	return b
+}
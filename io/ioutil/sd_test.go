@@ -0,0 +1,59 @@
+/*
+Copyright 2016 James DeFelice
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ioutil_test
+
+import (
+	"testing"
+
+	"github.com/gologs/log/context"
+	"github.com/gologs/log/context/fields"
+	"github.com/gologs/log/encoding"
+	"github.com/gologs/log/io"
+	. "github.com/gologs/log/io/ioutil"
+)
+
+func TestStructuredData(t *testing.T) {
+	for i, tc := range []struct {
+		ff       []fields.Field
+		expected string
+	}{
+		{nil, `- msg`},
+		{
+			[]fields.Field{fields.F("iut", 3), fields.F("eventSource", "Application")},
+			`[exampleSDID@32473 iut="3" eventSource="Application"] msg`,
+		},
+		{
+			[]fields.Field{fields.F(`a b="]`, `q"\]`), fields.F("", true)},
+			`[exampleSDID@32473 a_b___="q\"\\\]" _="true"] msg`,
+		},
+	} {
+		var (
+			capture string
+			s       = &io.BufferedStream{EOMFunc: func(b io.Buffer, err error) error {
+				capture = b.String()
+				return err
+			}}
+			c = fields.NewContext(context.Background(), tc.ff...)
+		)
+		if err := encoding.Format(StructuredData("exampleSDID@32473"))(c, s, "msg"); err != nil {
+			t.Fatalf("test case %d: unexpected error: %v", i, err)
+		}
+		if capture != tc.expected {
+			t.Errorf("test case %d: expected %q instead of %q", i, tc.expected, capture)
+		}
+	}
+}
@@ -0,0 +1,137 @@
+/*
+Copyright 2016 James DeFelice
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ioutil
+
+import (
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gologs/log/context"
+	"github.com/gologs/log/context/timestamp"
+	"github.com/gologs/log/encoding"
+)
+
+// TimeFormat renders a timestamp by appending it to the given buffer.
+type TimeFormat func([]byte, time.Time) []byte
+
+// Layout generates a TimeFormat that renders timestamps per the given layout.
+// See time.Time.Format.
+func Layout(layout string) TimeFormat {
+	return func(b []byte, t time.Time) []byte { return t.AppendFormat(b, layout) }
+}
+
+// Common TimeFormat instances.
+var (
+	RFC3339     = Layout(time.RFC3339)
+	RFC3339Nano = Layout(time.RFC3339Nano)
+)
+
+func epoch(unit time.Duration) TimeFormat {
+	return func(b []byte, t time.Time) []byte {
+		return strconv.AppendInt(b, t.UnixNano()/int64(unit), 10)
+	}
+}
+
+// EpochSeconds generates a TimeFormat that renders timestamps as the number of seconds elapsed
+// since the Unix epoch.
+func EpochSeconds() TimeFormat { return epoch(time.Second) }
+
+// EpochMillis generates a TimeFormat that renders timestamps as the number of milliseconds
+// elapsed since the Unix epoch.
+func EpochMillis() TimeFormat { return epoch(time.Millisecond) }
+
+// EpochNanos generates a TimeFormat that renders timestamps as the number of nanoseconds
+// elapsed since the Unix epoch.
+func EpochNanos() TimeFormat { return epoch(time.Nanosecond) }
+
+// UTC converts timestamps to UTC before rendering them via the given TimeFormat.
+func UTC(f TimeFormat) TimeFormat {
+	return func(b []byte, t time.Time) []byte { return f(b, t.UTC()) }
+}
+
+// Precision truncates timestamps to a multiple of `d` (for example, time.Millisecond) before
+// rendering them via the given TimeFormat. Note that layouts with a fixed number of fractional
+// digits will still render all of them.
+func Precision(d time.Duration, f TimeFormat) TimeFormat {
+	return func(b []byte, t time.Time) []byte { return f(b, t.Truncate(d)) }
+}
+
+// FormatTimestamp generates a stream encoding.Prefix decorator that prepends a timestamp
+// to every log message, rendered by the given TimeFormat.
+func FormatTimestamp(f TimeFormat) encoding.Decorator {
+	return encoding.AppendPrefix(func(c context.Context, b []byte) []byte {
+		if ts, ok := timestamp.FromContext(c); ok {
+			b = f(b, ts)
+		}
+		return b
+	})
+}
+
+// ProcessStart approximates the time at which the process started.
+var ProcessStart = time.Now()
+
+// appendSeconds renders d as seconds with microsecond precision, for example "12.000345".
+func appendSeconds(b []byte, d time.Duration) []byte {
+	if d < 0 {
+		b = append(b, '-')
+		d = -d
+	}
+	us := int64(d / time.Microsecond)
+	b = strconv.AppendInt(b, us/1e6, 10)
+	b = append(b, '.')
+	frac := us % 1e6
+	for div := int64(1e5); div > 0; div /= 10 {
+		b = append(b, byte('0'+frac/div%10))
+	}
+	return b
+}
+
+// Uptime generates a stream encoding.Prefix decorator that prepends the time elapsed between
+// `start` (for example, ProcessStart) and the timestamp of every log message, in the style of
+// dmesg: "[12.000345] ".
+func Uptime(start time.Time) encoding.Decorator {
+	return encoding.AppendPrefix(func(c context.Context, b []byte) []byte {
+		if ts, ok := timestamp.FromContext(c); ok {
+			b = append(appendSeconds(append(b, '['), ts.Sub(start)), ']', ' ')
+		}
+		return b
+	})
+}
+
+// Delta generates a stream encoding.Prefix decorator that prepends the time elapsed between
+// the timestamp of the previous log message and that of the current one, for example
+// "+0.000345 ". The first log message reports a delta of zero.
+func Delta() encoding.Decorator {
+	var (
+		mu   sync.Mutex
+		prev time.Time
+	)
+	return encoding.AppendPrefix(func(c context.Context, b []byte) []byte {
+		if ts, ok := timestamp.FromContext(c); ok {
+			mu.Lock()
+			if prev.IsZero() {
+				prev = ts
+			}
+			d := ts.Sub(prev)
+			prev = ts
+			mu.Unlock()
+			b = append(appendSeconds(append(b, '+'), d), ' ')
+		}
+		return b
+	})
+}
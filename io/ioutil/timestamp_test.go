@@ -0,0 +1,89 @@
+/*
+Copyright 2016 James DeFelice
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ioutil_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gologs/log/context"
+	"github.com/gologs/log/context/timestamp"
+	"github.com/gologs/log/encoding"
+	"github.com/gologs/log/io"
+	. "github.com/gologs/log/io/ioutil"
+)
+
+func TestFormatTimestamp(t *testing.T) {
+	var (
+		ts = time.Date(2016, time.March, 4, 5, 6, 7, 123456789, time.FixedZone("X", -3600))
+		c  = timestamp.NewContext(context.Background(), ts)
+	)
+	for i, tc := range []struct {
+		f        TimeFormat
+		expected string
+	}{
+		{RFC3339, "2016-03-04T05:06:07-01:00"},
+		{UTC(RFC3339), "2016-03-04T06:06:07Z"},
+		{UTC(RFC3339Nano), "2016-03-04T06:06:07.123456789Z"},
+		{Precision(time.Millisecond, UTC(RFC3339Nano)), "2016-03-04T06:06:07.123Z"},
+		{EpochSeconds(), "1457071567"},
+		{EpochMillis(), "1457071567123"},
+		{EpochNanos(), "1457071567123456789"},
+	} {
+		var (
+			capture string
+			s       = &io.BufferedStream{EOMFunc: func(b io.Buffer, err error) error {
+				capture = b.String()
+				return err
+			}}
+		)
+		if err := encoding.Format(FormatTimestamp(tc.f))(c, s, ""); err != nil {
+			t.Fatalf("test case %d: unexpected error: %v", i, err)
+		}
+		if capture != tc.expected {
+			t.Errorf("test case %d: expected %q instead of %q", i, tc.expected, capture)
+		}
+	}
+}
+
+func TestUptimeAndDelta(t *testing.T) {
+	var (
+		start   = time.Date(2016, time.March, 4, 5, 6, 7, 0, time.UTC)
+		capture string
+		s       = &io.BufferedStream{EOMFunc: func(b io.Buffer, err error) error {
+			capture = b.String()
+			return err
+		}}
+		m = encoding.Format(Uptime(start), Delta())
+	)
+	for i, tc := range []struct {
+		elapsed  time.Duration
+		expected string
+	}{
+		{0, "+0.000000 [0.000000] x"},
+		{1500 * time.Millisecond, "+1.500000 [1.500000] x"},
+		{12*time.Second + 345*time.Microsecond, "+10.500345 [12.000345] x"},
+	} {
+		c := timestamp.NewContext(context.Background(), start.Add(tc.elapsed))
+		if err := m(c, s, "x"); err != nil {
+			t.Fatalf("test case %d: unexpected error: %v", i, err)
+		}
+		if capture != tc.expected {
+			t.Errorf("test case %d: expected %q instead of %q", i, tc.expected, capture)
+		}
+	}
+}
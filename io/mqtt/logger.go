@@ -0,0 +1,113 @@
+/*
+Copyright 2016 James DeFelice
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mqtt
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/gologs/log/context"
+	"github.com/gologs/log/encoding"
+	"github.com/gologs/log/io"
+	"github.com/gologs/log/logger"
+)
+
+// Topic generates the topic of a log event from its context.
+type Topic func(context.Context) string
+
+// StaticTopic returns a Topic that always generates `topic`.
+func StaticTopic(topic string) Topic { return func(context.Context) string { return topic } }
+
+// TopicTemplate returns a Topic that expands the placeholders of the template, such as
+// "{level}" in "devices/42/logs/{level}", with the values of the registered annotations of the
+// same name (see context.RegisterField). Missing annotations expand to "-". Characters of the
+// values that are special to MQTT topics ('/', '+', '#') are replaced with '_'.
+func TopicTemplate(template string) Topic {
+	var (
+		lits  []string // lits[i] precedes names[i]; the final literal follows the last name
+		names []string
+		rest  = template
+	)
+	for {
+		i := strings.IndexByte(rest, '{')
+		j := strings.IndexByte(rest[i+1:], '}')
+		if i < 0 || j < 0 {
+			break
+		}
+		lits = append(lits, rest[:i])
+		names = append(names, rest[i+1:i+1+j])
+		rest = rest[i+j+2:]
+	}
+	if len(names) == 0 {
+		return StaticTopic(template)
+	}
+	lits = append(lits, rest)
+	return func(c context.Context) string {
+		var (
+			b  strings.Builder
+			aa = context.Annotations(c)
+		)
+		for i, name := range names {
+			b.WriteString(lits[i])
+			v := "-"
+			for _, a := range aa {
+				if a.Name == name {
+					v = topicEscaper.Replace(encoding.Sprint(a.Value))
+					break
+				}
+			}
+			b.WriteString(v)
+		}
+		b.WriteString(lits[len(names)])
+		return b.String()
+	}
+}
+
+var topicEscaper = strings.NewReplacer("/", "_", "+", "_", "#", "_")
+
+// publisher is a Stream that publishes each log event as a message
+type publisher struct {
+	bytes.Buffer
+	c   *Client
+	msg Message
+}
+
+func (p *publisher) EOM(err error) error {
+	defer p.Reset()
+	if err != nil {
+		return err
+	}
+	p.msg.Payload = p.Bytes()
+	err = p.c.Publish(p.msg)
+	p.msg.Payload = nil
+	return err
+}
+
+// Builder returns a logger.Builder that generates Loggers which publish each log event, as
+// rendered by the Marshaler, to the topic generated for it, with the given QoS and retain flag;
+// the Stream given to the Builder is ignored (though config requires one, for example io.Null,
+// in order to use a Builder). Errors are reported to the ErrorSink, as for
+// logger.WithStream. The generated Loggers are not safe for concurrent use, see config.Guard.
+func Builder(c *Client, topic Topic, qos byte, retain bool) logger.Builder {
+	return func(_ io.Stream, op encoding.Marshaler, errs logger.ErrorSink) logger.Logger {
+		p := &publisher{c: c, msg: Message{QoS: qos, Retain: retain}}
+		return logger.WithStream(p, func(ctx context.Context, s io.Stream, m string, a ...interface{}) error {
+			p.msg.Topic = topic(ctx)
+			return op(ctx, s, m, a...)
+		}, errs)
+	}
+}
@@ -0,0 +1,369 @@
+/*
+Copyright 2016 James DeFelice
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package mqtt publishes log events to an MQTT broker (protocol version 3.1.1), for deployments
+// where MQTT is the only uplink, such as embedded and IoT devices. Only what's needed to publish
+// is implemented: QoS 0 and 1, a last will message, and keep-alive.
+package mqtt
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	stdio "io"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/gologs/log/io"
+)
+
+// DefaultTimeout bounds connecting to the broker and awaiting acknowledgements.
+const DefaultTimeout = 10 * time.Second
+
+const (
+	typeConnect    = 1
+	typeConnack    = 2
+	typePublish    = 3
+	typePuback     = 4
+	typePingreq    = 12
+	typeDisconnect = 14
+
+	maxRemaining = 268435455 // maxRemaining is the largest remaining length of a packet
+)
+
+var (
+	// ErrQoS is returned for messages with a QoS other than 0 or 1.
+	ErrQoS = errors.New("mqtt: unsupported QoS")
+
+	errProtocol = errors.New("mqtt: protocol error")
+	errLost     = errors.New("mqtt: connection lost")
+)
+
+// ConnectError is returned when the broker refuses a connection; its value is the return code
+// of the CONNACK packet, for example 5 (not authorized).
+type ConnectError byte
+
+func (e ConnectError) Error() string {
+	return fmt.Sprintf("mqtt: connection refused, return code %d", byte(e))
+}
+
+// Message is an application message.
+type Message struct {
+	Topic   string
+	Payload []byte
+	QoS     byte // QoS is 0 (at most once) or 1 (at least once)
+	Retain  bool
+}
+
+// Config determines how a Client connects to a broker.
+type Config struct {
+	Addr     string // Addr is the host:port of the broker
+	ClientID string
+	Username string
+	Password string
+
+	// KeepAlive, if positive, is the keep-alive interval announced to the broker; the Client
+	// pings the broker as needed. A broker disconnects clients that exceed it, and then
+	// publishes their Will.
+	KeepAlive time.Duration
+
+	// Will (optional) is published by the broker should the Client disconnect ungracefully,
+	// which lets subscribers find out that a device stopped logging.
+	Will *Message
+
+	// Timeout bounds connecting to the broker and awaiting acknowledgements, defaults to
+	// DefaultTimeout.
+	Timeout time.Duration
+
+	// Dial connects to Addr, defaults to dialing TCP.
+	Dial func(addr string) (net.Conn, error)
+}
+
+// conn is a connection to the broker; its reader goroutine consumes incoming packets
+type conn struct {
+	net.Conn
+	acks      chan uint16
+	dead      chan struct{} // dead closes once the connection is lost
+	lastWrite time.Time
+}
+
+func (c *conn) read() {
+	defer close(c.dead)
+	r := bufio.NewReader(c)
+	for {
+		typ, body, err := readPacket(r)
+		if err != nil {
+			return
+		}
+		if typ>>4 == typePuback && len(body) == 2 {
+			select {
+			case c.acks <- uint16(body[0])<<8 | uint16(body[1]):
+			default:
+			}
+		}
+	}
+}
+
+// Client publishes messages to a broker. It connects on demand: initially, and again after the
+// connection is lost, at which point a failed publish is retried once. It is safe for
+// concurrent use.
+type Client struct {
+	cfg    Config
+	mu     sync.Mutex // mu guards the fields below
+	conn   *conn
+	id     uint16
+	closed bool
+	done   chan struct{}
+}
+
+// NewClient returns a Client for the given Config; it doesn't connect until the first message
+// is published. The Client should be closed upon shutdown.
+func NewClient(cfg Config) *Client {
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = DefaultTimeout
+	}
+	if cfg.Dial == nil {
+		timeout := cfg.Timeout
+		cfg.Dial = func(addr string) (net.Conn, error) { return net.DialTimeout("tcp", addr, timeout) }
+	}
+	c := &Client{cfg: cfg, done: make(chan struct{})}
+	if cfg.KeepAlive > 0 {
+		go c.keepAlive()
+	}
+	return c
+}
+
+func (c *Client) keepAlive() {
+	interval := c.cfg.KeepAlive / 2
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-c.done:
+			return
+		case <-ticker.C:
+		}
+		c.mu.Lock()
+		if c.conn != nil && time.Since(c.conn.lastWrite) >= interval {
+			if err := c.writeLocked([]byte{typePingreq << 4, 0}); err != nil {
+				c.disconnectLocked()
+			}
+		}
+		c.mu.Unlock()
+	}
+}
+
+func (c *Client) writeLocked(b []byte) error {
+	c.conn.SetWriteDeadline(time.Now().Add(c.cfg.Timeout))
+	_, err := c.conn.Write(b)
+	c.conn.lastWrite = time.Now()
+	return err
+}
+
+func (c *Client) connectLocked() error {
+	if c.closed {
+		return io.ErrClosed
+	}
+	if c.conn != nil {
+		return nil
+	}
+	nc, err := c.cfg.Dial(c.cfg.Addr)
+	if err != nil {
+		return err
+	}
+	nc.SetDeadline(time.Now().Add(c.cfg.Timeout))
+	if _, err = nc.Write(connectPacket(&c.cfg)); err == nil {
+		var (
+			typ  byte
+			body []byte
+		)
+		typ, body, err = readPacket(bufio.NewReaderSize(nc, 16))
+		switch {
+		case err != nil:
+		case typ>>4 != typeConnack || len(body) != 2:
+			err = errProtocol
+		case body[1] != 0:
+			err = ConnectError(body[1])
+		}
+	}
+	if err != nil {
+		nc.Close()
+		return err
+	}
+	nc.SetDeadline(time.Time{})
+	c.conn = &conn{Conn: nc, acks: make(chan uint16, 1), dead: make(chan struct{}), lastWrite: time.Now()}
+	go c.conn.read()
+	return nil
+}
+
+func (c *Client) disconnectLocked() {
+	if c.conn != nil {
+		c.conn.Close()
+		c.conn = nil
+	}
+}
+
+// Publish sends the message to the broker; for QoS 1 it waits for the broker to acknowledge it.
+func (c *Client) Publish(m Message) (err error) {
+	if m.QoS > 1 {
+		return ErrQoS
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for attempt := 0; attempt < 2; attempt++ {
+		if err = c.connectLocked(); err != nil {
+			return
+		}
+		if err = c.publishLocked(m); err == nil {
+			return
+		}
+		c.disconnectLocked()
+	}
+	return
+}
+
+func (c *Client) publishLocked(m Message) error {
+	var id uint16
+	if m.QoS > 0 {
+		if c.id++; c.id == 0 {
+			c.id++ // zero is not a valid packet identifier
+		}
+		id = c.id
+	}
+	if err := c.writeLocked(publishPacket(m, id)); err != nil {
+		return err
+	}
+	if m.QoS == 0 {
+		return nil
+	}
+	timer := time.NewTimer(c.cfg.Timeout)
+	defer timer.Stop()
+	for {
+		select {
+		case x := <-c.conn.acks:
+			if x == id {
+				return nil
+			}
+		case <-c.conn.dead:
+			return errLost
+		case <-timer.C:
+			return io.ErrTimeout
+		}
+	}
+}
+
+// Close disconnects gracefully from the broker (which then discards the Will); subsequent
+// messages fail with io.ErrClosed.
+func (c *Client) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return nil
+	}
+	c.closed = true
+	close(c.done)
+	if c.conn == nil {
+		return nil
+	}
+	err := c.writeLocked([]byte{typeDisconnect << 4, 0})
+	c.disconnectLocked()
+	return err
+}
+
+func appendString(b []byte, s string) []byte {
+	return append(append(b, byte(len(s)>>8), byte(len(s))), s...)
+}
+
+// packet prepends the fixed header to the body of a packet
+func packet(header byte, body []byte) []byte {
+	b := make([]byte, 0, len(body)+5)
+	b = append(b, header)
+	for n := len(body); ; {
+		x := byte(n % 128)
+		if n /= 128; n > 0 {
+			x |= 0x80
+		}
+		if b = append(b, x); n == 0 {
+			break
+		}
+	}
+	return append(b, body...)
+}
+
+func connectPacket(cfg *Config) []byte {
+	var flags byte = 0x02 // clean session
+	b := appendString(nil, "MQTT")
+	b = append(b, 4, 0, byte(cfg.KeepAlive/time.Second>>8), byte(cfg.KeepAlive/time.Second))
+	b = appendString(b, cfg.ClientID)
+	if w := cfg.Will; w != nil {
+		flags |= 0x04 | (w.QoS&0x03)<<3
+		if w.Retain {
+			flags |= 0x20
+		}
+		b = appendString(b, w.Topic)
+		b = appendString(b, string(w.Payload))
+	}
+	if cfg.Username != "" {
+		flags |= 0x80
+		b = appendString(b, cfg.Username)
+		if cfg.Password != "" {
+			flags |= 0x40
+			b = appendString(b, cfg.Password)
+		}
+	}
+	b[7] = flags
+	return packet(typeConnect<<4, b)
+}
+
+func publishPacket(m Message, id uint16) []byte {
+	header := byte(typePublish<<4) | m.QoS<<1
+	if m.Retain {
+		header |= 0x01
+	}
+	b := make([]byte, 0, len(m.Topic)+len(m.Payload)+4)
+	b = appendString(b, m.Topic)
+	if m.QoS > 0 {
+		b = append(b, byte(id>>8), byte(id))
+	}
+	return packet(header, append(b, m.Payload...))
+}
+
+func readPacket(r *bufio.Reader) (header byte, body []byte, err error) {
+	if header, err = r.ReadByte(); err != nil {
+		return
+	}
+	n, shift := 0, uint(0)
+	for {
+		var x byte
+		if x, err = r.ReadByte(); err != nil {
+			return
+		}
+		n |= int(x&0x7f) << shift
+		if x&0x80 == 0 {
+			break
+		}
+		if shift += 7; shift > 21 {
+			return 0, nil, errProtocol
+		}
+	}
+	if n > maxRemaining {
+		return 0, nil, errProtocol
+	}
+	body = make([]byte, n)
+	_, err = stdio.ReadFull(r, body)
+	return
+}
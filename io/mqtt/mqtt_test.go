@@ -0,0 +1,173 @@
+/*
+Copyright 2016 James DeFelice
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mqtt_test
+
+import (
+	"bufio"
+	stdio "io"
+	"net"
+	"reflect"
+	"testing"
+
+	"github.com/gologs/log/config"
+	"github.com/gologs/log/context/requestid"
+	"github.com/gologs/log/io"
+	. "github.com/gologs/log/io/mqtt"
+	"github.com/gologs/log/levels"
+)
+
+// broker is a fake MQTT broker that accepts connections from net.Pipe
+type broker struct {
+	connects  int
+	wills     []string
+	published []Message
+	done      chan struct{}
+}
+
+func readPacket(t *testing.T, r *bufio.Reader) (byte, []byte, bool) {
+	header, err := r.ReadByte()
+	if err != nil {
+		return 0, nil, false
+	}
+	n, shift := 0, uint(0)
+	for {
+		x, _ := r.ReadByte()
+		n |= int(x&0x7f) << shift
+		if x&0x80 == 0 {
+			break
+		}
+		shift += 7
+	}
+	body := make([]byte, n)
+	if _, err := stdio.ReadFull(r, body); err != nil {
+		return 0, nil, false
+	}
+	return header, body, true
+}
+
+func str(b []byte) (string, []byte) {
+	n := int(b[0])<<8 | int(b[1])
+	return string(b[2 : 2+n]), b[2+n:]
+}
+
+func (b *broker) dial(t *testing.T, drop int) func(string) (net.Conn, error) {
+	return func(string) (net.Conn, error) {
+		client, server := net.Pipe()
+		if b.connects++; b.connects > 1 {
+			drop = -1 // only the first connection is lost
+		}
+		go func() {
+			defer server.Close()
+			r := bufio.NewReader(server)
+			for i := 0; ; i++ {
+				header, body, ok := readPacket(t, r)
+				if !ok {
+					return
+				}
+				switch header >> 4 {
+				case 1: // CONNECT
+					flags, rest := body[7], body[10:]
+					_, rest = str(rest) // client ID
+					if flags&0x04 != 0 {
+						topic, _ := str(rest)
+						b.wills = append(b.wills, topic)
+					}
+					server.Write([]byte{0x20, 2, 0, 0})
+				case 3: // PUBLISH
+					if i == drop {
+						return // connection lost
+					}
+					var (
+						qos         = header >> 1 & 0x03
+						topic, rest = str(body)
+					)
+					m := Message{Topic: topic, QoS: qos, Retain: header&0x01 != 0}
+					if qos > 0 {
+						server.Write([]byte{0x40, 2, rest[0], rest[1]})
+						rest = rest[2:]
+					}
+					m.Payload = rest
+					b.published = append(b.published, m)
+				case 14: // DISCONNECT
+					close(b.done)
+					return
+				}
+			}
+		}()
+		return client, nil
+	}
+}
+
+func TestClient(t *testing.T) {
+	b := &broker{done: make(chan struct{})}
+	c := NewClient(Config{
+		ClientID: "device-42",
+		Will:     &Message{Topic: "devices/42/status", Payload: []byte("offline"), Retain: true},
+		Dial:     b.dial(t, 2), // the 2nd packet after CONNECT is lost, and so retried
+	})
+	for _, m := range []Message{
+		{Topic: "a", Payload: []byte("1")},
+		{Topic: "b", Payload: []byte("2"), QoS: 1},
+		{Topic: "c", Payload: []byte("3"), QoS: 1, Retain: true},
+	} {
+		if err := c.Publish(m); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if err := c.Publish(Message{QoS: 2}); err != ErrQoS {
+		t.Fatalf("expected %v instead of %v", ErrQoS, err)
+	}
+	c.Close()
+	<-b.done
+	if err := c.Publish(Message{Topic: "d"}); err != io.ErrClosed {
+		t.Fatalf("expected %v instead of %v", io.ErrClosed, err)
+	}
+	expected := []Message{
+		{Topic: "a", Payload: []byte("1")},
+		{Topic: "b", Payload: []byte("2"), QoS: 1},
+		{Topic: "c", Payload: []byte("3"), QoS: 1, Retain: true},
+	}
+	if !reflect.DeepEqual(expected, b.published) {
+		t.Fatalf("expected %+v instead of %+v", expected, b.published)
+	}
+	if b.connects != 2 || !reflect.DeepEqual(b.wills, []string{"devices/42/status", "devices/42/status"}) {
+		t.Fatalf("unexpected connects %d, wills %q", b.connects, b.wills)
+	}
+}
+
+func TestBuilder(t *testing.T) {
+	var (
+		b    = &broker{done: make(chan struct{})}
+		c    = NewClient(Config{Dial: b.dial(t, -1)})
+		logs = config.DefaultConfig.With(
+			config.Stream(io.Null()),
+			config.Builder(Builder(c, TopicTemplate("logs/{level}/{request_id}"), 1, false)),
+		)
+	)
+	logs.Infof("hello %d", 1)
+	levels.WithContext(logs, requestid.NewDecorator("a/b")).Warnf("hello %d", 2)
+	c.Close()
+	<-b.done
+
+	var got []string
+	for _, m := range b.published {
+		got = append(got, m.Topic+" "+string(m.Payload))
+	}
+	if expected := []string{"logs/info/- hello 1", "logs/warn/a_b hello 2"}; !reflect.DeepEqual(expected, got) {
+		t.Fatalf("expected %q instead of %q", expected, got)
+	}
+}
@@ -0,0 +1,29 @@
+/*
+Copyright 2016 James DeFelice
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package io
+
+import (
+	"io"
+	"os"
+)
+
+// NamedPipe returns a DialStream that writes log events to the named pipe `\\.\pipe\<name>`,
+// for example that of a local collector; the pipe is opened on demand, see DialStream.
+func NamedPipe(name string) (*DialStream, error) {
+	path := `\\.\pipe\` + name
+	return Dial(func() (io.WriteCloser, error) { return os.OpenFile(path, os.O_WRONLY, 0) }), nil
+}
@@ -0,0 +1,185 @@
+/*
+Copyright 2016 James DeFelice
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package recio converts between RecordIO log files (see io.RecordIO) and human-readable text,
+// one record per line, so that binary log files may be inspected, edited, and rebuilt.
+package recio
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	stdio "io"
+
+	"github.com/gologs/log/io"
+)
+
+// DefaultMaxRecordSize is the default size beyond which a record length is considered corrupt.
+const DefaultMaxRecordSize = 1 << 20
+
+// Options customize decoding and encoding; the zero value is ready to use.
+type Options struct {
+	// MaxRecordSize is the largest acceptable record payload, defaults to DefaultMaxRecordSize.
+	MaxRecordSize int
+	// Valid reports whether a record payload is well-formed, defaults to json.Valid. Payloads
+	// must also be free of newlines so that they fit on a single line of text.
+	Valid func([]byte) bool
+	// Corrupt (optional) is invoked for every run of `n` bytes, starting at `offset`, that were
+	// skipped while the Reader resynchronized with the record stream.
+	Corrupt func(offset, n int64)
+}
+
+func (o Options) withDefaults() Options {
+	if o.MaxRecordSize <= 0 {
+		o.MaxRecordSize = DefaultMaxRecordSize
+	}
+	if o.Valid == nil {
+		o.Valid = json.Valid
+	}
+	return o
+}
+
+func (o *Options) valid(b []byte) bool {
+	return len(b) > 0 && bytes.IndexAny(b, "\r\n") < 0 && o.Valid(b)
+}
+
+// Reader reads records from a RecordIO stream. Corrupt data (implausible lengths, truncated or
+// invalid payloads) is skipped byte by byte until a valid record is found.
+type Reader struct {
+	r       *bufio.Reader
+	opts    Options
+	offset  int64 // offset of the next unread byte
+	bad     int64 // offset of the current run of corrupt bytes
+	skipped int64 // length of the current run of corrupt bytes
+	total   int64
+}
+
+// NewReader returns a Reader that reads records from `r`.
+func NewReader(r stdio.Reader, opts Options) *Reader {
+	opts = opts.withDefaults()
+	return &Reader{
+		r:    bufio.NewReaderSize(r, opts.MaxRecordSize+binary.MaxVarintLen64),
+		opts: opts,
+	}
+}
+
+// Skipped returns the total number of corrupt bytes skipped so far.
+func (r *Reader) Skipped() int64 { return r.total + r.skipped }
+
+// Next returns the payload of the next valid record, which remains valid until the following
+// call to Next, or else io.EOF once the underlying reader is exhausted.
+func (r *Reader) Next() ([]byte, error) {
+	for {
+		hdr, err := r.r.Peek(binary.MaxVarintLen64)
+		if len(hdr) == 0 {
+			r.flush()
+			if err == nil || err == bufio.ErrBufferFull {
+				err = stdio.EOF
+			}
+			return nil, err
+		}
+		if err != nil && err != stdio.EOF {
+			return nil, err
+		}
+		if size, k := binary.Uvarint(hdr); k > 0 && size <= uint64(r.opts.MaxRecordSize) {
+			rec, err := r.r.Peek(k + int(size))
+			if err != nil && err != stdio.EOF {
+				return nil, err
+			}
+			if err == nil && r.opts.valid(rec[k:]) {
+				r.flush()
+				r.r.Discard(len(rec))
+				r.offset += int64(len(rec))
+				return rec[k:], nil
+			}
+		}
+		if r.skipped == 0 {
+			r.bad = r.offset
+		}
+		r.r.Discard(1)
+		r.offset++
+		r.skipped++
+	}
+}
+
+// flush reports the current run of corrupt bytes, if any
+func (r *Reader) flush() {
+	if r.skipped == 0 {
+		return
+	}
+	if r.opts.Corrupt != nil {
+		r.opts.Corrupt(r.bad, r.skipped)
+	}
+	r.total += r.skipped
+	r.skipped = 0
+}
+
+// Decode writes the payload of every valid record read from `r` to `w` as a line of text. It
+// returns the number of records decoded.
+func Decode(w stdio.Writer, r stdio.Reader, opts Options) (n int, err error) {
+	var (
+		rr = NewReader(r, opts)
+		bw = bufio.NewWriter(w)
+	)
+	for {
+		var rec []byte
+		if rec, err = rr.Next(); err != nil {
+			break
+		}
+		bw.Write(rec)
+		if err = bw.WriteByte('\n'); err != nil {
+			return
+		}
+		n++
+	}
+	if err == stdio.EOF {
+		err = nil
+	}
+	if ferr := bw.Flush(); err == nil {
+		err = ferr
+	}
+	return
+}
+
+// Encode writes every non-blank line of text read from `r` to `w` as a record, the inverse of
+// Decode. Lines must be valid per Options.Valid. It returns the number of records encoded.
+func Encode(w stdio.Writer, r stdio.Reader, opts Options) (n int, err error) {
+	opts = opts.withDefaults()
+	var (
+		s     = bufio.NewScanner(r)
+		rio   = io.RecordIO(w)
+		lines int
+	)
+	s.Buffer(make([]byte, 0, 4096), opts.MaxRecordSize+1)
+	for s.Scan() {
+		lines++
+		line := bytes.TrimSpace(s.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		if !opts.valid(line) {
+			return n, fmt.Errorf("recio: invalid record at line %d", lines)
+		}
+		rio.Write(line)
+		if err = rio.EOM(nil); err != nil {
+			return
+		}
+		n++
+	}
+	return n, s.Err()
+}
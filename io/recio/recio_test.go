@@ -0,0 +1,75 @@
+/*
+Copyright 2016 James DeFelice
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package recio_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	. "github.com/gologs/log/io/recio"
+)
+
+func TestRoundTrip(t *testing.T) {
+	const text = "{\"msg\":\"a\"}\n{\"msg\":\"b\",\"n\":1}\n"
+	var bin, out bytes.Buffer
+	n, err := Encode(&bin, strings.NewReader(text+"\n"), Options{})
+	if err != nil || n != 2 {
+		t.Fatalf("unexpected encode result: %d, %v", n, err)
+	}
+	n, err = Decode(&out, &bin, Options{})
+	if err != nil || n != 2 {
+		t.Fatalf("unexpected decode result: %d, %v", n, err)
+	}
+	if out.String() != text {
+		t.Fatalf("expected %q instead of %q", text, out.String())
+	}
+}
+
+func TestEncode_Invalid(t *testing.T) {
+	var bin bytes.Buffer
+	_, err := Encode(&bin, strings.NewReader("{}\nnot json\n"), Options{})
+	if err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestDecode_Resync(t *testing.T) {
+	var bin bytes.Buffer
+	if _, err := Encode(&bin, strings.NewReader("{\"msg\":\"a\"}\n{\"msg\":\"b\"}\n{\"msg\":\"c\"}\n"), Options{}); err != nil {
+		t.Fatal(err)
+	}
+	var (
+		data    = bin.Bytes()
+		corrupt = append(append(append([]byte{}, data[:12]...), "\xff\xff\x07garbage"...), data[15:]...)
+		out     bytes.Buffer
+		runs    []int64
+		opts    = Options{Corrupt: func(offset, n int64) { runs = append(runs, offset, n) }}
+	)
+	// the first record survives, the second loses its length and first bytes, the third survives
+	corrupt = append(corrupt, 0x20) // a truncated trailing record
+	n, err := Decode(&out, bytes.NewReader(corrupt), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 2 || out.String() != "{\"msg\":\"a\"}\n{\"msg\":\"c\"}\n" {
+		t.Fatalf("unexpected output (%d records): %q", n, out.String())
+	}
+	if len(runs) != 4 || runs[0] != 12 || runs[2] != int64(len(corrupt)-1) || runs[3] != 1 {
+		t.Fatalf("unexpected corrupt runs: %v", runs)
+	}
+}
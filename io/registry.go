@@ -0,0 +1,95 @@
+/*
+Copyright 2016 James DeFelice
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package io
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// SinkFactory generates a Stream given a factory-specific argument (possibly empty).
+type SinkFactory func(arg string) (Stream, error)
+
+var sinks = struct {
+	sync.RWMutex
+	factories map[string]SinkFactory
+}{factories: map[string]SinkFactory{
+	"stderr": func(string) (Stream, error) { return TextStream(os.Stderr), nil },
+	"stdout": func(string) (Stream, error) { return TextStream(os.Stdout), nil },
+	"null":   func(string) (Stream, error) { return Null(), nil },
+	"file":   openFile,
+}}
+
+func openFile(path string) (Stream, error) {
+	if path == "" {
+		return nil, fmt.Errorf("file sink requires a path")
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, err
+	}
+	return closingStream{TextStream(f), f}, nil
+}
+
+// closingStream is a Stream that may be closed, for example by the config.Build cleanup func
+type closingStream struct {
+	Stream
+	c io.Closer
+}
+
+func (s closingStream) Close() error { return s.c.Close() }
+
+// RegisterSink associates a SinkFactory with a name so that declarative configurations may refer
+// to it. Registering a name again replaces the prior SinkFactory. Built-in names are "stderr",
+// "stdout", "null", and "file" (whose argument is the path of a file to append to; its Stream
+// implements io.Closer).
+func RegisterSink(name string, f SinkFactory) {
+	sinks.Lock()
+	defer sinks.Unlock()
+	sinks.factories[name] = f
+}
+
+// OpenSink generates a Stream per the given spec, which is either a registered sink name or
+// else a name and argument separated by a colon, for example "file:/var/log/app.log".
+func OpenSink(spec string) (Stream, error) {
+	name, arg := spec, ""
+	if i := strings.IndexByte(spec, ':'); i >= 0 {
+		name, arg = spec[:i], spec[i+1:]
+	}
+	sinks.RLock()
+	f, ok := sinks.factories[name]
+	sinks.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown sink %q", name)
+	}
+	return f(arg)
+}
+
+// RegisteredSinks returns the sorted names of all registered sink factories.
+func RegisteredSinks() (names []string) {
+	sinks.RLock()
+	defer sinks.RUnlock()
+	for name := range sinks.factories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return
+}
@@ -0,0 +1,122 @@
+/*
+Copyright 2016 James DeFelice
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package io
+
+import (
+	"fmt"
+	"math/rand"
+	"time"
+)
+
+// RetryPolicy determines how Retry handles failed writes. The zero value is usable.
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of attempts per log event, defaults to 3.
+	MaxAttempts int
+	// Backoff is the delay before the first retry, defaults to 10ms; it doubles per attempt.
+	Backoff time.Duration
+	// MaxBackoff caps the delay between attempts, defaults to 1s.
+	MaxBackoff time.Duration
+	// Jitter randomizes each delay by up to the given fraction (0..1) of its length.
+	Jitter float64
+	// Budget, if positive, is the total time allowed per log event across all attempts.
+	Budget time.Duration
+	// Retryable returns true if an error is transient; defaults to treating all errors as such.
+	Retryable func(error) bool
+	// Sleep waits between attempts, defaults to time.Sleep.
+	Sleep func(time.Duration)
+}
+
+// RetryError is reported for log events that could not be written within a RetryPolicy.
+type RetryError struct {
+	Attempts int
+	Err      error // Err is the error returned by the final attempt
+}
+
+func (e *RetryError) Error() string {
+	return fmt.Sprintf("giving up after %d attempt(s): %v", e.Attempts, e.Err)
+}
+
+func (p RetryPolicy) withDefaults() RetryPolicy {
+	if p.MaxAttempts <= 0 {
+		p.MaxAttempts = 3
+	}
+	if p.Backoff <= 0 {
+		p.Backoff = 10 * time.Millisecond
+	}
+	if p.MaxBackoff <= 0 {
+		p.MaxBackoff = time.Second
+	}
+	if p.Retryable == nil {
+		p.Retryable = func(error) bool { return true }
+	}
+	if p.Sleep == nil {
+		p.Sleep = time.Sleep
+	}
+	return p
+}
+
+func (p RetryPolicy) delay(d time.Duration) time.Duration {
+	if p.Jitter > 0 {
+		d += time.Duration(rand.Float64() * p.Jitter * float64(d))
+	}
+	if d > p.MaxBackoff {
+		d = p.MaxBackoff
+	}
+	return d
+}
+
+// Retry wraps the provided stream such that each log event is locally buffered and then written
+// to the underlying stream upon EOM, retrying transient failures per the given policy. Since a
+// failed attempt may have partially written the event, the underlying stream should buffer
+// between calls to EOM (see NewBuffered). Events that could not be written are reported as a
+// *RetryError by EOM, and so to the error sink of the logger.
+func Retry(s Stream, policy RetryPolicy) Stream {
+	p := policy.withDefaults()
+	return &BufferedStream{
+		EOMFunc: func(buf Buffer, err error) error {
+			if err != nil {
+				// the marshaler failed, there's nothing worth retrying
+				return s.EOM(err)
+			}
+			var (
+				b        = []byte(buf.String())
+				backoff  = p.Backoff
+				deadline time.Time
+			)
+			if p.Budget > 0 {
+				deadline = time.Now().Add(p.Budget)
+			}
+			for attempt := 1; ; attempt++ {
+				_, err = s.Write(b)
+				if err = s.EOM(err); err == nil {
+					return nil
+				}
+				if attempt >= p.MaxAttempts || !p.Retryable(err) {
+					return &RetryError{Attempts: attempt, Err: err}
+				}
+				d := p.delay(backoff)
+				if !deadline.IsZero() && time.Now().Add(d).After(deadline) {
+					return &RetryError{Attempts: attempt, Err: err}
+				}
+				p.Sleep(d)
+				if backoff *= 2; backoff > p.MaxBackoff {
+					backoff = p.MaxBackoff
+				}
+			}
+		},
+	}
+}
@@ -0,0 +1,94 @@
+/*
+Copyright 2016 James DeFelice
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package io_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	. "github.com/gologs/log/io"
+)
+
+// flaky fails the first `failures` events and records the rest
+type flaky struct {
+	BufferedStream
+	failures int
+	output   []string
+}
+
+func newFlaky(failures int) *flaky {
+	f := &flaky{failures: failures}
+	f.EOMFunc = func(b Buffer, err error) error {
+		if err != nil {
+			return err
+		}
+		if f.failures > 0 {
+			f.failures--
+			return errors.New("transient")
+		}
+		f.output = append(f.output, b.String())
+		return nil
+	}
+	return f
+}
+
+func TestRetry(t *testing.T) {
+	var (
+		sleeps []time.Duration
+		policy = RetryPolicy{
+			MaxAttempts: 4,
+			Backoff:     time.Millisecond,
+			MaxBackoff:  3 * time.Millisecond,
+			Sleep:       func(d time.Duration) { sleeps = append(sleeps, d) },
+		}
+		f = newFlaky(3)
+		s = Retry(f, policy)
+	)
+	s.Write([]byte("hello"))
+	if err := s.EOM(nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := []string{"hello"}; !reflect.DeepEqual(expected, f.output) {
+		t.Fatalf("expected %q instead of %q", expected, f.output)
+	}
+	if expected := []time.Duration{time.Millisecond, 2 * time.Millisecond, 3 * time.Millisecond}; !reflect.DeepEqual(expected, sleeps) {
+		t.Fatalf("expected backoff %v instead of %v", expected, sleeps)
+	}
+
+	// give up
+	f = newFlaky(10)
+	s = Retry(f, policy)
+	s.Write([]byte("hello"))
+	err := s.EOM(nil)
+	if re, ok := err.(*RetryError); !ok || re.Attempts != 4 {
+		t.Fatalf("expected RetryError after 4 attempts instead of %#v", err)
+	}
+	if len(f.output) != 0 {
+		t.Fatalf("unexpected output %q", f.output)
+	}
+
+	// permanent errors aren't retried
+	f = newFlaky(1)
+	policy.Retryable = func(error) bool { return false }
+	s = Retry(f, policy)
+	s.Write([]byte("hello"))
+	if re, ok := s.EOM(nil).(*RetryError); !ok || re.Attempts != 1 {
+		t.Fatalf("expected RetryError after 1 attempt instead of %#v", re)
+	}
+}
@@ -0,0 +1,266 @@
+/*
+Copyright 2016 James DeFelice
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package io
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+)
+
+// The ring file layout is a fixed-size header followed by a circular data region. The header
+// records the data region's capacity, the offsets of the next write (head) and of the oldest
+// record (tail), the number of bytes in use, and the last sequence number. Each record is
+// a uint32 length, a uint64 sequence number, and then the record data. A length of ringPad, or
+// fewer than ringRecordHeader bytes remaining before the end of the data region, indicates that
+// the next record begins at the start of the data region.
+const (
+	ringMagic        = "GOLOGRNG"
+	ringHeaderSize   = 64
+	ringRecordHeader = 12
+	ringPad          = ^uint32(0)
+
+	offCapacity = 8
+	offHead     = 16
+	offTail     = 24
+	offUsed     = 32
+	offSeq      = 40
+)
+
+var (
+	// ErrRingUnsupported is returned by OpenRing on platforms that don't support memory mapping.
+	ErrRingUnsupported = errors.New("ring streams are not supported on this platform")
+
+	errBadRing     = errors.New("not a ring file")
+	errCorruptRing = errors.New("corrupt ring file header")
+)
+
+// RingRecord is a log event retained by a ring file.
+type RingRecord struct {
+	Seq  uint64
+	Data []byte
+}
+
+// ring implements the circular buffer over some region of memory (usually, a mapped file)
+type ring struct {
+	mem  []byte // mem includes the header
+	data []byte
+}
+
+func newRing(mem []byte, init bool) (*ring, error) {
+	r := &ring{mem: mem, data: mem[ringHeaderSize:]}
+	if init {
+		copy(mem, ringMagic)
+		r.put(offCapacity, uint64(len(r.data)))
+		for _, off := range []int{offHead, offTail, offUsed, offSeq} {
+			r.put(off, 0)
+		}
+		return r, nil
+	}
+	if string(mem[:len(ringMagic)]) != ringMagic || r.get(offCapacity) != uint64(len(r.data)) {
+		return nil, errBadRing
+	}
+	// the offsets are read back from a file that may have been truncated or clobbered
+	if c := r.capacity(); r.get(offHead) >= c || r.get(offTail) >= c || r.get(offUsed) > c {
+		return nil, errCorruptRing
+	}
+	return r, nil
+}
+
+func (r *ring) get(off int) uint64      { return binary.BigEndian.Uint64(r.mem[off:]) }
+func (r *ring) put(off int, v uint64)   { binary.BigEndian.PutUint64(r.mem[off:], v) }
+func (r *ring) capacity() uint64        { return uint64(len(r.data)) }
+func (r *ring) padAt(pos uint64) uint64 { return r.capacity() - pos }
+
+// isPad returns true if the data region wraps around at pos
+func (r *ring) isPad(pos uint64) bool {
+	return r.capacity()-pos < ringRecordHeader || binary.BigEndian.Uint32(r.data[pos:]) == ringPad
+}
+
+// sizeAt returns the size of the record (or padding) at pos
+func (r *ring) sizeAt(pos uint64) uint64 {
+	if r.isPad(pos) {
+		return r.padAt(pos)
+	}
+	return ringRecordHeader + uint64(binary.BigEndian.Uint32(r.data[pos:]))
+}
+
+func (r *ring) ensureFree(n uint64) {
+	for tail, used := r.get(offTail), r.get(offUsed); r.capacity()-used < n; {
+		sz := r.sizeAt(tail)
+		tail, used = (tail+sz)%r.capacity(), used-sz
+		r.put(offTail, tail)
+		r.put(offUsed, used)
+	}
+}
+
+func (r *ring) write(b []byte) {
+	if max := r.capacity()/2 - ringRecordHeader; uint64(len(b)) > max {
+		b = b[:max] // oversized records are truncated
+	}
+	n := ringRecordHeader + uint64(len(b))
+	head := r.get(offHead)
+	if pad := r.padAt(head); pad < n {
+		r.ensureFree(pad)
+		if pad >= ringRecordHeader {
+			binary.BigEndian.PutUint32(r.data[head:], ringPad)
+		}
+		r.put(offUsed, r.get(offUsed)+pad)
+		head = 0
+		r.put(offHead, head)
+	}
+	r.ensureFree(n)
+	seq := r.get(offSeq) + 1
+	binary.BigEndian.PutUint32(r.data[head:], uint32(len(b)))
+	binary.BigEndian.PutUint64(r.data[head+4:], seq)
+	copy(r.data[head+ringRecordHeader:], b)
+	// the header is updated last, so that a crash mid-write loses (at most) the current record
+	r.put(offSeq, seq)
+	r.put(offHead, (head+n)%r.capacity())
+	r.put(offUsed, r.get(offUsed)+n)
+}
+
+func (r *ring) records() (recs []RingRecord) {
+	for pos, used := r.get(offTail), r.get(offUsed); used > 0; {
+		sz := r.sizeAt(pos)
+		if sz > used || sz > r.capacity()-pos {
+			break // corrupt
+		}
+		if !r.isPad(pos) {
+			recs = append(recs, RingRecord{
+				Seq:  binary.BigEndian.Uint64(r.data[pos+4:]),
+				Data: append([]byte(nil), r.data[pos+ringRecordHeader:pos+sz]...),
+			})
+		}
+		pos, used = (pos+sz)%r.capacity(), used-sz
+	}
+	return
+}
+
+// RingStream is a Stream that retains the most recent log events in a memory-mapped file of
+// fixed size, acting as a "black box" recorder: the contents of the file survive a crash of the
+// process and may be dumped via ReadRing. Like other buffering streams it is not safe for
+// concurrent use.
+type RingStream struct {
+	buf   bytes.Buffer
+	r     *ring
+	f     *os.File
+	unmap func() error
+}
+
+// OpenRing opens (or creates) a ring file at `path` whose data region is `size` bytes. An
+// existing ring file with a different size (or one that isn't a ring file) is reinitialized.
+func OpenRing(path string, size int) (*RingStream, error) {
+	if size < 2*ringRecordHeader {
+		return nil, fmt.Errorf("ring size too small: %d", size)
+	}
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, err
+	}
+	fi, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	total := int64(ringHeaderSize + size)
+	fresh := fi.Size() != total
+	if fresh {
+		if err = f.Truncate(total); err != nil {
+			f.Close()
+			return nil, err
+		}
+	}
+	mem, unmap, err := mmap(f, int(total))
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	r, err := newRing(mem, fresh)
+	if err == errBadRing || err == errCorruptRing {
+		r, err = newRing(mem, true)
+	}
+	if err != nil {
+		unmap()
+		f.Close()
+		return nil, err
+	}
+	return &RingStream{r: r, f: f, unmap: unmap}, nil
+}
+
+// Write implements Stream
+func (s *RingStream) Write(b []byte) (int, error) { return s.buf.Write(b) }
+
+// EOM implements Stream
+func (s *RingStream) EOM(err error) error {
+	defer s.buf.Reset()
+	if err == nil {
+		s.r.write(s.buf.Bytes())
+	}
+	return err
+}
+
+// Records returns the log events currently retained, oldest first.
+func (s *RingStream) Records() []RingRecord { return s.r.records() }
+
+// Close unmaps and closes the ring file.
+func (s *RingStream) Close() error {
+	err := s.unmap()
+	if cerr := s.f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// ReadRing returns the log events retained by the ring file at `path`, oldest first. It does
+// not require memory mapping, and so may be used to dump ring files post-mortem on any platform.
+func ReadRing(path string) ([]RingRecord, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if len(b) < ringHeaderSize {
+		return nil, errBadRing
+	}
+	r, err := newRing(b, false)
+	if err != nil {
+		return nil, err
+	}
+	return r.records(), nil
+}
+
+// DumpRing writes the log events retained by the ring file at `path`, oldest first, to `w`;
+// a newline is appended to log events that lack one.
+func DumpRing(path string, w io.Writer) error {
+	recs, err := ReadRing(path)
+	if err != nil {
+		return err
+	}
+	for _, rec := range recs {
+		if _, err = w.Write(rec.Data); err == nil && (len(rec.Data) == 0 || rec.Data[len(rec.Data)-1] != '\n') {
+			_, err = w.Write([]byte{'\n'})
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
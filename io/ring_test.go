@@ -0,0 +1,133 @@
+//go:build !windows && !plan9
+
+/*
+Copyright 2016 James DeFelice
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package io_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/gologs/log/io"
+)
+
+func TestRing(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ring")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "blackbox")
+
+	s, err := OpenRing(path, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 20; i++ {
+		fmt.Fprintf(s, "event %02d", i)
+		if err = s.EOM(nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// each record is 20 bytes, so exactly five fit
+	check := func(recs []RingRecord) {
+		t.Helper()
+		if len(recs) != 5 {
+			t.Fatalf("expected 5 records instead of %d", len(recs))
+		}
+		for i, rec := range recs {
+			if expected := fmt.Sprintf("event %02d", 15+i); string(rec.Data) != expected || rec.Seq != uint64(16+i) {
+				t.Fatalf("expected %q (seq %d) instead of %q (seq %d)", expected, 16+i, rec.Data, rec.Seq)
+			}
+		}
+	}
+	check(s.Records())
+	if err = s.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	recs, err := ReadRing(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	check(recs)
+
+	var buf bytes.Buffer
+	if err = DumpRing(path, &buf); err != nil {
+		t.Fatal(err)
+	}
+	if expected := "event 15\nevent 16\nevent 17\nevent 18\nevent 19\n"; buf.String() != expected {
+		t.Fatalf("expected %q instead of %q", expected, buf.String())
+	}
+
+	// reopening retains previous records
+	if s, err = OpenRing(path, 100); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	fmt.Fprint(s, "event 20")
+	_ = s.EOM(nil)
+	if recs = s.Records(); len(recs) != 5 || string(recs[4].Data) != "event 20" || recs[4].Seq != 21 {
+		t.Fatalf("unexpected records after reopen: %v", recs)
+	}
+}
+
+func TestReadRing_Corrupt(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ring")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "blackbox")
+
+	// a ring file with a 100 byte data region, whose header is written by hand
+	ring := func(tail, used uint64, length uint32) []byte {
+		b := make([]byte, 64+100)
+		copy(b, "GOLOGRNG")
+		binary.BigEndian.PutUint64(b[8:], 100)
+		binary.BigEndian.PutUint64(b[24:], tail)
+		binary.BigEndian.PutUint64(b[32:], used)
+		if tail < 100 {
+			binary.BigEndian.PutUint32(b[64+tail:], length)
+		}
+		return b
+	}
+	for i, tc := range []struct {
+		b       []byte
+		wantErr bool
+	}{
+		{ring(1<<40, 20, 8), true},
+		{ring(0, 1000, 8), true},
+		{ring(50, 100, 40), false}, // the record overruns the data region
+	} {
+		if err = ioutil.WriteFile(path, tc.b, 0644); err != nil {
+			t.Fatal(err)
+		}
+		recs, err := ReadRing(path)
+		if (err != nil) != tc.wantErr {
+			t.Errorf("test case %d: unexpected error %v", i, err)
+		}
+		if len(recs) != 0 {
+			t.Errorf("test case %d: unexpected records %v", i, recs)
+		}
+	}
+}
@@ -0,0 +1,123 @@
+/*
+Copyright 2016 James DeFelice
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package io
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+)
+
+// RotatingFile is a Stream that appends log events to a file, which is rotated once it would
+// exceed a maximum size: the file is renamed with a ".1" suffix, prior rotations are shifted
+// (".1" becomes ".2", and so on), and those beyond the number to keep are removed. Log events
+// are never split across files. Like other buffering streams it is not safe for concurrent use.
+type RotatingFile struct {
+	buf     bytes.Buffer
+	path    string
+	maxSize int64
+	keep    int
+	f       *os.File
+	size    int64
+}
+
+// OpenRotatingFile opens (or creates) the file at `path` for appending; it's rotated once it
+// would exceed `maxSize` bytes (unless not positive), keeping up to `keep` rotated files.
+func OpenRotatingFile(path string, maxSize int64, keep int) (*RotatingFile, error) {
+	r := &RotatingFile{path: path, maxSize: maxSize, keep: keep}
+	if err := r.open(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *RotatingFile) open() error {
+	f, err := os.OpenFile(r.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return err
+	}
+	fi, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	r.f, r.size = f, fi.Size()
+	return nil
+}
+
+func (r *RotatingFile) rotated(n int) string { return fmt.Sprintf("%s.%d", r.path, n) }
+
+func (r *RotatingFile) rotate() error {
+	if err := r.f.Close(); err != nil {
+		return err
+	}
+	r.f = nil
+	if r.keep <= 0 {
+		if err := os.Remove(r.path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	} else {
+		os.Remove(r.rotated(r.keep))
+		for n := r.keep - 1; n > 0; n-- {
+			if err := os.Rename(r.rotated(n), r.rotated(n+1)); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+		}
+		if err := os.Rename(r.path, r.rotated(1)); err != nil {
+			return err
+		}
+	}
+	return r.open()
+}
+
+// Write implements Stream
+func (r *RotatingFile) Write(b []byte) (int, error) { return r.buf.Write(b) }
+
+// EOM implements Stream
+func (r *RotatingFile) EOM(err error) error {
+	defer r.buf.Reset()
+	if err != nil {
+		return err
+	}
+	if b := r.buf.Bytes(); len(b) == 0 || b[len(b)-1] != '\n' {
+		r.buf.WriteByte('\n')
+	}
+	if r.f == nil {
+		// a prior rotation failed, try again
+		if err = r.open(); err != nil {
+			return err
+		}
+	}
+	if n := int64(r.buf.Len()); r.maxSize > 0 && r.size > 0 && r.size+n > r.maxSize {
+		if err = r.rotate(); err != nil {
+			return err
+		}
+	}
+	n, err := r.f.Write(r.buf.Bytes())
+	r.size += int64(n)
+	return err
+}
+
+// Close closes the file.
+func (r *RotatingFile) Close() error {
+	if r.f == nil {
+		return nil
+	}
+	err := r.f.Close()
+	r.f = nil
+	return err
+}
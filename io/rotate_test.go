@@ -0,0 +1,61 @@
+/*
+Copyright 2016 James DeFelice
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package io_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/gologs/log/io"
+)
+
+func TestRotatingFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gologs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "app.log")
+	r, err := OpenRotatingFile(path, 10, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, m := range []string{"one", "two", "three", "four", "five", "six"} {
+		r.Write([]byte(m))
+		if err := r.EOM(nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := r.Close(); err != nil {
+		t.Fatal(err)
+	}
+	for name, expected := range map[string]string{
+		"app.log":   "six\n",
+		"app.log.1": "four\nfive\n",
+		"app.log.2": "three\n",
+	} {
+		if b, err := ioutil.ReadFile(filepath.Join(dir, name)); err != nil || string(b) != expected {
+			t.Errorf("expected %s to contain %q instead of %q: %v", name, expected, b, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, "app.log.3")); !os.IsNotExist(err) {
+		t.Errorf("expected app.log.3 to be removed: %v", err)
+	}
+}
@@ -0,0 +1,80 @@
+/*
+Copyright 2016 James DeFelice
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package io
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+)
+
+// SafeBufferStream is a Stream that retains the content of each log event as a line, without a
+// trailing newline, and is safe for concurrent use: Lines may be invoked while log events are
+// being written, by any goroutine. It's the recommended means of capturing encoded log output
+// in tests and examples (logtest.Recorder captures log events prior to encoding). The writes of
+// concurrent log events must still be serialized, as they are by config.LockGuard. The zero
+// value is ready for use.
+type SafeBufferStream struct {
+	mu    sync.Mutex
+	buf   bytes.Buffer // buf accumulates the log event in progress
+	lines []string
+}
+
+// Write implements Stream
+func (s *SafeBufferStream) Write(b []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.buf.Write(b)
+}
+
+// EOM implements Stream; the log event in progress is discarded if err is not nil.
+func (s *SafeBufferStream) EOM(err error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err == nil {
+		s.lines = append(s.lines, strings.TrimSuffix(s.buf.String(), "\n"))
+	}
+	s.buf.Reset()
+	return err
+}
+
+// Lines returns a copy of the lines logged so far, one per log event.
+func (s *SafeBufferStream) Lines() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.lines...)
+}
+
+// String returns the lines logged so far, each terminated by a newline.
+func (s *SafeBufferStream) String() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var b strings.Builder
+	for _, line := range s.lines {
+		b.WriteString(line)
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+// Reset discards the lines logged so far, as well as the log event in progress.
+func (s *SafeBufferStream) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lines = nil
+	s.buf.Reset()
+}
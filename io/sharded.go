@@ -0,0 +1,161 @@
+/*
+Copyright 2016 James DeFelice
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package io
+
+import (
+	"bytes"
+	"runtime"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+type shardedRecord struct {
+	seq uint64
+	b   []byte
+}
+
+type shard struct {
+	mu      sync.Mutex
+	records []shardedRecord
+}
+
+// ShardedWriter accepts log events concurrently, without a global lock: each event is assigned
+// a sequence number and appended to one of several shards. A background goroutine periodically
+// merges the shards and writes the events, in sequence order, to the underlying stream (one
+// Write and EOM per event). Use Stream to obtain a Stream for each log event.
+//
+// The runtime doesn't expose the current P, so shards approximate per-P buffers: each of the
+// pooled Streams is bound to a shard, and since sync.Pool caches per P, events that are logged
+// on the same P tend to be appended to the same shard.
+type ShardedWriter struct {
+	s        Stream
+	shards   []shard
+	seq      uint64 // seq is the most recently assigned sequence number
+	assigned uint32 // assigned is the number of pooled Streams bound to a shard
+	onError  func(error)
+	pool     sync.Pool
+
+	flushMu sync.Mutex // flushMu serializes writes to s
+	next    uint64     // next is the sequence number of the next event to be written
+	held    []shardedRecord
+
+	done chan struct{}
+	once sync.Once
+	wg   sync.WaitGroup
+}
+
+// Sharded returns a ShardedWriter that writes to `s` every `interval` (if positive; otherwise
+// only upon Flush). The number of shards defaults to runtime.GOMAXPROCS if `shards` isn't
+// positive. Errors returned by `s` are reported to `onError`, if not nil. Close should be invoked
+// to stop the background goroutine and write any remaining log events.
+func Sharded(s Stream, shards int, interval time.Duration, onError func(error)) *ShardedWriter {
+	if shards <= 0 {
+		shards = runtime.GOMAXPROCS(0)
+	}
+	w := &ShardedWriter{
+		s:       s,
+		shards:  make([]shard, shards),
+		onError: onError,
+		next:    1,
+		done:    make(chan struct{}),
+	}
+	w.pool.New = func() interface{} {
+		i := atomic.AddUint32(&w.assigned, 1)
+		return &shardStream{w: w, sh: &w.shards[i%uint32(len(w.shards))]}
+	}
+	if interval > 0 {
+		w.wg.Add(1)
+		go w.flushEvery(interval)
+	}
+	return w
+}
+
+// shardStream buffers a single log event for a ShardedWriter
+type shardStream struct {
+	bytes.Buffer
+	w  *ShardedWriter
+	sh *shard
+}
+
+func (s *shardStream) EOM(err error) error {
+	if err == nil {
+		s.w.append(s.sh, append([]byte(nil), s.Bytes()...))
+	}
+	s.Reset()
+	s.w.pool.Put(s)
+	return err
+}
+
+// Stream returns a Stream for a single log event; it must not be used after EOM. It is safe
+// to invoke Stream concurrently.
+func (w *ShardedWriter) Stream() Stream { return w.pool.Get().(*shardStream) }
+
+func (w *ShardedWriter) append(sh *shard, b []byte) {
+	sh.mu.Lock()
+	seq := atomic.AddUint64(&w.seq, 1)
+	sh.records = append(sh.records, shardedRecord{seq, b})
+	sh.mu.Unlock()
+}
+
+func (w *ShardedWriter) flushEvery(interval time.Duration) {
+	defer w.wg.Done()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-w.done:
+			return
+		case <-ticker.C:
+			w.Flush()
+		}
+	}
+}
+
+// Flush writes, in sequence order, all log events that have been appended so far. Events are
+// held back if an earlier sequence number has been assigned but not yet appended.
+func (w *ShardedWriter) Flush() {
+	w.flushMu.Lock()
+	defer w.flushMu.Unlock()
+	records := w.held
+	for i := range w.shards {
+		sh := &w.shards[i]
+		sh.mu.Lock()
+		records = append(records, sh.records...)
+		sh.records = sh.records[:0]
+		sh.mu.Unlock()
+	}
+	sort.Slice(records, func(i, j int) bool { return records[i].seq < records[j].seq })
+	i := 0
+	for ; i < len(records) && records[i].seq == w.next; i++ {
+		_, err := w.s.Write(records[i].b)
+		if err = w.s.EOM(err); err != nil && w.onError != nil {
+			w.onError(err)
+		}
+		w.next++
+	}
+	w.held = append(w.held[:0:0], records[i:]...)
+}
+
+// Close stops the background goroutine and then flushes pending log events. It's safe to
+// invoke Close multiple times.
+func (w *ShardedWriter) Close() {
+	w.once.Do(func() { close(w.done) })
+	w.wg.Wait()
+	w.Flush()
+}
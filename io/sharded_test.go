@@ -0,0 +1,95 @@
+/*
+Copyright 2016 James DeFelice
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package io_test
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/gologs/log/io"
+)
+
+func TestSharded(t *testing.T) {
+	const (
+		writers = 8
+		events  = 100
+	)
+	var (
+		r  = newBatchRecorder() // one "batch" per event
+		w  = Sharded(r, 4, time.Millisecond, nil)
+		wg sync.WaitGroup
+	)
+	for i := 0; i < writers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < events; j++ {
+				s := w.Stream()
+				fmt.Fprintf(s, "%d:%d", i, j)
+				s.EOM(nil)
+			}
+		}(i)
+	}
+	wg.Wait()
+	w.Close()
+
+	got := r.Batches()
+	if len(got) != writers*events {
+		t.Fatalf("expected %d events instead of %d", writers*events, len(got))
+	}
+	// events from the same writer are written in order
+	last := map[string]int{}
+	for _, e := range got {
+		var (
+			parts = strings.SplitN(e, ":", 2)
+			j, _  = strconv.Atoi(parts[1])
+		)
+		if prev, ok := last[parts[0]]; ok && prev >= j {
+			t.Fatalf("out of order event %q after %d", e, prev)
+		}
+		last[parts[0]] = j
+	}
+	sort.Strings(got)
+	for i := 1; i < len(got); i++ {
+		if got[i] == got[i-1] {
+			t.Fatalf("duplicate event %q", got[i])
+		}
+	}
+}
+
+func TestSharded_NoInterval(t *testing.T) {
+	var (
+		r = newBatchRecorder()
+		w = Sharded(r, 2, 0, nil)
+		s = w.Stream()
+	)
+	fmt.Fprint(s, "hello")
+	s.EOM(nil)
+	if got := r.Batches(); len(got) != 0 {
+		t.Fatalf("expected no events before Flush instead of %q", got)
+	}
+	w.Flush()
+	if got := r.Batches(); len(got) != 1 || got[0] != "hello" {
+		t.Fatalf("unexpected events %q", got)
+	}
+	w.Close()
+}
@@ -0,0 +1,74 @@
+/*
+Copyright 2016 James DeFelice
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package io
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"sync"
+)
+
+// stdoutMu and stderrMu serialize the lines written to os.Stdout and os.Stderr, respectively
+var stdoutMu, stderrMu sync.Mutex
+
+// lineStream is a Stream that writes each log event as a single line, see Lines
+type lineStream struct {
+	buf bytes.Buffer
+	w   io.Writer
+	mu  *sync.Mutex
+}
+
+// Lines returns a Stream that buffers each log event and, upon EOM, writes it to `w` as a single
+// line (appending a newline if needed) with a single call to Write. Writes by all of the streams
+// returned by Lines for os.Stdout (or os.Stderr) are serialized, so that their lines never
+// interleave; other Writers that are shared by streams must serialize concurrent calls to Write
+// themselves. Each stream, like other buffering streams, handles one log event at a time.
+func Lines(w io.Writer) Stream {
+	mu := new(sync.Mutex)
+	switch w {
+	case os.Stdout:
+		mu = &stdoutMu
+	case os.Stderr:
+		mu = &stderrMu
+	}
+	return &lineStream{w: w, mu: mu}
+}
+
+// Stdout returns a Stream that writes log events to os.Stdout, line by line, see Lines.
+func Stdout() Stream { return Lines(os.Stdout) }
+
+// Stderr returns a Stream that writes log events to os.Stderr, line by line, see Lines.
+func Stderr() Stream { return Lines(os.Stderr) }
+
+// Write implements Stream
+func (s *lineStream) Write(b []byte) (int, error) { return s.buf.Write(b) }
+
+// EOM implements Stream
+func (s *lineStream) EOM(err error) error {
+	defer s.buf.Reset()
+	if err != nil {
+		return err
+	}
+	if b := s.buf.Bytes(); len(b) == 0 || b[len(b)-1] != '\n' {
+		s.buf.WriteByte('\n')
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err = s.w.Write(s.buf.Bytes())
+	return err
+}
@@ -21,6 +21,7 @@ import (
 	"fmt"
 	"io"
 	"log"
+	"sync"
 )
 
 // Stream writes serialized log data ... somewhere.
@@ -65,11 +66,46 @@ func (bs *BufferedStream) EOM(err error) error {
 	return err
 }
 
+// maxPooledBuffer bounds the capacity of the buffers that are returned to the buffers pool
+const maxPooledBuffer = 64 << 10
+
+var buffers = sync.Pool{New: func() interface{} { return new(bytes.Buffer) }}
+
+// pooledStream is like BufferedStream, except that it borrows a buffer from a pool for the
+// duration of each log event: buffers are shared by streams, and idle streams don't pin the
+// memory of the largest log event that they've buffered.
+type pooledStream struct {
+	buf *bytes.Buffer
+	eom func(Buffer, error) error
+}
+
+func (ps *pooledStream) Write(b []byte) (int, error) {
+	if ps.buf == nil {
+		ps.buf = buffers.Get().(*bytes.Buffer)
+	}
+	return ps.buf.Write(b)
+}
+
+func (ps *pooledStream) EOM(err error) error {
+	buf := ps.buf
+	if buf == nil {
+		buf = buffers.Get().(*bytes.Buffer)
+	}
+	ps.buf = nil
+	defer func() {
+		if buf.Cap() <= maxPooledBuffer {
+			buf.Reset()
+			buffers.Put(buf)
+		}
+	}()
+	return ps.eom(buf, err)
+}
+
 // NewBuffered wraps the provided stream such that all generated log content is locally
-// buffered before being flushed to the underlying stream upon EOM.
+// buffered before being flushed to the underlying stream upon EOM. Buffers are pooled.
 func NewBuffered(s Stream) Stream {
-	return &BufferedStream{
-		EOMFunc: func(buf Buffer, err error) error {
+	return &pooledStream{
+		eom: func(buf Buffer, err error) error {
 			if err == nil {
 				_, err = buf.WriteTo(s)
 			}
@@ -80,8 +116,8 @@ func NewBuffered(s Stream) Stream {
 
 // SystemStream returns a buffered Stream that logs output via the standard "log" package.
 func SystemStream(calldepth int) Stream {
-	return &BufferedStream{
-		EOMFunc: func(buf Buffer, err error) error {
+	return &pooledStream{
+		eom: func(buf Buffer, err error) error {
 			if err != nil {
 				return err
 			}
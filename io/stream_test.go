@@ -18,6 +18,9 @@ package io_test
 
 import (
 	"errors"
+	"fmt"
+	"os"
+	"sync"
 	"testing"
 
 	. "github.com/gologs/log/io"
@@ -63,3 +66,97 @@ func TestBufferedStream(t *testing.T) {
 		t.Fatalf("unexpected err %v", err)
 	}
 }
+
+// chunks records each call to Write
+type chunks struct {
+	sync.Mutex
+	writes []string
+}
+
+func (c *chunks) Write(b []byte) (int, error) {
+	c.Lock()
+	defer c.Unlock()
+	c.writes = append(c.writes, string(b))
+	return len(b), nil
+}
+
+func TestLines(t *testing.T) {
+	var (
+		w  = &chunks{}
+		wg sync.WaitGroup
+	)
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			s := Lines(w)
+			for j := 0; j < 100; j++ {
+				fmt.Fprintf(s, "stream %d", i)
+				fmt.Fprintf(s, " event %d", j)
+				s.EOM(nil)
+			}
+		}(i)
+	}
+	wg.Wait()
+	if len(w.writes) != 400 {
+		t.Fatalf("expected 400 writes instead of %d", len(w.writes))
+	}
+	for _, x := range w.writes {
+		var i, j int
+		if n, err := fmt.Sscanf(x, "stream %d event %d\n", &i, &j); n != 2 || err != nil {
+			t.Fatalf("unexpected line %q", x)
+		}
+	}
+}
+
+func TestSafeBufferStream(t *testing.T) {
+	var (
+		s    SafeBufferStream
+		done = make(chan struct{})
+	)
+	go func() {
+		defer close(done)
+		for j := 0; j < 100; j++ {
+			fmt.Fprintf(&s, "event %d\n", j)
+			s.EOM(nil)
+		}
+	}()
+	for reading := true; reading; {
+		select {
+		case <-done:
+			reading = false
+		default:
+			_ = s.Lines() // concurrently with the writer
+		}
+	}
+
+	fmt.Fprint(&s, "discarded")
+	if err := s.EOM(errors.New("oops")); err == nil {
+		t.Fatal("expected an error")
+	}
+	lines := s.Lines()
+	if len(lines) != 100 || lines[0] != "event 0" || lines[99] != "event 99" {
+		t.Fatalf("unexpected lines %q", lines)
+	}
+	s.Reset()
+	if x := s.String(); x != "" {
+		t.Fatalf("unexpected output %q after reset", x)
+	}
+}
+
+func TestIsTerminal(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	defer w.Close()
+	for _, x := range []interface{ Write([]byte) (int, error) }{w, &chunks{}, (*os.File)(nil)} {
+		if IsTerminal(x) {
+			t.Errorf("unexpected terminal %T", x)
+		}
+		if EnableColor(x) {
+			t.Errorf("unexpected colors for %T", x)
+		}
+	}
+}
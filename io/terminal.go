@@ -0,0 +1,43 @@
+/*
+Copyright 2016 James DeFelice
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package io
+
+import (
+	"io"
+	"os"
+)
+
+// IsTerminal returns true if `w` is a file that refers to a terminal (more precisely, to a
+// character device). Writers that aren't files, such as buffers and pipes, are not terminals.
+func IsTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok || f == nil {
+		return false
+	}
+	fi, err := f.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
+}
+
+// EnableColor prepares `w` for ANSI color escape sequences and returns true if it's a terminal
+// (see IsTerminal) that renders them. On Windows it enables virtual terminal processing of the
+// console, and returns false if the console doesn't support it (prior to Windows 10).
+func EnableColor(w io.Writer) bool {
+	if !IsTerminal(w) {
+		return false
+	}
+	return enableVT(w.(*os.File))
+}
@@ -0,0 +1,107 @@
+/*
+Copyright 2016 James DeFelice
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package io
+
+import (
+	"bytes"
+	"errors"
+	"time"
+)
+
+var (
+	// ErrTimeout is returned by a TimeoutStream when writing a log event takes too long.
+	ErrTimeout = errors.New("timed out writing log event")
+
+	// ErrCanceled is returned by a TimeoutStream when a log event is abandoned because its
+	// done chan closed before it could be written.
+	ErrCanceled = errors.New("canceled writing log event")
+
+	// ErrBusy is returned by a TimeoutStream for log events that are dropped because the write
+	// of an earlier (abandoned) event has not yet completed.
+	ErrBusy = errors.New("dropped log event, stream is busy")
+)
+
+// TimeoutStream buffers each log event and, upon EOM, writes it to the underlying stream in the
+// background, waiting at most a fixed duration for the write to complete. Abandoned writes run to
+// completion but, until they do, subsequent log events are dropped: the underlying stream never
+// sees concurrent writes. Like other buffering streams it is not safe for concurrent use.
+type TimeoutStream struct {
+	buf      bytes.Buffer
+	s        Stream
+	d        time.Duration
+	inflight chan struct{} // inflight holds a token while a write is pending
+}
+
+// Timeout returns a TimeoutStream that waits at most `d` for each log event to be written to
+// `s`, for example a network sink that may hang.
+func Timeout(s Stream, d time.Duration) *TimeoutStream {
+	return &TimeoutStream{s: s, d: d, inflight: make(chan struct{}, 1)}
+}
+
+// Write implements Stream
+func (t *TimeoutStream) Write(b []byte) (int, error) { return t.buf.Write(b) }
+
+// EOM implements Stream
+func (t *TimeoutStream) EOM(err error) error { return t.EOMDone(nil, err) }
+
+// EOMDone is like EOM but also abandons the write if `done` closes first (see WithDone).
+func (t *TimeoutStream) EOMDone(done <-chan struct{}, err error) error {
+	defer t.buf.Reset()
+	if err != nil {
+		return err
+	}
+	select {
+	case t.inflight <- struct{}{}:
+	default:
+		return ErrBusy
+	}
+	var (
+		b      = append([]byte(nil), t.buf.Bytes()...)
+		result = make(chan error, 1)
+		timer  = time.NewTimer(t.d)
+	)
+	defer timer.Stop()
+	go func() {
+		defer func() { <-t.inflight }()
+		_, err := t.s.Write(b)
+		result <- t.s.EOM(err)
+	}()
+	select {
+	case err = <-result:
+		return err
+	case <-timer.C:
+		return ErrTimeout
+	case <-done:
+		return ErrCanceled
+	}
+}
+
+type doneStream struct {
+	*TimeoutStream
+	done <-chan struct{}
+}
+
+func (d doneStream) EOM(err error) error { return d.EOMDone(d.done, err) }
+
+// WithDone returns a view of the receiver whose EOM also abandons the write if `done` closes,
+// typically the Done chan of the log event's context.
+func (t *TimeoutStream) WithDone(done <-chan struct{}) Stream {
+	if done == nil {
+		return t
+	}
+	return doneStream{t, done}
+}
@@ -0,0 +1,82 @@
+/*
+Copyright 2016 James DeFelice
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package io_test
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	. "github.com/gologs/log/io"
+)
+
+func TestTimeout(t *testing.T) {
+	var (
+		unblock = make(chan struct{})
+		out     bytes.Buffer
+		s       = Timeout(&WriterAdapter{
+			Writer: &out,
+			EOMFunc: func(err error) error {
+				<-unblock // blocks until closed
+				return err
+			},
+		}, 10*time.Millisecond)
+	)
+	s.Write([]byte("hung"))
+	if err := s.EOM(nil); err != ErrTimeout {
+		t.Fatalf("expected %v instead of %v", ErrTimeout, err)
+	}
+	s.Write([]byte("dropped"))
+	if err := s.EOM(nil); err != ErrBusy {
+		t.Fatalf("expected %v instead of %v", ErrBusy, err)
+	}
+
+	close(unblock)
+	// wait for the abandoned write to complete
+	for i := 0; ; i++ {
+		s.Write([]byte("ok"))
+		err := s.EOM(nil)
+		if err == nil {
+			break
+		}
+		if err != ErrBusy || i > 100 {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if out.String() != "hungok" {
+		t.Fatalf("unexpected output %q", out.String())
+	}
+}
+
+func TestTimeout_WithDone(t *testing.T) {
+	var (
+		unblock = make(chan struct{})
+		done    = make(chan struct{})
+		s       = Timeout(&WriterAdapter{
+			Writer:  &bytes.Buffer{},
+			EOMFunc: func(err error) error { <-unblock; return err },
+		}, time.Hour)
+		ds = s.WithDone(done)
+	)
+	defer close(unblock)
+	close(done)
+	ds.Write([]byte("foo"))
+	if err := ds.EOM(nil); err != ErrCanceled {
+		t.Fatalf("expected %v instead of %v", ErrCanceled, err)
+	}
+}
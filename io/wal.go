@@ -0,0 +1,243 @@
+/*
+Copyright 2016 James DeFelice
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package io
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"io"
+	"os"
+)
+
+// DefaultCompactAt is the default size at which a fully acknowledged WAL file is truncated.
+const DefaultCompactAt = 1 << 20
+
+// WALOptions customize the durability of a WALStream.
+type WALOptions struct {
+	// SyncEvery fsyncs the log file after every N appended log events (and the acknowledgement
+	// file after every N forwarded log events). Zero leaves syncing to the operating system.
+	SyncEvery int
+	// CompactAt is the size of the log file beyond which it is truncated once every log event
+	// has been forwarded, defaults to DefaultCompactAt.
+	CompactAt int64
+}
+
+type walRecord struct {
+	data []byte
+	end  int64 // end is the offset in the log file just past this record
+}
+
+// WALStream provides guaranteed delivery: each log event is appended to a local write-ahead log
+// file before it is forwarded to the underlying stream, and is only acknowledged once forwarded
+// successfully. Log events that could not be forwarded are retried, in order, upon subsequent
+// EOMs, and are replayed when the WAL is reopened (for example after a crash or restart).
+// Acknowledgements are stored in a separate file whose name is that of the log file with an
+// ".ack" suffix. Like other buffering streams it is not safe for concurrent use.
+type WALStream struct {
+	buf     bytes.Buffer
+	s       Stream
+	opts    WALOptions
+	f       *os.File
+	ackf    *os.File
+	end     int64
+	ack     int64
+	pending []walRecord
+	appends int
+	acks    int
+}
+
+// OpenWAL opens (or creates) the write-ahead log file at `path` and attempts to replay any log
+// events that were not acknowledged previously to `s`. Replay errors are not fatal: such events
+// remain pending.
+func OpenWAL(path string, s Stream, opts WALOptions) (*WALStream, error) {
+	if opts.CompactAt <= 0 {
+		opts.CompactAt = DefaultCompactAt
+	}
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, err
+	}
+	ackf, err := os.OpenFile(path+".ack", os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	w := &WALStream{s: s, opts: opts, f: f, ackf: ackf}
+	if err = w.recover(); err != nil {
+		w.Close()
+		return nil, err
+	}
+	w.forward()
+	return w, nil
+}
+
+// recover reads the acknowledged offset and loads unacknowledged records; a partially written
+// trailing record (torn by a crash), or one whose size exceeds the rest of the log, is discarded.
+func (w *WALStream) recover() error {
+	var ack [8]byte
+	if n, err := w.ackf.ReadAt(ack[:], 0); err == nil && n == len(ack) {
+		w.ack = int64(binary.BigEndian.Uint64(ack[:]))
+	}
+	fi, err := w.f.Stat()
+	if err != nil {
+		return err
+	}
+	if w.ack > fi.Size() {
+		w.ack = 0 // the log was truncated independently of its acknowledgements
+	}
+	r := bufio.NewReader(io.NewSectionReader(w.f, w.ack, fi.Size()-w.ack))
+	w.end = w.ack
+	for {
+		sz, err := binary.ReadUvarint(r)
+		if err != nil || sz > uint64(fi.Size()-w.end) {
+			break
+		}
+		data := make([]byte, sz)
+		if _, err = io.ReadFull(r, data); err != nil {
+			break
+		}
+		w.end += int64(uvarintLen(sz)) + int64(sz)
+		w.pending = append(w.pending, walRecord{data: data, end: w.end})
+	}
+	if w.end != fi.Size() {
+		if err = w.f.Truncate(w.end); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func uvarintLen(x uint64) int {
+	var b [binary.MaxVarintLen64]byte
+	return binary.PutUvarint(b[:], x)
+}
+
+// Write implements Stream
+func (w *WALStream) Write(b []byte) (int, error) { return w.buf.Write(b) }
+
+// EOM implements Stream. It returns an error if the log event could not be appended to the
+// log file, or if any pending log event could not be forwarded (in which case it remains
+// pending).
+func (w *WALStream) EOM(err error) error {
+	defer w.buf.Reset()
+	if err != nil {
+		return err
+	}
+	if err = w.append(w.buf.Bytes()); err != nil {
+		return err
+	}
+	return w.forward()
+}
+
+func (w *WALStream) append(b []byte) error {
+	var (
+		sz  [binary.MaxVarintLen64]byte
+		n   = binary.PutUvarint(sz[:], uint64(len(b)))
+		rec = make([]byte, 0, n+len(b))
+	)
+	rec = append(append(rec, sz[:n]...), b...)
+	if _, err := w.f.WriteAt(rec, w.end); err != nil {
+		return err
+	}
+	w.end += int64(len(rec))
+	w.pending = append(w.pending, walRecord{data: rec[n:], end: w.end})
+	if w.appends++; w.opts.SyncEvery > 0 && w.appends%w.opts.SyncEvery == 0 {
+		return w.f.Sync()
+	}
+	return nil
+}
+
+// forward attempts to write all pending log events to the underlying stream, in order.
+func (w *WALStream) forward() (err error) {
+	acked := 0
+	for _, rec := range w.pending {
+		_, err = w.s.Write(rec.data)
+		if err = w.s.EOM(err); err != nil {
+			break
+		}
+		w.ack = rec.end
+		acked++
+	}
+	if acked == 0 {
+		return
+	}
+	w.pending = w.pending[acked:]
+	if len(w.pending) == 0 && w.end >= w.opts.CompactAt {
+		if cerr := w.compact(acked); err == nil {
+			err = cerr
+		}
+		return
+	}
+	if aerr := w.writeAck(acked); err == nil {
+		err = aerr
+	}
+	return
+}
+
+// compact truncates the fully acknowledged log. The reset acknowledgement is synced before the
+// log is truncated: otherwise, after a crash, a stale acknowledgement could point into records
+// that were appended since. A crash in between replays the log, which was already forwarded.
+func (w *WALStream) compact(acked int) error {
+	w.ack = 0
+	if err := w.writeAck(acked); err != nil {
+		return err
+	}
+	if err := w.ackf.Sync(); err != nil {
+		return err
+	}
+	if err := w.f.Truncate(0); err != nil {
+		return err
+	}
+	w.end = 0
+	return nil
+}
+
+func (w *WALStream) writeAck(n int) error {
+	var ack [8]byte
+	binary.BigEndian.PutUint64(ack[:], uint64(w.ack))
+	if _, err := w.ackf.WriteAt(ack[:], 0); err != nil {
+		return err
+	}
+	before := w.acks
+	w.acks += n
+	if k := w.opts.SyncEvery; k > 0 && w.acks/k != before/k {
+		return w.ackf.Sync()
+	}
+	return nil
+}
+
+// Pending returns the number of log events that have not yet been forwarded.
+func (w *WALStream) Pending() int { return len(w.pending) }
+
+// Close syncs and closes the log files; pending log events are replayed when the WAL is
+// reopened. Close does not close the underlying stream.
+func (w *WALStream) Close() error {
+	var err error
+	for _, f := range []*os.File{w.f, w.ackf} {
+		if f == nil {
+			continue
+		}
+		if serr := f.Sync(); err == nil {
+			err = serr
+		}
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+	}
+	return err
+}
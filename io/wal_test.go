@@ -0,0 +1,195 @@
+/*
+Copyright 2016 James DeFelice
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package io_test
+
+import (
+	"encoding/binary"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	. "github.com/gologs/log/io"
+)
+
+// remote records events until it's told to fail
+type remote struct {
+	BufferedStream
+	down   bool
+	events []string
+}
+
+func newRemote() *remote {
+	r := &remote{}
+	r.EOMFunc = func(b Buffer, err error) error {
+		if err != nil {
+			return err
+		}
+		if r.down {
+			return errors.New("remote is down")
+		}
+		r.events = append(r.events, b.String())
+		return nil
+	}
+	return r
+}
+
+func TestWAL(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gologs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	var (
+		path = filepath.Join(dir, "audit.wal")
+		r    = newRemote()
+		log  = func(w *WALStream, m string) error {
+			w.Write([]byte(m))
+			return w.EOM(nil)
+		}
+	)
+	w, err := OpenWAL(path, r, WALOptions{SyncEvery: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = log(w, "a"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	r.down = true
+	if err = log(w, "b"); err == nil {
+		t.Fatal("expected forwarding error")
+	}
+	log(w, "c")
+	if w.Pending() != 2 {
+		t.Fatalf("expected 2 pending events instead of %d", w.Pending())
+	}
+	w.Close()
+
+	// simulate a torn write at the tail of the log
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Write([]byte{10, 'x'})
+	f.Close()
+
+	// restart: pending events are replayed
+	r.down = false
+	if w, err = OpenWAL(path, r, WALOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	if w.Pending() != 0 {
+		t.Fatalf("expected no pending events instead of %d", w.Pending())
+	}
+	log(w, "d")
+	if expected := []string{"a", "b", "c", "d"}; !reflect.DeepEqual(expected, r.events) {
+		t.Fatalf("expected %q instead of %q", expected, r.events)
+	}
+}
+
+func TestWAL_Corrupt(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gologs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "audit.wal")
+	for i, tc := range []struct {
+		log []byte
+		ack uint64
+	}{
+		{log: []byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x7f, 'x'}},
+		// a stale acknowledgement that points into the middle of a record
+		{log: []byte{10, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x7f, 'x'}, ack: 1},
+	} {
+		var ack [8]byte
+		binary.BigEndian.PutUint64(ack[:], tc.ack)
+		if err = ioutil.WriteFile(path, tc.log, 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err = ioutil.WriteFile(path+".ack", ack[:], 0644); err != nil {
+			t.Fatal(err)
+		}
+		w, err := OpenWAL(path, newRemote(), WALOptions{})
+		if err != nil {
+			t.Fatalf("test case %d: unexpected error: %v", i, err)
+		}
+		if w.Pending() != 0 {
+			t.Errorf("test case %d: expected the corrupt tail to be discarded", i)
+		}
+		w.Close()
+	}
+}
+
+func TestWAL_Compact(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gologs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	var (
+		path = filepath.Join(dir, "audit.wal")
+		r    = newRemote()
+		log  = func(w *WALStream, m string) {
+			w.Write([]byte(m))
+			if err := w.EOM(nil); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		}
+		size = func(path string) int64 {
+			fi, err := os.Stat(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			return fi.Size()
+		}
+	)
+	w, err := OpenWAL(path, r, WALOptions{CompactAt: 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+	log(w, "a")
+	log(w, "b")
+	if n := size(path); n != 0 {
+		t.Fatalf("expected the log to be compacted instead of %d bytes", n)
+	}
+	log(w, "c")
+	w.Close()
+
+	// simulate a crash between the reset of the acknowledgement and the truncation of the log:
+	// the forwarded events are replayed, rather than lost
+	if err = ioutil.WriteFile(path, []byte{1, 'a', 1, 'b', 1, 'c'}, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err = ioutil.WriteFile(path+".ack", make([]byte, 8), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if w, err = OpenWAL(path, r, WALOptions{CompactAt: 4}); err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	log(w, "d")
+	if expected := []string{"a", "b", "c", "a", "b", "c", "d"}; !reflect.DeepEqual(expected, r.events) {
+		t.Fatalf("expected %q instead of %q", expected, r.events)
+	}
+}
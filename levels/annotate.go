@@ -0,0 +1,110 @@
+/*
+Copyright 2016 James DeFelice
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package levels
+
+import (
+	"runtime"
+	"time"
+
+	"github.com/gologs/log/caller"
+	"github.com/gologs/log/context"
+	"github.com/gologs/log/context/fields"
+	"github.com/gologs/log/io"
+	"github.com/gologs/log/logger"
+)
+
+// AnnotateErrors returns a Transform that annotates the context of log events at Error and
+// above with:
+//   - the Caller of the event, if not already present (for example, when call tracking is
+//     disabled), see caller.FromContext;
+//   - a "stack" field, see caller.CaptureStack;
+//   - an "error" field, if any of the event's args is a non-nil error (the first such error).
+//
+// Structured encoders render these alongside the other fields of the event.
+func AnnotateErrors() Transform {
+	d := func(logs logger.Logger) logger.Logger {
+		if logger.IsNull(logs) {
+			return logs
+		}
+		return logger.Func(func(c context.Context, m string, a ...interface{}) {
+			if c == nil {
+				c = context.TODO()
+			}
+			stack := caller.CaptureStack()
+			if _, ok := caller.FromContext(c); !ok && len(stack) > 0 {
+				c = caller.NewContext(c, stack[0].File, stack[0].Line, stack[0].FuncName)
+			}
+			ff := make([]fields.Field, 1, 2)
+			ff[0] = fields.F("stack", stack)
+			for _, x := range a {
+				if err, ok := x.(error); ok && err != nil {
+					ff = append(ff, fields.F("error", err))
+					break
+				}
+			}
+			logs.Logf(fields.NewContext(c, ff...), m, a...)
+		})
+	}
+	return Transform{Error: d, Fatal: d, Panic: d}
+}
+
+// AnnotateRuntime returns a Transform that annotates the context of log events at Fatal and
+// above with a snapshot of the state of the runtime, as fields: "goroutines" (the number of
+// goroutines), "memstats" (heap and GC statistics, see runtime.MemStats). If `stacks` is not
+// nil then the stacks of all goroutines are written to it, followed by EOM. The snapshot is
+// only taken for such events, so there's no cost otherwise.
+func AnnotateRuntime(stacks io.Stream) Transform {
+	d := func(logs logger.Logger) logger.Logger {
+		if logger.IsNull(logs) {
+			return logs
+		}
+		return logger.Func(func(c context.Context, m string, a ...interface{}) {
+			if c == nil {
+				c = context.TODO()
+			}
+			var ms runtime.MemStats
+			runtime.ReadMemStats(&ms)
+			c = fields.NewContext(c,
+				fields.F("goroutines", runtime.NumGoroutine()),
+				fields.F("memstats", map[string]interface{}{
+					"heap_alloc":     ms.HeapAlloc,
+					"heap_sys":       ms.HeapSys,
+					"heap_objects":   ms.HeapObjects,
+					"sys":            ms.Sys,
+					"num_gc":         ms.NumGC,
+					"gc_pause_total": time.Duration(ms.PauseTotalNs),
+					"last_gc":        time.Unix(0, int64(ms.LastGC)),
+				}),
+			)
+			if stacks != nil {
+				buf := make([]byte, 1<<16)
+				for {
+					n := runtime.Stack(buf, true)
+					if n < len(buf) {
+						buf = buf[:n]
+						break
+					}
+					buf = make([]byte, 2*len(buf))
+				}
+				_, err := stacks.Write(buf)
+				_ = stacks.EOM(err)
+			}
+			logs.Logf(c, m, a...)
+		})
+	}
+	return Transform{Fatal: d, Panic: d}
+}
@@ -0,0 +1,41 @@
+/*
+Copyright 2016 James DeFelice
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package levels
+
+import (
+	"github.com/gologs/log/context"
+	"github.com/gologs/log/logger"
+)
+
+// ErrorSinks returns a logger.ErrorSink that forwards errors to the sink registered for the
+// level of the failed log event, or else to `def` (which may be nil to ignore such errors).
+func ErrorSinks(def logger.ErrorSink, byLevel map[Level]logger.ErrorSink) logger.ErrorSink {
+	if def == nil {
+		def = logger.IgnoreErrors()
+	}
+	return logger.ErrorSinkFunc(func(c context.Context, e logger.Entry, err error) {
+		if c != nil {
+			if lvl, ok := FromContext(c); ok {
+				if es, ok := byLevel[lvl]; ok && es != nil {
+					es.LogError(c, e, err)
+					return
+				}
+			}
+		}
+		def.LogError(c, e, err)
+	})
+}
@@ -0,0 +1,82 @@
+/*
+Copyright 2016 James DeFelice
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package levels
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gologs/log/context"
+	"github.com/gologs/log/logger"
+)
+
+// nameProbe is given to TransformOps by Explain, to discover their names
+type nameProbe struct{ name string }
+
+func (*nameProbe) Logf(_ context.Context, _ string, _ ...interface{}) {}
+
+// Named returns a TransformOp that identifies `op` by `name`, in the Path of log events (see
+// logger.SetTracing and logger.Path) and in the output of Explain.
+func Named(name string, op TransformOp) TransformOp {
+	return func(x Level, logs logger.Logger) (Level, logger.Logger) {
+		if p, ok := logs.(*nameProbe); ok {
+			p.name = name
+			return x, logs
+		}
+		x, logs = op(x, logs)
+		return x, logger.Traced(name, logs)
+	}
+}
+
+// Explain describes the effective pipeline of each Level, as built by the given TransformOps:
+// for every level, one line that lists the ops in the order they're applied (log events pass
+// through them in reverse), noting those that remap the level or drop log events. Ops are
+// identified by name (see Named), or else by their position in the chain (#1, #2, ...).
+func Explain(chain ...TransformOp) string {
+	var b strings.Builder
+	for _, lvl := range allLevels {
+		var (
+			x    = lvl
+			logs = logger.Logger(logger.Func(func(_ context.Context, _ string, _ ...interface{}) {}))
+			sep  = " "
+		)
+		fmt.Fprintf(&b, "%v:", lvl)
+		for i, op := range chain {
+			if op == nil {
+				continue
+			}
+			probe := &nameProbe{}
+			op(x, probe)
+			name := probe.name
+			if name == "" {
+				name = fmt.Sprintf("#%d", i+1)
+			}
+			x2, logs2 := op(x, logs)
+			b.WriteString(sep + name)
+			sep = ", "
+			if x2 != x {
+				fmt.Fprintf(&b, " -> %v", x2)
+			}
+			if logger.IsNull(logs2) && !logger.IsNull(logs) {
+				b.WriteString(" (dropped)")
+			}
+			x, logs = x2, logs2
+		}
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
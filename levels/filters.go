@@ -17,6 +17,7 @@ limitations under the License.
 package levels
 
 import (
+	"github.com/gologs/log/context"
 	"github.com/gologs/log/logger"
 )
 
@@ -78,8 +79,14 @@ func MatchAny(levelMask Level) Filter { return func(x Level) bool { return (leve
 func MatchExact(lvl Level) Filter { return func(x Level) bool { return x == lvl } }
 
 // MatchAtOrAbove filters return true if the tested level is the same or higher then that provided
-// to the matcher.
-func MatchAtOrAbove(lvl Level) Filter { return func(x Level) bool { return x >= lvl } }
+// to the matcher, by Severity; unranked levels are at or above only themselves.
+func MatchAtOrAbove(lvl Level) Filter {
+	min := lvl.Severity()
+	if min == 0 {
+		return MatchExact(lvl)
+	}
+	return func(x Level) bool { return x.Severity() >= min }
+}
 
 // Broadcast replicates log messages for the accepted levels to all the provided loggers.
 // If replace is false, a copy of the log message is also sent to the original input logger
@@ -113,3 +120,51 @@ func Accept(filter Filter) TransformOp {
 		return x, logger.Null()
 	}
 }
+
+// AcceptContext drops log events for which `pred` returns false, as evaluated against the
+// context of each event. For example, debug logging may be enabled for specific requests:
+//
+//	Only(MatchExact(Debug), AcceptContext(isDebugRequest))
+//
+// Note that this transform must be applied before any threshold that would otherwise discard
+// the levels of interest.
+func AcceptContext(pred func(context.Context) bool) TransformOp {
+	return func(x Level, ll logger.Logger) (Level, logger.Logger) {
+		return x, logger.When(pred)(ll)
+	}
+}
+
+// Only applies the given TransformOp to those levels accepted by the filter; other levels
+// are passed through unmodified.
+func Only(filter Filter, op TransformOp) TransformOp {
+	return func(x Level, ll logger.Logger) (Level, logger.Logger) {
+		if filter(x) && op != nil {
+			return op(x, ll)
+		}
+		return x, ll
+	}
+}
+
+// Reject drops log events that match the given predicate, for example those that describe
+// requests for health-check endpoints. See logger.Reject.
+func Reject(pred logger.Predicate) TransformOp {
+	return func(x Level, ll logger.Logger) (Level, logger.Logger) {
+		return x, logger.Reject(pred)(ll)
+	}
+}
+
+// EnableWhen wraps a threshold TransformOp (see MinThreshold) such that log events at levels
+// accepted by `filter`, that would otherwise be discarded by the threshold, are logged if `pred`
+// returns true for the context of the event. For example, debug logging may be enabled for
+// specific packages (see caller.Matches):
+//
+//	EnableWhen(MatchExact(Debug), caller.Matches("io/rotate"), MinThreshold(Info))
+func EnableWhen(filter Filter, pred func(context.Context) bool, threshold TransformOp) TransformOp {
+	return func(x Level, ll logger.Logger) (Level, logger.Logger) {
+		x2, ll2 := threshold(x, ll)
+		if filter(x) && pred != nil && logger.IsNull(ll2) && !logger.IsNull(ll) {
+			return x, logger.When(pred)(ll)
+		}
+		return x2, ll2
+	}
+}
@@ -56,3 +56,41 @@ func NewIndexer(idx Indexer, levels []Level, chain ...TransformOp) Indexer {
 	}
 	return m
 }
+
+// Override returns an Indexer that maps each Level to the Logger given for it in `overrides`,
+// if any, and otherwise to the Logger of `base`; for example, to route Fatal log events to a
+// synchronous sink. A nil Logger in `overrides` discards the log events of its Level.
+func Override(base Indexer, overrides map[Level]logger.Logger) Indexer {
+	m := make(levelMap, len(overrides))
+	for lvl, logs := range overrides {
+		if logs == nil {
+			logs = logger.Null()
+		}
+		m[lvl] = logs
+	}
+	return IndexerFunc(func(lvl Level) (logger.Logger, bool) {
+		if logs, ok := m[lvl]; ok {
+			return logs, true
+		}
+		if base == nil {
+			return nil, false
+		}
+		return base.Logger(lvl)
+	})
+}
+
+// Chain returns an Indexer that maps each Level to the Logger of the first of the given
+// Indexers that has one for it.
+func Chain(idx ...Indexer) Indexer {
+	return IndexerFunc(func(lvl Level) (logger.Logger, bool) {
+		for _, i := range idx {
+			if i == nil {
+				continue
+			}
+			if logs, ok := i.Logger(lvl); ok {
+				return logs, true
+			}
+		}
+		return nil, false
+	})
+}
@@ -17,7 +17,14 @@ limitations under the License.
 package levels
 
 import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"github.com/gologs/log/caller"
 	"github.com/gologs/log/context"
+	"github.com/gologs/log/context/fields"
 	"github.com/gologs/log/logger"
 )
 
@@ -37,6 +44,117 @@ type Interface interface {
 	Panic(...interface{})          // Panic logs without a message format and then, typically, invokes a panic func
 }
 
+// InterfaceE is an optional extension of Interface. Its methods return the error, if any, that
+// prevented a log event from being written to its sink; such errors are then not reported to
+// the sink's ErrorSink. See logger.CaptureErrors.
+type InterfaceE interface {
+	DebugfE(string, ...interface{}) error // DebugfE signifies a Debug level message
+	InfofE(string, ...interface{}) error  // InfofE signifies an Info level message
+	WarnfE(string, ...interface{}) error  // WarnfE signifies a Warn level message
+	ErrorfE(string, ...interface{}) error // ErrorfE signifies an Error level message
+}
+
+type noErrors struct{ Interface }
+
+func (i noErrors) DebugfE(m string, a ...interface{}) error { i.Debugf(m, a...); return nil }
+func (i noErrors) InfofE(m string, a ...interface{}) error  { i.Infof(m, a...); return nil }
+func (i noErrors) WarnfE(m string, a ...interface{}) error  { i.Warnf(m, a...); return nil }
+func (i noErrors) ErrorfE(m string, a ...interface{}) error { i.Errorf(m, a...); return nil }
+
+// E returns the InterfaceE extension of the given Interface. Interface implementations that
+// do not also implement InterfaceE never report errors.
+func E(i Interface) InterfaceE {
+	if e, ok := i.(InterfaceE); ok {
+		return e
+	}
+	return noErrors{i}
+}
+
+// InterfaceErr is an optional extension of Interface. Its methods log a message along with an
+// error, which is attached to the context of the log event as the "error" field (unless nil),
+// followed by the given fields; see fields.FromContext. The message is never interpreted as a
+// format.
+type InterfaceErr interface {
+	WarnErr(err error, msg string, f ...fields.Field)  // WarnErr signifies a Warn level message
+	ErrorErr(err error, msg string, f ...fields.Field) // ErrorErr signifies an Error level message
+}
+
+type noErr struct{ Interface }
+
+func (i noErr) WarnErr(err error, m string, f ...fields.Field)  { i.Warn(flatten(err, m, f)) }
+func (i noErr) ErrorErr(err error, m string, f ...fields.Field) { i.Error(flatten(err, m, f)) }
+
+// flatten renders the error and fields as part of the message, for implementations of Interface
+// that can't attach them to the context of the log event
+func flatten(err error, m string, f []fields.Field) string {
+	var b strings.Builder
+	b.WriteString(m)
+	if err != nil {
+		b.WriteString(": ")
+		b.WriteString(err.Error())
+	}
+	for _, x := range f {
+		fmt.Fprintf(&b, " %s=%v", x.Key, x.Value)
+	}
+	return b.String()
+}
+
+// Err returns the InterfaceErr extension of the given Interface. Interface implementations that
+// do not also implement InterfaceErr render the error and fields as part of the message.
+func Err(i Interface) InterfaceErr {
+	if e, ok := i.(InterfaceErr); ok {
+		return e
+	}
+	return noErr{i}
+}
+
+// InterfaceW is an optional extension of Interface, akin to the "sugared" APIs of other logging
+// libraries. Its methods log a message along with fields given as alternating keys and values
+// (see fields.KV), which are attached to the context of the log event; see fields.FromContext.
+// The message is never interpreted as a format.
+type InterfaceW interface {
+	Debugw(msg string, keysAndValues ...interface{}) // Debugw signifies a Debug level message
+	Infow(msg string, keysAndValues ...interface{})  // Infow signifies an Info level message
+	Warnw(msg string, keysAndValues ...interface{})  // Warnw signifies a Warn level message
+	Errorw(msg string, keysAndValues ...interface{}) // Errorw signifies an Error level message
+	Fatalw(msg string, keysAndValues ...interface{}) // Fatalw logs and then, typically, invokes an exit func
+	Panicw(msg string, keysAndValues ...interface{}) // Panicw logs and then, typically, invokes a panic func
+}
+
+type noKV struct{ Interface }
+
+func (i noKV) Debugw(m string, kv ...interface{}) { i.Debug(flatten(nil, m, fields.KV(kv...))) }
+func (i noKV) Infow(m string, kv ...interface{})  { i.Info(flatten(nil, m, fields.KV(kv...))) }
+func (i noKV) Warnw(m string, kv ...interface{})  { i.Warn(flatten(nil, m, fields.KV(kv...))) }
+func (i noKV) Errorw(m string, kv ...interface{}) { i.Error(flatten(nil, m, fields.KV(kv...))) }
+func (i noKV) Fatalw(m string, kv ...interface{}) { i.Fatal(flatten(nil, m, fields.KV(kv...))) }
+func (i noKV) Panicw(m string, kv ...interface{}) { i.Panic(flatten(nil, m, fields.KV(kv...))) }
+
+// W returns the InterfaceW extension of the given Interface. Interface implementations that
+// do not also implement InterfaceW render the fields as part of the message.
+func W(i Interface) InterfaceW {
+	if w, ok := i.(InterfaceW); ok {
+		return w
+	}
+	return noKV{i}
+}
+
+// Enabler is an optional extension of Interface. Implementations report whether log events
+// at a given Level would actually be delivered, which allows callers to skip the construction
+// of expensive log arguments.
+type Enabler interface {
+	Enabled(Level) bool
+}
+
+// Enabled returns true if the given Interface would deliver log events at the given Level.
+// Interface implementations that do not also implement Enabler are assumed to log everything.
+func Enabled(i Interface, lvl Level) bool {
+	if e, ok := i.(Enabler); ok {
+		return e.Enabled(lvl)
+	}
+	return true
+}
+
 // Level represents a logging priority, or threshold, usually to indicate a level
 // of importance for an associated log message.
 type Level int
@@ -55,6 +173,34 @@ const (
 
 var allLevels = []Level{Debug, Info, Warn, Error, Fatal, Panic}
 
+var levelNames = map[Level]string{
+	Debug: "debug",
+	Info:  "info",
+	Warn:  "warn",
+	Error: "error",
+	Fatal: "fatal",
+	Panic: "panic",
+}
+
+// String returns the lowercase name of the Level
+func (l Level) String() string {
+	if s, ok := levelNames[l]; ok {
+		return s
+	}
+	return "Level(" + strconv.Itoa(int(l)) + ")"
+}
+
+// ParseLevel returns the Level identified by the given name (case-insensitive), see Level.String.
+func ParseLevel(name string) (Level, bool) {
+	name = strings.ToLower(name)
+	for l, s := range levelNames {
+		if s == name {
+			return l, true
+		}
+	}
+	return 0, false
+}
+
 type key int
 
 const (
@@ -74,17 +220,22 @@ func NewContext(ctx context.Context, lvl Level) context.Context {
 	return context.WithValue(ctx, levelKey, lvl)
 }
 
+func init() {
+	context.RegisterField("level", func(c context.Context) (interface{}, bool) { return FromContext(c) })
+}
+
 // FromContext attempts to extract a Level from the given Context.
 func FromContext(ctx context.Context) (Level, bool) {
+	if ctx == nil {
+		return 0, false
+	}
 	x, ok := ctx.Value(levelKey).(Level)
 	return x, ok
 }
 
-// this is rubbish, but it silences "go vet"s complaints about lack of format specifiers,
-// and it's a dumb enough func that the golang toolchain can optimize this away
-func govetIgnoreFormat() string { return "" }
-
-type loggers struct {
+// loggerTable skips the construction of a Context for disabled levels: those levels are always
+// backed by logger.Null() and there's no point in generating context that would be discarded.
+type loggerTable struct {
 	ctxf   context.Getter
 	debugf logger.Logger
 	infof  logger.Logger
@@ -94,41 +245,273 @@ type loggers struct {
 	panicf logger.Logger
 }
 
+// loggers holds a *loggerTable that may be atomically replaced, see Reconfigure. Instances
+// derived via WithContext share the same table and apply additional context decorators.
+type loggers struct {
+	table    *atomic.Value
+	decorate context.Decorators
+}
+
+func (f *loggers) load() *loggerTable { return f.table.Load().(*loggerTable) }
+
+func (f *loggers) ctx(t *loggerTable) context.Context {
+	return f.decorate.Decorate(t.ctxf())
+}
+
 // Debugf implements Interface
-func (f *loggers) Debugf(m string, a ...interface{}) { f.debugf.Logf(f.ctxf(), m, a...) }
+func (f *loggers) Debugf(m string, a ...interface{}) {
+	if t := f.load(); !logger.IsNull(t.debugf) {
+		t.debugf.Logf(f.ctx(t), m, a...)
+	}
+}
 
 // Debug implements Interface
-func (f *loggers) Debug(a ...interface{}) { f.debugf.Logf(f.ctxf(), govetIgnoreFormat(), a...) }
+func (f *loggers) Debug(a ...interface{}) {
+	if t := f.load(); !logger.IsNull(t.debugf) {
+		t.debugf.Logf(f.ctx(t), "", a...)
+	}
+}
 
 // Infof implements Interface
-func (f *loggers) Infof(m string, a ...interface{}) { f.infof.Logf(f.ctxf(), m, a...) }
+func (f *loggers) Infof(m string, a ...interface{}) {
+	if t := f.load(); !logger.IsNull(t.infof) {
+		t.infof.Logf(f.ctx(t), m, a...)
+	}
+}
 
 // Info implements Interface
-func (f *loggers) Info(a ...interface{}) { f.infof.Logf(f.ctxf(), govetIgnoreFormat(), a...) }
+func (f *loggers) Info(a ...interface{}) {
+	if t := f.load(); !logger.IsNull(t.infof) {
+		t.infof.Logf(f.ctx(t), "", a...)
+	}
+}
 
 // Warnf implements Interface
-func (f *loggers) Warnf(m string, a ...interface{}) { f.warnf.Logf(f.ctxf(), m, a...) }
+func (f *loggers) Warnf(m string, a ...interface{}) {
+	if t := f.load(); !logger.IsNull(t.warnf) {
+		t.warnf.Logf(f.ctx(t), m, a...)
+	}
+}
 
 // Warn implements Interface
-func (f *loggers) Warn(a ...interface{}) { f.warnf.Logf(f.ctxf(), govetIgnoreFormat(), a...) }
+func (f *loggers) Warn(a ...interface{}) {
+	if t := f.load(); !logger.IsNull(t.warnf) {
+		t.warnf.Logf(f.ctx(t), "", a...)
+	}
+}
 
 // Errorf implements Interface
-func (f *loggers) Errorf(m string, a ...interface{}) { f.errorf.Logf(f.ctxf(), m, a...) }
+func (f *loggers) Errorf(m string, a ...interface{}) {
+	if t := f.load(); !logger.IsNull(t.errorf) {
+		t.errorf.Logf(f.ctx(t), m, a...)
+	}
+}
 
 // Error implements Interface
-func (f *loggers) Error(a ...interface{}) { f.errorf.Logf(f.ctxf(), govetIgnoreFormat(), a...) }
+func (f *loggers) Error(a ...interface{}) {
+	if t := f.load(); !logger.IsNull(t.errorf) {
+		t.errorf.Logf(f.ctx(t), "", a...)
+	}
+}
 
 // Fatalf implements Interface
-func (f *loggers) Fatalf(m string, a ...interface{}) { f.fatalf.Logf(f.ctxf(), m, a...) }
+func (f *loggers) Fatalf(m string, a ...interface{}) {
+	if t := f.load(); !logger.IsNull(t.fatalf) {
+		t.fatalf.Logf(f.ctx(t), m, a...)
+	}
+}
 
 // Fatal implements Interface
-func (f *loggers) Fatal(a ...interface{}) { f.fatalf.Logf(f.ctxf(), govetIgnoreFormat(), a...) }
+func (f *loggers) Fatal(a ...interface{}) {
+	if t := f.load(); !logger.IsNull(t.fatalf) {
+		t.fatalf.Logf(f.ctx(t), "", a...)
+	}
+}
 
 // Panicf implements Interface
-func (f *loggers) Panicf(m string, a ...interface{}) { f.panicf.Logf(f.ctxf(), m, a...) }
+func (f *loggers) Panicf(m string, a ...interface{}) {
+	if t := f.load(); !logger.IsNull(t.panicf) {
+		t.panicf.Logf(f.ctx(t), m, a...)
+	}
+}
 
 // Panic implements Interface
-func (f *loggers) Panic(a ...interface{}) { f.panicf.Logf(f.ctxf(), govetIgnoreFormat(), a...) }
+func (f *loggers) Panic(a ...interface{}) {
+	if t := f.load(); !logger.IsNull(t.panicf) {
+		t.panicf.Logf(f.ctx(t), "", a...)
+	}
+}
+
+// DebugfE implements InterfaceE
+func (f *loggers) DebugfE(m string, a ...interface{}) (err error) {
+	if t := f.load(); !logger.IsNull(t.debugf) {
+		c, errf := logger.CaptureErrors(f.ctx(t))
+		t.debugf.Logf(c, m, a...)
+		err = errf()
+	}
+	return
+}
+
+// InfofE implements InterfaceE
+func (f *loggers) InfofE(m string, a ...interface{}) (err error) {
+	if t := f.load(); !logger.IsNull(t.infof) {
+		c, errf := logger.CaptureErrors(f.ctx(t))
+		t.infof.Logf(c, m, a...)
+		err = errf()
+	}
+	return
+}
+
+// WarnfE implements InterfaceE
+func (f *loggers) WarnfE(m string, a ...interface{}) (err error) {
+	if t := f.load(); !logger.IsNull(t.warnf) {
+		c, errf := logger.CaptureErrors(f.ctx(t))
+		t.warnf.Logf(c, m, a...)
+		err = errf()
+	}
+	return
+}
+
+// ErrorfE implements InterfaceE
+func (f *loggers) ErrorfE(m string, a ...interface{}) (err error) {
+	if t := f.load(); !logger.IsNull(t.errorf) {
+		c, errf := logger.CaptureErrors(f.ctx(t))
+		t.errorf.Logf(c, m, a...)
+		err = errf()
+	}
+	return
+}
+
+// withError returns the context of a log event generated by a method of InterfaceErr
+func (f *loggers) withError(t *loggerTable, err error, ff []fields.Field) context.Context {
+	c := f.ctx(t)
+	if err != nil {
+		ff = append([]fields.Field{fields.F("error", err)}, ff...)
+	}
+	return fields.NewContext(c, ff...)
+}
+
+// WarnErr implements InterfaceErr
+func (f *loggers) WarnErr(err error, m string, ff ...fields.Field) {
+	if t := f.load(); !logger.IsNull(t.warnf) {
+		t.warnf.Logf(f.withError(t, err, ff), "", m)
+	}
+}
+
+// ErrorErr implements InterfaceErr
+func (f *loggers) ErrorErr(err error, m string, ff ...fields.Field) {
+	if t := f.load(); !logger.IsNull(t.errorf) {
+		t.errorf.Logf(f.withError(t, err, ff), "", m)
+	}
+}
+
+// Debugw implements InterfaceW
+func (f *loggers) Debugw(m string, kv ...interface{}) {
+	if t := f.load(); !logger.IsNull(t.debugf) {
+		t.debugf.Logf(fields.NewContext(f.ctx(t), fields.KV(kv...)...), "", m)
+	}
+}
+
+// Infow implements InterfaceW
+func (f *loggers) Infow(m string, kv ...interface{}) {
+	if t := f.load(); !logger.IsNull(t.infof) {
+		t.infof.Logf(fields.NewContext(f.ctx(t), fields.KV(kv...)...), "", m)
+	}
+}
+
+// Warnw implements InterfaceW
+func (f *loggers) Warnw(m string, kv ...interface{}) {
+	if t := f.load(); !logger.IsNull(t.warnf) {
+		t.warnf.Logf(fields.NewContext(f.ctx(t), fields.KV(kv...)...), "", m)
+	}
+}
+
+// Errorw implements InterfaceW
+func (f *loggers) Errorw(m string, kv ...interface{}) {
+	if t := f.load(); !logger.IsNull(t.errorf) {
+		t.errorf.Logf(fields.NewContext(f.ctx(t), fields.KV(kv...)...), "", m)
+	}
+}
+
+// Fatalw implements InterfaceW
+func (f *loggers) Fatalw(m string, kv ...interface{}) {
+	if t := f.load(); !logger.IsNull(t.fatalf) {
+		t.fatalf.Logf(fields.NewContext(f.ctx(t), fields.KV(kv...)...), "", m)
+	}
+}
+
+// Panicw implements InterfaceW
+func (f *loggers) Panicw(m string, kv ...interface{}) {
+	if t := f.load(); !logger.IsNull(t.panicf) {
+		t.panicf.Logf(fields.NewContext(f.ctx(t), fields.KV(kv...)...), "", m)
+	}
+}
+
+// Enabled implements Enabler
+func (f *loggers) Enabled(lvl Level) bool {
+	var (
+		t    = f.load()
+		logs logger.Logger
+	)
+	switch lvl {
+	case Debug:
+		logs = t.debugf
+	case Info:
+		logs = t.infof
+	case Warn:
+		logs = t.warnf
+	case Error:
+		logs = t.errorf
+	case Fatal:
+		logs = t.fatalf
+	case Panic:
+		logs = t.panicf
+	default:
+		return false
+	}
+	return !logger.IsNull(logs)
+}
+
+// Reconfigure atomically replaces the loggers that back Interface `dst` with those that back
+// Interface `src`, such that clients already holding a reference to `dst` observe the change.
+// Returns false if either Interface was not generated by WithLoggers, in which case `dst` is
+// not modified.
+func Reconfigure(dst, src Interface) bool {
+	d, ok := dst.(*loggers)
+	if !ok {
+		return false
+	}
+	s, ok := src.(*loggers)
+	if !ok {
+		return false
+	}
+	d.table.Store(s.load())
+	return true
+}
+
+// WithContext returns an Interface that decorates the context of every log event generated
+// by `i` with the given decorators, for example to annotate log events with request-scoped
+// values. The returned Interface observes changes made to `i` via Reconfigure. If `i` was not
+// generated by WithLoggers (or WithContext) then it's returned unmodified.
+func WithContext(i Interface, d ...context.Decorator) Interface {
+	f, ok := i.(*loggers)
+	if !ok || len(d) == 0 {
+		return i
+	}
+	dd := make(context.Decorators, 0, len(f.decorate)+len(d))
+	return &loggers{
+		table:    f.table,
+		decorate: append(append(dd, f.decorate...), d...),
+	}
+}
+
+// WithCallDepth returns an Interface that adjusts the call depth at which the Caller of every
+// log event generated by `i` is looked up by `n` frames (see caller.Skip). Bridges that forward
+// log events from other logging APIs to `i` should use it to account for their own frames, so
+// that caller information identifies the code that invoked the bridge. See WithContext.
+func WithCallDepth(i Interface, n int) Interface {
+	return WithContext(i, func(c context.Context) context.Context { return caller.Skip(c, n) })
+}
 
 // WithLoggers is a factory function, it generates an instance of Interface using the Logger
 // instances found in the provided Indexer. If a requisite Logger is not found by the Indexer
@@ -141,7 +524,8 @@ func WithLoggers(ctxf context.Getter, index Indexer) Interface {
 		}
 		return logs
 	}
-	return &loggers{
+	f := &loggers{table: new(atomic.Value)}
+	f.table.Store(&loggerTable{
 		ctxf,
 		t(Debug),
 		t(Info),
@@ -149,7 +533,16 @@ func WithLoggers(ctxf context.Getter, index Indexer) Interface {
 		t(Error),
 		t(Fatal),
 		t(Panic),
-	}
+	})
+	return f
+}
+
+// Discard returns an Interface that discards all log events without generating their contexts:
+// every level is backed by logger.Null, and Fatal and Panic neither exit nor panic. It's useful
+// as a default for libraries, and to silence logging in benchmarks. Like other Interfaces that
+// are generated by WithLoggers, it may be reconfigured later, see Reconfigure.
+func Discard() Interface {
+	return WithLoggers(context.TODO, IndexerFunc(func(Level) (logger.Logger, bool) { return logger.Null(), true }))
 }
 
 // MinThreshold generates a transform that only logs messages at or above the `min` Level.
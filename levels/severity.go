@@ -0,0 +1,103 @@
+/*
+Copyright 2016 James DeFelice
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package levels
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
+)
+
+// Severities of the supported Levels; they're spaced apart so that custom Levels may be ranked
+// in between, see RegisterSeverity.
+const (
+	DebugSeverity = 10 * (iota + 1)
+	InfoSeverity
+	WarnSeverity
+	ErrorSeverity
+	FatalSeverity
+	PanicSeverity
+)
+
+var (
+	severitiesMu sync.Mutex
+	severities   atomic.Value // severities is a map[Level]int, guarded by severitiesMu
+)
+
+func init() {
+	severities.Store(map[Level]int{
+		Debug: DebugSeverity,
+		Info:  InfoSeverity,
+		Warn:  WarnSeverity,
+		Error: ErrorSeverity,
+		Fatal: FatalSeverity,
+		Panic: PanicSeverity,
+	})
+}
+
+// RegisterSeverity ranks a custom Level, a single bit flag other than those of the supported
+// Levels, such that filters which order Levels (see Severity) order it as well: for example,
+// a "notice" Level between Info and Warn would be ranked at InfoSeverity+5. It panics if `l`
+// isn't a single bit, or if the severity isn't positive. It's typically invoked by an init func.
+func RegisterSeverity(l Level, severity int) {
+	if l <= 0 || l&(l-1) != 0 || severity <= 0 {
+		panic(fmt.Sprintf("levels: cannot rank %v at severity %d", l, severity))
+	}
+	severitiesMu.Lock()
+	defer severitiesMu.Unlock()
+	old := severities.Load().(map[Level]int)
+	m := make(map[Level]int, len(old)+1)
+	for k, v := range old {
+		m[k] = v
+	}
+	m[l] = severity
+	severities.Store(m)
+}
+
+// Severity returns the rank of the Level in order of increasing severity, from DebugSeverity to
+// PanicSeverity, including custom Levels (see RegisterSeverity). Because Levels are bit flags
+// their numeric values are not meant to be compared; filters that order Levels, such as
+// MatchAtOrAbove, compare their Severity instead. A mask of Levels is ranked by the least
+// severe of them, and unranked Levels have a Severity of 0.
+func (l Level) Severity() int {
+	m := severities.Load().(map[Level]int)
+	if s, ok := m[l]; ok {
+		return s
+	}
+	min := 0
+	for rest := l; rest > 0; {
+		x := rest & -rest // the lowest set bit
+		rest &^= x
+		if s, ok := m[x]; ok && (min == 0 || s < min) {
+			min = s
+		}
+	}
+	return min
+}
+
+// All returns the ranked Levels, including custom ones (see RegisterSeverity), in order of
+// increasing severity.
+func All() []Level {
+	m := severities.Load().(map[Level]int)
+	all := make([]Level, 0, len(m))
+	for l := range m {
+		all = append(all, l)
+	}
+	sort.Slice(all, func(i, j int) bool { return m[all[i]] < m[all[j]] })
+	return all
+}
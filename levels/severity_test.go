@@ -0,0 +1,103 @@
+/*
+Copyright 2016 James DeFelice
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package levels_test
+
+import (
+	"reflect"
+	"testing"
+
+	. "github.com/gologs/log/levels"
+)
+
+// notice is a custom level, ranked between Info and Warn
+const notice = Level(1 << 20)
+
+func init() { RegisterSeverity(notice, InfoSeverity+5) }
+
+func TestSeverity(t *testing.T) {
+	for i, tc := range []struct {
+		lvl      Level
+		expected int
+	}{
+		{Debug, DebugSeverity},
+		{Info, InfoSeverity},
+		{Warn, WarnSeverity},
+		{Error, ErrorSeverity},
+		{Fatal, FatalSeverity},
+		{Panic, PanicSeverity},
+		{notice, InfoSeverity + 5},
+		{Warn | Error, WarnSeverity}, // masks rank by their least severe level
+		{Panic | Info, InfoSeverity}, // regardless of bit order
+		{Error | notice, InfoSeverity + 5},
+		{Level(1 << 30), 0}, // unranked
+		{Warn | Level(1<<30), WarnSeverity},
+		{0, 0},
+		{-1, 0},
+	} {
+		if s := tc.lvl.Severity(); s != tc.expected {
+			t.Errorf("test case %d: expected severity %d for %v instead of %d", i, tc.expected, tc.lvl, s)
+		}
+	}
+}
+
+func TestMatchAtOrAbove(t *testing.T) {
+	for i, tc := range []struct {
+		min      Level
+		expected []Level
+	}{
+		{Debug, []Level{Debug, Info, notice, Warn, Error, Fatal, Panic}},
+		{Warn, []Level{Warn, Error, Fatal, Panic}},
+		{Warn | Error, []Level{Warn, Error, Fatal, Panic}},
+		{notice, []Level{notice, Warn, Error, Fatal, Panic}},
+		{Panic, []Level{Panic}},
+		{Level(1 << 30), []Level{Level(1 << 30)}}, // unranked levels match only themselves
+	} {
+		var matched []Level
+		f := MatchAtOrAbove(tc.min)
+		for _, lvl := range append(All(), Level(1<<30)) {
+			if f(lvl) {
+				matched = append(matched, lvl)
+			}
+		}
+		if !reflect.DeepEqual(matched, tc.expected) {
+			t.Errorf("test case %d: expected %v to match %v instead of %v", i, tc.min, tc.expected, matched)
+		}
+	}
+}
+
+func TestRegisterSeverity(t *testing.T) {
+	if expected := []Level{Debug, Info, notice, Warn, Error, Fatal, Panic}; !reflect.DeepEqual(All(), expected) {
+		t.Fatalf("expected levels %v instead of %v", expected, All())
+	}
+	for _, tc := range []struct {
+		lvl      Level
+		severity int
+	}{
+		{Warn | Error, 1},
+		{0, 1},
+		{Level(1 << 21), 0},
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("expected RegisterSeverity(%v, %d) to panic", tc.lvl, tc.severity)
+				}
+			}()
+			RegisterSeverity(tc.lvl, tc.severity)
+		}()
+	}
+}
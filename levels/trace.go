@@ -0,0 +1,139 @@
+/*
+Copyright 2016 James DeFelice
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package levels
+
+import (
+	"container/list"
+	"sync"
+
+	"github.com/gologs/log/context"
+	"github.com/gologs/log/logger"
+)
+
+const (
+	// DefaultTraceDepth is the number of log events retained per key by TraceOnError.
+	DefaultTraceDepth = 100
+
+	// DefaultTraceKeys is the number of keys for which TraceOnError retains log events.
+	DefaultTraceKeys = 1000
+)
+
+type traceEvent struct {
+	ctx  context.Context
+	m    string
+	a    []interface{}
+	logs logger.Logger
+}
+
+type traceBuffer struct {
+	key    string
+	events []traceEvent
+}
+
+// traces is a LRU cache of per-key event buffers
+type traces struct {
+	sync.Mutex
+	depth, max int
+	lru        *list.List
+	byKey      map[string]*list.Element
+}
+
+func (t *traces) record(key string, e traceEvent) {
+	t.Lock()
+	defer t.Unlock()
+	el, ok := t.byKey[key]
+	if ok {
+		t.lru.MoveToFront(el)
+	} else {
+		if t.lru.Len() >= t.max {
+			oldest := t.lru.Back()
+			delete(t.byKey, t.lru.Remove(oldest).(*traceBuffer).key)
+		}
+		el = t.lru.PushFront(&traceBuffer{key: key})
+		t.byKey[key] = el
+	}
+	b := el.Value.(*traceBuffer)
+	if len(b.events) >= t.depth {
+		copy(b.events, b.events[1:])
+		b.events = b.events[:len(b.events)-1]
+	}
+	b.events = append(b.events, e)
+}
+
+func (t *traces) take(key string) []traceEvent {
+	t.Lock()
+	defer t.Unlock()
+	el, ok := t.byKey[key]
+	if !ok {
+		return nil
+	}
+	delete(t.byKey, key)
+	return t.lru.Remove(el).(*traceBuffer).events
+}
+
+// TraceOnError returns a threshold TransformOp (see MinThreshold) that, instead of discarding
+// log events below `min`, retains the most recent `depth` of them for each key (as extracted
+// from the context of each event by `key`, for example a request ID). When a log event at Error
+// or above is logged for some key, the events retained for that key are logged first, at their
+// original levels, providing a trace of the activity that led up to the failure without logging
+// such events globally. Events below `min` that lack a key are discarded. A `depth` <= 0 selects
+// DefaultTraceDepth; events are retained for (at most) DefaultTraceKeys of the most recently
+// used keys.
+//
+// Retained events are logged with their original context, but the arguments of such events are
+// not copied and so should not be modified after they're logged.
+func TraceOnError(min Level, key func(context.Context) (string, bool), depth int) TransformOp {
+	if depth <= 0 {
+		depth = DefaultTraceDepth
+	}
+	t := &traces{
+		depth: depth,
+		max:   DefaultTraceKeys,
+		lru:   list.New(),
+		byKey: make(map[string]*list.Element),
+	}
+	keyOf := func(c context.Context) (string, bool) {
+		if c == nil || key == nil {
+			return "", false
+		}
+		return key(c)
+	}
+	return func(x Level, logs logger.Logger) (Level, logger.Logger) {
+		switch {
+		case x.Severity() < min.Severity():
+			if logger.IsNull(logs) {
+				return x, logs
+			}
+			return x, logger.Func(func(c context.Context, m string, a ...interface{}) {
+				if k, ok := keyOf(c); ok {
+					t.record(k, traceEvent{ctx: c, m: m, a: append([]interface{}(nil), a...), logs: logs})
+				}
+			})
+		case x.Severity() >= Error.Severity():
+			return x, logger.Func(func(c context.Context, m string, a ...interface{}) {
+				if k, ok := keyOf(c); ok {
+					for _, e := range t.take(k) {
+						e.logs.Logf(e.ctx, e.m, e.a...)
+					}
+				}
+				logs.Logf(c, m, a...)
+			})
+		default:
+			return x, logs
+		}
+	}
+}
@@ -18,46 +18,118 @@ package log
 
 import (
 	"github.com/gologs/log/config"
+	"github.com/gologs/log/context/fields"
+	"github.com/gologs/log/levels"
 )
 
+// Enabled returns true if log events at the given level would actually be logged; use it
+// to avoid building expensive log arguments that would otherwise be discarded.
+func Enabled(lvl levels.Level) bool { return levels.Enabled(config.Logging(), lvl) }
+
 // Debugf logs at levels.Debug
-func Debugf(msg string, args ...interface{}) { config.Logging.Debugf(msg, args...) }
+func Debugf(msg string, args ...interface{}) { config.Logging().Debugf(msg, args...) }
 
 // Debug logs at levels.Debug
-func Debug(args ...interface{}) { config.Logging.Debug(args...) }
+func Debug(args ...interface{}) { config.Logging().Debug(args...) }
 
 // Infof logs at levels.Info
-func Infof(msg string, args ...interface{}) { config.Logging.Infof(msg, args...) }
+func Infof(msg string, args ...interface{}) { config.Logging().Infof(msg, args...) }
 
 // Info logs at levels.Info
-func Info(args ...interface{}) { config.Logging.Info(args...) }
+func Info(args ...interface{}) { config.Logging().Info(args...) }
 
 // Warnf logs at levels.Warn
-func Warnf(msg string, args ...interface{}) { config.Logging.Warnf(msg, args...) }
+func Warnf(msg string, args ...interface{}) { config.Logging().Warnf(msg, args...) }
 
 // Warn logs at levels.Warn
-func Warn(args ...interface{}) { config.Logging.Warn(args...) }
+func Warn(args ...interface{}) { config.Logging().Warn(args...) }
 
 // Errorf logs at levels.Error
-func Errorf(msg string, args ...interface{}) { config.Logging.Errorf(msg, args...) }
+func Errorf(msg string, args ...interface{}) { config.Logging().Errorf(msg, args...) }
 
 // Error logs at levels.Error
-func Error(args ...interface{}) { config.Logging.Error(args...) }
+func Error(args ...interface{}) { config.Logging().Error(args...) }
+
+// DebugfE logs at levels.Debug and returns the error, if any, reported by the sink
+func DebugfE(msg string, args ...interface{}) error {
+	return levels.E(config.Logging()).DebugfE(msg, args...)
+}
+
+// InfofE logs at levels.Info and returns the error, if any, reported by the sink
+func InfofE(msg string, args ...interface{}) error {
+	return levels.E(config.Logging()).InfofE(msg, args...)
+}
+
+// WarnfE logs at levels.Warn and returns the error, if any, reported by the sink
+func WarnfE(msg string, args ...interface{}) error {
+	return levels.E(config.Logging()).WarnfE(msg, args...)
+}
+
+// ErrorfE logs at levels.Error and returns the error, if any, reported by the sink
+func ErrorfE(msg string, args ...interface{}) error {
+	return levels.E(config.Logging()).ErrorfE(msg, args...)
+}
+
+// WarnErr logs the message at levels.Warn, along with the error and fields, see levels.InterfaceErr
+func WarnErr(err error, msg string, f ...fields.Field) {
+	levels.Err(config.Logging()).WarnErr(err, msg, f...)
+}
+
+// ErrorErr logs the message at levels.Error, along with the error and fields, see levels.InterfaceErr
+func ErrorErr(err error, msg string, f ...fields.Field) {
+	levels.Err(config.Logging()).ErrorErr(err, msg, f...)
+}
+
+// Debugw logs the message at levels.Debug, along with fields given as alternating keys and
+// values, see levels.InterfaceW
+func Debugw(msg string, keysAndValues ...interface{}) {
+	levels.W(config.Logging()).Debugw(msg, keysAndValues...)
+}
+
+// Infow logs the message at levels.Info, along with fields given as alternating keys and
+// values, see levels.InterfaceW
+func Infow(msg string, keysAndValues ...interface{}) {
+	levels.W(config.Logging()).Infow(msg, keysAndValues...)
+}
+
+// Warnw logs the message at levels.Warn, along with fields given as alternating keys and
+// values, see levels.InterfaceW
+func Warnw(msg string, keysAndValues ...interface{}) {
+	levels.W(config.Logging()).Warnw(msg, keysAndValues...)
+}
+
+// Errorw logs the message at levels.Error, along with fields given as alternating keys and
+// values, see levels.InterfaceW
+func Errorw(msg string, keysAndValues ...interface{}) {
+	levels.W(config.Logging()).Errorw(msg, keysAndValues...)
+}
 
 // Fatalf logs at levels.Fatal
-func Fatalf(msg string, args ...interface{}) { config.Logging.Fatalf(msg, args...) }
+func Fatalf(msg string, args ...interface{}) { config.Logging().Fatalf(msg, args...) }
 
 // Fatal logs at levels.Fatal
-func Fatal(args ...interface{}) { config.Logging.Fatal(args...) }
+func Fatal(args ...interface{}) { config.Logging().Fatal(args...) }
 
 // Panicf logs at levels.Panic
-func Panicf(msg string, args ...interface{}) { config.Logging.Panicf(msg, args...) }
+func Panicf(msg string, args ...interface{}) { config.Logging().Panicf(msg, args...) }
 
 // Panic logs at levels.Panic
-func Panic(args ...interface{}) { config.Logging.Panic(args...) }
+func Panic(args ...interface{}) { config.Logging().Panic(args...) }
+
+// Fatalw logs the message at levels.Fatal, along with fields given as alternating keys and
+// values, see levels.InterfaceW
+func Fatalw(msg string, keysAndValues ...interface{}) {
+	levels.W(config.Logging()).Fatalw(msg, keysAndValues...)
+}
+
+// Panicw logs the message at levels.Panic, along with fields given as alternating keys and
+// values, see levels.InterfaceW
+func Panicw(msg string, keysAndValues ...interface{}) {
+	levels.W(config.Logging()).Panicw(msg, keysAndValues...)
+}
 
 // Logf is an alias for Infof
-func Logf(msg string, args ...interface{}) { config.Logging.Infof(msg, args...) }
+func Logf(msg string, args ...interface{}) { config.Logging().Infof(msg, args...) }
 
 // Log is an alias for Info
-func Log(args ...interface{}) { config.Logging.Info(args...) }
+func Log(args ...interface{}) { config.Logging().Info(args...) }
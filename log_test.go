@@ -27,39 +27,39 @@ import (
 	"github.com/gologs/log/caller"
 	"github.com/gologs/log/config"
 	"github.com/gologs/log/context"
-	"github.com/gologs/log/encoding"
+	"github.com/gologs/log/context/requestid"
 	"github.com/gologs/log/io"
 	"github.com/gologs/log/io/ioutil"
 	"github.com/gologs/log/levels"
+	"github.com/gologs/log/logapi"
 	"github.com/gologs/log/logger"
 	"github.com/gologs/log/logger/redact"
 )
 
 func Example_withCustomLogger() {
 	var (
-		logs    = []string{}
+		logs    = &io.SafeBufferStream{}
 		flogger = logger.Func(func(_ context.Context, m string, a ...interface{}) {
 			if m == "" {
-				logs = append(logs, fmt.Sprint(a...))
+				fmt.Fprint(logs, a...)
 			} else {
-				logs = append(logs, fmt.Sprintf(m, a...))
+				fmt.Fprintf(logs, m, a...)
 			}
+			_ = logs.EOM(nil)
 		})
 	)
 
 	// swap out the default logger
-	config.Logging = config.DefaultConfig.With(config.Logger(flogger))
+	config.SetLogging(config.DefaultConfig.With(config.Logger(flogger)))
 	log.Debugf("I can count 1 2 %d", 3)
 	log.Logf("and more 4 5 %d", 6)
 
-	govetIgnoreFormatString := func() string { return "7 %%" }
-	log.Log(govetIgnoreFormatString(), 8, 9)
+	// print-style funcs never interpret their args as a message format
+	log.Log("7 %%", 8, 9)
 
 	// print what we logged
-	fmt.Printf("%d\n", len(logs))
-	for i := range logs {
-		fmt.Println(logs[i])
-	}
+	fmt.Printf("%d\n", len(logs.Lines()))
+	fmt.Print(logs)
 
 	// Output:
 	// 2
@@ -69,25 +69,16 @@ func Example_withCustomLogger() {
 
 func Example_withCustomStream() {
 	var (
-		logs   = []string{}
-		stream = &io.BufferedStream{
-			EOMFunc: func(b io.Buffer, err error) error {
-				if err != nil {
-					return err
-				}
-				logs = append(logs, b.String())
-				return nil
-			},
-		}
+		stream = &io.SafeBufferStream{}
 	)
 
 	// swap out the default logger
-	config.Logging = config.DefaultConfig.With(
+	config.SetLogging(config.DefaultConfig.With(
 		config.OnPanic(config.NoPanic()),
 		config.OnExit(config.NoExit()),
 		config.Stream(stream),
 		config.Encoding(ioutil.Level()),
-	)
+	))
 	log.Debugf("I can count 1 2 %d", 3)
 	log.Infof("and more 4 5 %d", 6)
 	log.Warnf("and more 5 6 %d", 7)
@@ -103,10 +94,8 @@ func Example_withCustomStream() {
 	log.Panic("panic w/o", "format")
 
 	// print what we logged
-	fmt.Printf("%d\n", len(logs))
-	for i := range logs {
-		fmt.Println(logs[i])
-	}
+	fmt.Printf("%d\n", len(stream.Lines()))
+	fmt.Print(stream)
 
 	// Output:
 	// 10
@@ -124,16 +113,7 @@ func Example_withCustomStream() {
 
 func Example_withCustomMarshaler() {
 	var (
-		logs   = []string{}
-		stream = &io.BufferedStream{
-			EOMFunc: func(b io.Buffer, err error) error {
-				if err != nil {
-					return err
-				}
-				logs = append(logs, b.String())
-				return nil
-			},
-		}
+		stream = &io.SafeBufferStream{}
 		// key=value marshaler
 		marshaler = func(ctx context.Context, w io.Stream, m string, a ...interface{}) (err error) {
 			caller, ok := caller.FromContext(ctx)
@@ -162,24 +142,22 @@ func Example_withCustomMarshaler() {
 	)
 
 	// swap out the default logger
-	config.Logging = config.DefaultConfig.With(
+	config.SetLogging(config.DefaultConfig.With(
 		config.OnPanic(config.NoPanic()),
 		config.OnExit(config.NoExit()),
 		config.Stream(stream),
 		config.Marshaler(marshaler),
 		config.Encoding(ioutil.Level()),
-	)
+	))
 	log.Info("k%", "v", "majorVersion", 1, "module", "storage")
 
 	// print what we logged
-	fmt.Printf("%d\n", len(logs))
-	for i := range logs {
-		fmt.Println(logs[i])
-	}
+	fmt.Printf("%d\n", len(stream.Lines()))
+	fmt.Print(stream)
 
 	// Output:
 	// 1
-	// I{k%=v,majorVersion=1,module=storage,file=log_test.go,line=172,func=Example_withCustomMarshaler}
+	// I{k%=v,majorVersion=1,module=storage,file=log_test.go,line=152,func=Example_withCustomMarshaler}
 }
 
 type password struct {
@@ -204,8 +182,6 @@ func Example_withTextStream() {
 		tick      = func() { t = t.Add(time.Second) }
 		fakeClock = func() time.Time { return t }
 	)
-	config.Clock = fakeClock
-	defer func() { config.Clock = time.Now }() // restore the default clock when we're done
 
 	// illustates how to inject a logger.Decorator while making use of a custom stream with
 	// additional prefix decorators
@@ -213,9 +189,8 @@ func Example_withTextStream() {
 		config.Stream(io.NewBuffered(io.TextStream(os.Stdout))),
 		config.Encoding(ioutil.GlogHeader()),
 		config.Level(levels.Debug),
-		config.Builder(func(s io.Stream, m encoding.Marshaler, e chan<- error) logger.Logger {
-			return redact.Default(logger.WithStream(s, m, e))
-		}))
+		config.Clock(fakeClock),
+		config.Builder(redact.Builder()))
 
 	log.Debugf("password=%v", &password{secret: "mysecret"})
 	tick()
@@ -225,3 +200,125 @@ func Example_withTextStream() {
 	// D0101 00:00:00.000000 password=xxREDACTEDxx
 	// D0101 00:00:01.000000 cc=xxxxxxxxxxxxxxxxxxx
 }
+
+func Example_enabled() {
+	restore := config.Update(config.Level(levels.Warn))
+	defer config.Update(restore)
+
+	fmt.Println(log.Enabled(levels.Debug))
+	fmt.Println(log.Enabled(levels.Info))
+	fmt.Println(log.Enabled(levels.Warn))
+	fmt.Println(log.Enabled(levels.Panic))
+
+	// Output:
+	// false
+	// false
+	// true
+	// true
+}
+
+func Example_apply() {
+	restore := config.Update(config.Level(levels.Warn))
+	defer config.Update(restore)
+
+	// libraries may hold on to a reference to the logging instance
+	logs := config.Logging()
+	fmt.Println(levels.Enabled(logs, levels.Debug))
+
+	// ... and observe later changes to it
+	config.Apply(config.Level(levels.Debug))
+	fmt.Println(levels.Enabled(logs, levels.Debug))
+
+	// Output:
+	// false
+	// true
+}
+
+func Example_withRequestID() {
+	var (
+		logs = config.DefaultConfig.With(
+			config.Stream(io.TextStream(os.Stdout)),
+			config.Encoding(ioutil.RequestID()),
+		)
+		requestLogs = levels.WithContext(logs, requestid.NewDecorator("1234"))
+	)
+	logs.Info("starting up")
+	requestLogs.Infof("handling request")
+
+	// Output:
+	// starting up
+	// [1234] handling request
+}
+
+func Example_withContextPredicate() {
+	type debugKey struct{}
+	var (
+		debugRequest = func(c context.Context) bool { return c.Value(debugKey{}) != nil }
+		logs         = config.DefaultConfig.With(
+			config.Stream(io.TextStream(os.Stdout)),
+			config.Level(levels.Debug),
+			config.TransformOps(
+				levels.Only(levels.MatchExact(levels.Debug), levels.AcceptContext(debugRequest)),
+			),
+		)
+	)
+	logs.Debug("not logged")
+	levels.WithContext(logs, context.NewDecorator(debugKey{}, true)).Debug("logged")
+
+	// Output:
+	// logged
+}
+
+// client is a library type that depends on logapi only
+type client struct {
+	Log logapi.Interface // Log is optional
+}
+
+func (c *client) connect() { logapi.OrNop(c.Log).Infof("connecting to %s", "db:5432") }
+
+func Example_api() {
+	restore := config.Update(config.Logger(logger.Func(func(c context.Context, m string, a ...interface{}) {
+		x, _ := caller.FromContext(c)
+		fmt.Printf("%s: %s\n", filepath.Base(x.File), fmt.Sprintf(m, a...))
+	})))
+	defer config.Update(restore)
+
+	(&client{}).connect() // discarded
+	(&client{Log: log.API()}).connect()
+
+	// Output:
+	// log_test.go: connecting to db:5432
+}
+
+func Example_discard() {
+	logs := levels.Discard()
+	logs.Errorf("never %s", "seen")
+	logs.Fatal("doesn't exit")
+	fmt.Println(levels.Enabled(logs, levels.Error))
+
+	// Output:
+	// false
+}
+
+func Example_override() {
+	printer := func(prefix string) logger.Logger {
+		return logger.Func(func(_ context.Context, m string, a ...interface{}) {
+			fmt.Printf(prefix+m+"\n", a...)
+		})
+	}
+	var (
+		queued = levels.IndexerFunc(func(levels.Level) (logger.Logger, bool) { return printer("queued: "), true })
+		fatal  = levels.Override(queued, map[levels.Level]logger.Logger{levels.Fatal: printer("sync: ")})
+		logs   = levels.WithLoggers(context.TODO, levels.Chain(
+			levels.Override(nil, map[levels.Level]logger.Logger{levels.Debug: nil}), // discard Debug
+			fatal,
+		))
+	)
+	logs.Debugf("not %s", "seen")
+	logs.Infof("starting %d workers", 4)
+	logs.Fatalf("giving up after %d attempts", 3)
+
+	// Output:
+	// queued: starting 4 workers
+	// sync: giving up after 3 attempts
+}
@@ -0,0 +1,69 @@
+/*
+Copyright 2016 James DeFelice
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package logapi defines the leveled logging interface for libraries to depend on. It has no
+// dependencies, so that libraries needn't pull in the configuration and I/O machinery of the
+// rest of this module; applications inject an implementation, such as log.API() or any
+// levels.Interface (which has the same methods). For example:
+//
+//	type Client struct {
+//		Log logapi.Interface // Log is optional
+//	}
+//
+//	func (c *Client) log() logapi.Interface { return logapi.OrNop(c.Log) }
+package logapi
+
+// Interface is the canonical leveled logging interface, identical to levels.Interface.
+type Interface interface {
+	Debugf(string, ...interface{}) // Debugf signifies a Debug level message
+	Debug(...interface{})          // Debug signifies a Debug level message, without a message format
+	Infof(string, ...interface{})  // Infof signifies an Info level message
+	Info(...interface{})           // Info signifies an Info level message, without a message format
+	Warnf(string, ...interface{})  // Warnf signifies an Warn level message
+	Warn(...interface{})           // Warn signifies an Warn level message, without a message format
+	Errorf(string, ...interface{}) // Errorf signifies an Error level message
+	Error(...interface{})          // Error signifies an Error level message, without a message format
+	Fatalf(string, ...interface{}) // Fatalf logs and then, typically, invokes an exit func
+	Fatal(...interface{})          // Fatal logs without a message format and then, typically, invokes an exit func
+	Panicf(string, ...interface{}) // Panicf logs and then, typically, invokes a panic func
+	Panic(...interface{})          // Panic logs without a message format and then, typically, invokes a panic func
+}
+
+type nop struct{}
+
+func (nop) Debugf(string, ...interface{}) {}
+func (nop) Debug(...interface{})          {}
+func (nop) Infof(string, ...interface{})  {}
+func (nop) Info(...interface{})           {}
+func (nop) Warnf(string, ...interface{})  {}
+func (nop) Warn(...interface{})           {}
+func (nop) Errorf(string, ...interface{}) {}
+func (nop) Error(...interface{})          {}
+func (nop) Fatalf(string, ...interface{}) {}
+func (nop) Fatal(...interface{})          {}
+func (nop) Panicf(string, ...interface{}) {}
+func (nop) Panic(...interface{})          {}
+
+// Nop returns an Interface that discards all log events; Fatal and Panic neither exit nor panic.
+func Nop() Interface { return nop{} }
+
+// OrNop returns `i`, or else Nop if `i` is nil.
+func OrNop(i Interface) Interface {
+	if i == nil {
+		return Nop()
+	}
+	return i
+}
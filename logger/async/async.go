@@ -0,0 +1,386 @@
+/*
+Copyright 2016 James DeFelice
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package async provides a Logger that hands log events off to a background goroutine, so that
+// callers don't block on slow sinks.
+package async
+
+import (
+	"container/list"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gologs/log/context"
+	"github.com/gologs/log/diag"
+	"github.com/gologs/log/encoding"
+	"github.com/gologs/log/levels"
+	"github.com/gologs/log/logger"
+	"github.com/gologs/log/stats"
+)
+
+const (
+	// DefaultCapacity is the default maximum number of queued log events.
+	DefaultCapacity = 1024
+	// DefaultExitTimeout is the default time allowed for queued log events to be delivered
+	// before a Fatal or Panic log event.
+	DefaultExitTimeout = 3 * time.Second
+	// DefaultEnqueueTimeout is the default time that a priority log event waits for room in
+	// the queue.
+	DefaultEnqueueTimeout = time.Second
+)
+
+// Options customize the behavior of a Logger.
+type Options struct {
+	// Capacity is the maximum number of queued log events, defaults to DefaultCapacity.
+	Capacity int
+	// Priority returns true for log events that must not be dropped. When the queue is full,
+	// a priority event evicts the oldest non-priority event, or else blocks until there's room
+	// (or until the context of the log event is done, or EnqueueTimeout elapses, in which case
+	// it's dropped). Non-priority events are dropped when the queue is full. Defaults to
+	// WarnOrAbove.
+	Priority func(context.Context) bool
+	// EnqueueTimeout is the time that a priority log event waits for room in the queue before
+	// it's dropped. Defaults to DefaultEnqueueTimeout; negative values wait indefinitely.
+	EnqueueTimeout time.Duration
+	// ExitTimeout is the time allowed for queued log events to be delivered before a Fatal or
+	// Panic log event, after which they're abandoned (see FlushTimeout) so that the process may
+	// exit, or panic, promptly. Defaults to DefaultExitTimeout; negative values wait indefinitely.
+	ExitTimeout time.Duration
+	// Stats, if not nil, registers the counters of the Logger (see Stats) as Name+".enqueued",
+	// Name+".dropped", Name+".evicted", and Name+".abandoned".
+	Stats *stats.Registry
+	// Name prefixes the names of registered counters, defaults to "async".
+	Name string
+}
+
+// WarnOrAbove returns true for log events at levels.Warn or above.
+func WarnOrAbove(c context.Context) bool {
+	if c == nil {
+		return false
+	}
+	lvl, ok := levels.FromContext(c)
+	return ok && lvl.Severity() >= levels.Warn.Severity()
+}
+
+// Stats is a snapshot of the counters maintained by a Logger.
+type Stats struct {
+	Enqueued uint64 // Enqueued counts log events accepted into the queue
+	Dropped  uint64 // Dropped counts log events rejected because the queue was full (or closed)
+	Evicted  uint64 // Evicted counts queued log events discarded in favor of priority events
+	// Abandoned counts queued log events discarded because they weren't delivered in time,
+	// see FlushTimeout
+	Abandoned uint64
+}
+
+type event struct {
+	c        context.Context
+	m        string
+	a        []interface{}
+	priority bool
+}
+
+// Logger queues log events for delivery to another Logger by a background goroutine. Fatal and
+// Panic log events are delivered synchronously, after the queue has drained (see ExitTimeout),
+// so that they're not lost when the process subsequently exits or panics.
+type Logger struct {
+	logs           logger.Logger
+	capacity       int
+	priority       func(context.Context) bool
+	exitTimeout    time.Duration
+	enqueueTimeout time.Duration
+
+	mu       sync.Mutex
+	cond     *sync.Cond // cond signals changes to queue, busy, and closed
+	queue    list.List
+	busy     bool // busy is true while the worker is delivering an event
+	running  bool // running is true while the worker goroutine runs, see run
+	closed   bool
+	dropping bool // dropping is true after an event is dropped, until one is enqueued
+
+	enqueued, dropped, evicted, abandoned uint64
+}
+
+var _ = logger.Logger(&Logger{}) // Logger implements logger.Logger
+
+// New returns a Logger that delivers log events to `logs` in the background; Close should be
+// invoked to flush the queue. The background goroutine runs only while log events are queued,
+// so a Logger that's discarded without being closed doesn't leak it.
+func New(logs logger.Logger, opts Options) *Logger {
+	if opts.Capacity <= 0 {
+		opts.Capacity = DefaultCapacity
+	}
+	if opts.Priority == nil {
+		opts.Priority = WarnOrAbove
+	}
+	if opts.ExitTimeout == 0 {
+		opts.ExitTimeout = DefaultExitTimeout
+	}
+	if opts.EnqueueTimeout == 0 {
+		opts.EnqueueTimeout = DefaultEnqueueTimeout
+	}
+	l := &Logger{
+		logs:           logs,
+		capacity:       opts.Capacity,
+		priority:       opts.Priority,
+		exitTimeout:    opts.ExitTimeout,
+		enqueueTimeout: opts.EnqueueTimeout,
+	}
+	l.cond = sync.NewCond(&l.mu)
+	if opts.Stats != nil {
+		if opts.Name == "" {
+			opts.Name = "async"
+		}
+		opts.Stats.Register(opts.Name+".enqueued", func() uint64 { return l.Stats().Enqueued })
+		opts.Stats.Register(opts.Name+".dropped", func() uint64 { return l.Stats().Dropped })
+		opts.Stats.Register(opts.Name+".evicted", func() uint64 { return l.Stats().Evicted })
+		opts.Stats.Register(opts.Name+".abandoned", func() uint64 { return l.Stats().Abandoned })
+	}
+	return l
+}
+
+// Describe reports the settings and state of the Logger, see config.Describer.
+func (l *Logger) Describe() map[string]interface{} {
+	l.mu.Lock()
+	queued := l.queue.Len()
+	l.mu.Unlock()
+	s := l.Stats()
+	return map[string]interface{}{
+		"capacity":  l.capacity,
+		"queued":    queued,
+		"enqueued":  s.Enqueued,
+		"dropped":   s.Dropped,
+		"evicted":   s.Evicted,
+		"abandoned": s.Abandoned,
+	}
+}
+
+// Decorator returns a logger.Decorator that generates async Loggers, for use with
+// logger.Builder.Then. Such Loggers cannot be closed (or flushed) explicitly; use New instead
+// if queued log events must be delivered before the process exits. The Loggers generated for
+// pipelines that are rebuilt, and so discarded, deliver the log events that remain queued and
+// are then garbage collected, see New.
+func Decorator(opts Options) logger.Decorator {
+	return func(logs logger.Logger) logger.Logger {
+		if logger.IsNull(logs) {
+			return logs
+		}
+		return New(logs, opts)
+	}
+}
+
+// run delivers queued log events until the queue is empty, and then exits: it's started by
+// Logf as needed.
+func (l *Logger) run() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	defer func() {
+		l.running = false
+		l.cond.Broadcast()
+	}()
+	for l.queue.Len() > 0 {
+		e := l.queue.Remove(l.queue.Front()).(*event)
+		l.busy = true
+		l.cond.Broadcast()
+		l.mu.Unlock()
+
+		l.logs.Logf(e.c, e.m, e.a...)
+
+		l.mu.Lock()
+		l.busy = false
+		l.cond.Broadcast()
+	}
+}
+
+func synchronous(c context.Context) bool {
+	if c == nil {
+		return false
+	}
+	lvl, _ := levels.FromContext(c)
+	return lvl == levels.Fatal || lvl == levels.Panic
+}
+
+// Logf implements logger.Logger
+func (l *Logger) Logf(c context.Context, m string, a ...interface{}) {
+	if synchronous(c) {
+		l.mu.Lock()
+		// hold the lock so that the worker can't deliver anything concurrently
+		defer l.mu.Unlock()
+		if n := l.flushLocked(l.exitTimeout); n > 0 {
+			diag.Logf("async: abandoned %d queued log events after waiting %v", n, l.exitTimeout)
+		}
+		if l.busy {
+			// the sink is stuck delivering an earlier event and isn't safe for concurrent use
+			diag.Logf("async: sink is unresponsive, failed to deliver: %s", encoding.Message(m, a...))
+			return
+		}
+		l.logs.Logf(c, m, a...)
+		return
+	}
+	e := &event{c: c, m: m, priority: l.priority(c)}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	var (
+		stopWaker chan struct{}
+		expired   bool
+	)
+	for !l.closed && l.queue.Len() >= l.capacity {
+		if !e.priority {
+			l.dropLocked()
+			return
+		}
+		if l.evictLocked() {
+			break
+		}
+		// the queue is full of priority events, wait for room unless the caller gives up first
+		if expired || (c != nil && c.Err() != nil) {
+			l.dropLocked()
+			return
+		}
+		if stopWaker == nil {
+			stopWaker = make(chan struct{})
+			defer close(stopWaker)
+			if c != nil && c.Done() != nil {
+				go l.wakeOn(c.Done(), stopWaker)
+			}
+			if l.enqueueTimeout >= 0 {
+				t := time.AfterFunc(l.enqueueTimeout, func() {
+					l.mu.Lock()
+					defer l.mu.Unlock()
+					expired = true
+					l.cond.Broadcast()
+				})
+				defer t.Stop()
+			}
+		}
+		l.cond.Wait()
+	}
+	if l.closed {
+		l.dropLocked()
+		return
+	}
+	l.dropping = false
+	e.a = append([]interface{}(nil), a...) // the caller may reuse its args
+	l.queue.PushBack(e)
+	atomic.AddUint64(&l.enqueued, 1)
+	if !l.running {
+		l.running = true
+		go l.run()
+	}
+	l.cond.Broadcast()
+}
+
+// wakeOn wakes the goroutines that wait for room in the queue once `done` closes, so that they
+// may observe that their context is done.
+func (l *Logger) wakeOn(done <-chan struct{}, stop <-chan struct{}) {
+	select {
+	case <-done:
+		l.mu.Lock()
+		l.cond.Broadcast()
+		l.mu.Unlock()
+	case <-stop:
+	}
+}
+
+func (l *Logger) dropLocked() {
+	atomic.AddUint64(&l.dropped, 1)
+	if !l.dropping && !l.closed {
+		diag.Logf("async: queue is full, dropping log events")
+	}
+	l.dropping = true
+}
+
+// evictLocked removes the oldest non-priority event from the queue, if any.
+func (l *Logger) evictLocked() bool {
+	for x := l.queue.Front(); x != nil; x = x.Next() {
+		if !x.Value.(*event).priority {
+			l.queue.Remove(x)
+			atomic.AddUint64(&l.evicted, 1)
+			return true
+		}
+	}
+	return false
+}
+
+func (l *Logger) drainLocked() {
+	for l.queue.Len() > 0 || l.busy {
+		l.cond.Wait()
+	}
+}
+
+// Flush blocks until all queued log events have been delivered.
+func (l *Logger) Flush() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.drainLocked()
+}
+
+// FlushTimeout blocks until all queued log events have been delivered, or until the timeout
+// elapses; the log events that remain queued at that time are abandoned (see Stats.Abandoned)
+// and their number returned. A negative timeout waits indefinitely, like Flush.
+func (l *Logger) FlushTimeout(timeout time.Duration) (abandoned int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.flushLocked(timeout)
+}
+
+func (l *Logger) flushLocked(timeout time.Duration) (abandoned int) {
+	if timeout < 0 {
+		l.drainLocked()
+		return 0
+	}
+	expired := false
+	t := time.AfterFunc(timeout, func() {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		expired = true
+		l.cond.Broadcast()
+	})
+	defer t.Stop()
+	for (l.queue.Len() > 0 || l.busy) && !expired {
+		l.cond.Wait()
+	}
+	abandoned = l.queue.Len()
+	if abandoned > 0 {
+		l.queue.Init()
+		atomic.AddUint64(&l.abandoned, uint64(abandoned))
+		l.cond.Broadcast()
+	}
+	return abandoned
+}
+
+// Close flushes the queue and waits for the background goroutine to exit. Log events generated
+// after Close are dropped. It's safe to invoke Close multiple times.
+func (l *Logger) Close() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.closed = true
+	l.cond.Broadcast()
+	for l.running {
+		l.cond.Wait()
+	}
+}
+
+// Stats returns a snapshot of the counters maintained by the Logger.
+func (l *Logger) Stats() Stats {
+	return Stats{
+		Enqueued:  atomic.LoadUint64(&l.enqueued),
+		Dropped:   atomic.LoadUint64(&l.dropped),
+		Evicted:   atomic.LoadUint64(&l.evicted),
+		Abandoned: atomic.LoadUint64(&l.abandoned),
+	}
+}
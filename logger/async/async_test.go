@@ -0,0 +1,243 @@
+/*
+Copyright 2016 James DeFelice
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package async_test
+
+import (
+	stdcontext "context"
+	"fmt"
+	"reflect"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gologs/log/context"
+	"github.com/gologs/log/diag"
+	"github.com/gologs/log/levels"
+	"github.com/gologs/log/logger"
+	. "github.com/gologs/log/logger/async"
+	"github.com/gologs/log/stats"
+)
+
+func TestLogger(t *testing.T) {
+	var (
+		mu        sync.Mutex
+		delivered []string
+		started   = make(chan struct{})
+		release   = make(chan struct{})
+		once      sync.Once
+		sink      = logger.Func(func(_ context.Context, m string, _ ...interface{}) {
+			once.Do(func() {
+				close(started)
+				<-release
+			})
+			mu.Lock()
+			defer mu.Unlock()
+			delivered = append(delivered, m)
+		})
+		reg = stats.NewRegistry()
+		l   = New(sink, Options{Capacity: 2, Stats: reg})
+		at  = func(lvl levels.Level) context.Context {
+			return levels.NewContext(context.Background(), lvl)
+		}
+	)
+	defer l.Close()
+
+	l.Logf(at(levels.Info), "i0")
+	<-started // the worker is now blocked delivering i0
+	l.Logf(at(levels.Info), "i1")
+	l.Logf(at(levels.Info), "i2")
+	l.Logf(at(levels.Info), "i3") // dropped, queue is full
+	l.Logf(at(levels.Warn), "w1") // evicts i1
+	close(release)
+	l.Flush()
+
+	l.Logf(at(levels.Fatal), "f1") // synchronous
+
+	mu.Lock()
+	got := append([]string(nil), delivered...)
+	mu.Unlock()
+	if expected := []string{"i0", "i2", "w1", "f1"}; !reflect.DeepEqual(expected, got) {
+		t.Fatalf("expected %q instead of %q", expected, got)
+	}
+	if expected := (Stats{Enqueued: 4, Dropped: 1, Evicted: 1}); expected != l.Stats() {
+		t.Fatalf("expected %+v instead of %+v", expected, l.Stats())
+	}
+
+	if expected := "async.abandoned=0 async.dropped=1 async.enqueued=4 async.evicted=1"; reg.Stats().String() != expected {
+		t.Fatalf("expected %q instead of %q", expected, reg.Stats())
+	}
+
+	l.Close()
+	l.Logf(at(levels.Error), "e1")
+	if s := l.Stats(); s.Dropped != 2 {
+		t.Fatalf("expected events to be dropped after Close: %+v", s)
+	}
+}
+
+func TestExitTimeout(t *testing.T) {
+	var (
+		mu        sync.Mutex
+		delivered []string
+		diags     []string
+		release   = make(chan struct{})
+		started   = make(chan struct{})
+		once      sync.Once
+		sink      = logger.Func(func(_ context.Context, m string, _ ...interface{}) {
+			once.Do(func() {
+				close(started)
+				<-release // the sink hangs
+			})
+			mu.Lock()
+			defer mu.Unlock()
+			delivered = append(delivered, m)
+		})
+		l  = New(sink, Options{ExitTimeout: 10 * time.Millisecond})
+		at = func(lvl levels.Level) context.Context {
+			return levels.NewContext(context.Background(), lvl)
+		}
+	)
+	defer diag.SetLogger(diag.SetLogger(logger.Func(func(_ context.Context, m string, a ...interface{}) {
+		diags = append(diags, fmt.Sprintf(m, a...))
+	})))
+	defer func() {
+		close(release)
+		l.Close()
+	}()
+
+	l.Logf(at(levels.Info), "i0")
+	<-started
+	l.Logf(at(levels.Info), "i1")
+	l.Logf(at(levels.Info), "i2")
+	l.Logf(at(levels.Fatal), "f1") // doesn't hang
+
+	if s := l.Stats(); s.Abandoned != 2 {
+		t.Fatalf("expected 2 abandoned events: %+v", s)
+	}
+	if len(diags) != 2 || !strings.Contains(diags[0], "abandoned 2 queued log events") ||
+		!strings.HasSuffix(diags[1], "failed to deliver: f1") {
+		t.Fatalf("unexpected diagnostics %q", diags)
+	}
+	if n := l.FlushTimeout(time.Millisecond); n != 0 {
+		t.Fatalf("expected no abandoned events instead of %d", n)
+	}
+}
+
+func TestLogger_ReusedArgs(t *testing.T) {
+	var (
+		mu        sync.Mutex
+		delivered []string
+		sink      = logger.Func(func(_ context.Context, m string, a ...interface{}) {
+			mu.Lock()
+			defer mu.Unlock()
+			delivered = append(delivered, fmt.Sprintf(m, a...))
+		})
+		l = New(sink, Options{})
+		a = []interface{}{"first"}
+	)
+	l.Logf(context.Background(), "%v", a...)
+	a[0] = "reused" // callers may reuse their args once Logf returns
+	l.Logf(context.Background(), "%v", a...)
+	l.Close()
+
+	if expected := []string{"first", "reused"}; !reflect.DeepEqual(expected, delivered) {
+		t.Fatalf("expected %q instead of %q", expected, delivered)
+	}
+}
+
+func TestLogger_Canceled(t *testing.T) {
+	var (
+		started = make(chan struct{})
+		release = make(chan struct{})
+		once    sync.Once
+		sink    = logger.Func(func(_ context.Context, _ string, _ ...interface{}) {
+			once.Do(func() { close(started) })
+			<-release
+		})
+		l   = New(sink, Options{Capacity: 1})
+		ctx = levels.NewContext(context.Background(), levels.Warn)
+	)
+	defer func() {
+		close(release)
+		l.Close()
+	}()
+	l.Logf(ctx, "w0")
+	<-started // the worker is now blocked delivering w0
+	l.Logf(ctx, "w1")
+
+	// the queue is full of priority events: enqueueing blocks until the caller gives up
+	c, cancel := stdcontext.WithCancel(ctx)
+	returned := make(chan struct{})
+	go func() {
+		defer close(returned)
+		l.Logf(c, "w2")
+	}()
+	time.Sleep(10 * time.Millisecond)
+	cancel()
+	select {
+	case <-returned:
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected Logf to return once its context was canceled")
+	}
+	if s := l.Stats(); s.Dropped != 1 {
+		t.Fatalf("expected the canceled log event to be dropped: %+v", s)
+	}
+}
+
+func TestLogger_EnqueueTimeout(t *testing.T) {
+	var (
+		started = make(chan struct{})
+		release = make(chan struct{})
+		once    sync.Once
+		sink    = logger.Func(func(_ context.Context, _ string, _ ...interface{}) {
+			once.Do(func() { close(started) })
+			<-release
+		})
+		l   = New(sink, Options{Capacity: 1, EnqueueTimeout: 10 * time.Millisecond})
+		ctx = levels.NewContext(context.Background(), levels.Error)
+	)
+	defer func() {
+		close(release)
+		l.Close()
+	}()
+	l.Logf(ctx, "e0")
+	<-started // the worker is now blocked delivering e0
+	l.Logf(ctx, "e1")
+	l.Logf(ctx, "e2") // waits for room, then gives up
+	if s := l.Stats(); s.Dropped != 1 || s.Enqueued != 2 {
+		t.Fatalf("expected the priority log event to be dropped: %+v", s)
+	}
+}
+
+func TestDecorator_Rebuild(t *testing.T) {
+	var (
+		sink = logger.Func(func(_ context.Context, _ string, _ ...interface{}) {})
+		d    = Decorator(Options{})
+		n    = runtime.NumGoroutine()
+	)
+	for i := 0; i < 100; i++ {
+		d(sink).Logf(context.Background(), "rebuilt %d", i)
+	}
+	// the workers of discarded Loggers exit once their queues are empty
+	for i := 0; runtime.NumGoroutine() > n; i++ {
+		if i > 1000 {
+			t.Fatalf("expected at most %d goroutines instead of %d", n, runtime.NumGoroutine())
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
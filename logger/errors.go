@@ -0,0 +1,293 @@
+/*
+Copyright 2016 James DeFelice
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logger
+
+import (
+	"fmt"
+	stdio "io"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gologs/log/context"
+	"github.com/gologs/log/io"
+)
+
+// Entry describes a log event that a sink failed to process.
+type Entry struct {
+	Format string
+	Args   []interface{} // Args is a copy of the args of the log event, so it may be retained
+	Stream io.Stream     // Stream is the sink that failed, if known
+}
+
+// ErrorSink consumes errors generated by the logging subsystem, along with the context and
+// Entry of the log event that failed.
+type ErrorSink interface {
+	LogError(context.Context, Entry, error)
+}
+
+// ErrorSinkFunc adapts the ErrorSink interface to functional form.
+type ErrorSinkFunc func(context.Context, Entry, error)
+
+// LogError simply invokes the receiver with the given args.
+func (f ErrorSinkFunc) LogError(c context.Context, e Entry, err error) { f(c, e, err) }
+
+type ignoreErrors struct{}
+
+func (ignoreErrors) LogError(_ context.Context, _ Entry, _ error) {}
+
+// IgnoreErrors is a convenience func to improve readability of func invocations
+// that accept an ErrorSink.
+func IgnoreErrors() ErrorSink { return ignoreErrors{} }
+
+// ErrorChan adapts an error chan to the ErrorSink interface. Errors are sent as-is (without
+// their Entry); a send blocks until the error is received or the context of the failed log
+// event is done. If the context can never be done (for example, context.Background) then errors
+// that can't be sent immediately are dropped, so that logging never wedges upon a consumer that
+// stopped receiving. A nil chan ignores all errors. See also TryErrorChan and AsyncErrors.
+func ErrorChan(ch chan<- error) ErrorSink {
+	if ch == nil {
+		return IgnoreErrors()
+	}
+	return ErrorSinkFunc(func(c context.Context, _ Entry, err error) {
+		var done <-chan struct{}
+		if c != nil {
+			done = c.Done()
+		}
+		if done == nil {
+			select {
+			case ch <- err:
+			default:
+			}
+			return
+		}
+		select {
+		case ch <- err:
+		case <-done:
+		}
+	})
+}
+
+// DroppingErrorSink is an ErrorSink that never blocks, see TryErrorChan.
+type DroppingErrorSink struct {
+	ch      chan<- error
+	dropped uint64
+}
+
+// TryErrorChan returns an ErrorSink that sends errors to `ch` only if it's ready to receive
+// them (typically, because it's buffered); other errors are dropped and counted.
+func TryErrorChan(ch chan<- error) *DroppingErrorSink { return &DroppingErrorSink{ch: ch} }
+
+// LogError implements ErrorSink
+func (s *DroppingErrorSink) LogError(_ context.Context, _ Entry, err error) {
+	select {
+	case s.ch <- err:
+	default:
+		atomic.AddUint64(&s.dropped, 1)
+	}
+}
+
+// Dropped returns the number of errors that have been dropped.
+func (s *DroppingErrorSink) Dropped() uint64 { return atomic.LoadUint64(&s.dropped) }
+
+type failure struct {
+	c   context.Context
+	e   Entry
+	err error
+}
+
+// AsyncErrorSink is an ErrorSink that hands errors off to another ErrorSink, see AsyncErrors.
+type AsyncErrorSink struct {
+	ch      chan failure
+	done    chan struct{}
+	once    sync.Once
+	dropped uint64
+}
+
+// AsyncErrors returns an ErrorSink that buffers (up to `size`) errors and delivers them to `es`
+// from a separate goroutine, so that a slow ErrorSink doesn't slow down the logging path. Errors
+// that overflow the buffer are dropped and counted. The returned ErrorSink should be closed upon
+// shutdown.
+func AsyncErrors(es ErrorSink, size int) *AsyncErrorSink {
+	if size < 1 {
+		size = 1
+	}
+	s := &AsyncErrorSink{ch: make(chan failure, size), done: make(chan struct{})}
+	go func() {
+		defer close(s.done)
+		for f := range s.ch {
+			es.LogError(f.c, f.e, f.err)
+		}
+	}()
+	return s
+}
+
+// LogError implements ErrorSink
+func (s *AsyncErrorSink) LogError(c context.Context, e Entry, err error) {
+	defer func() {
+		if recover() != nil {
+			atomic.AddUint64(&s.dropped, 1) // closed
+		}
+	}()
+	select {
+	case s.ch <- failure{c, e, err}:
+	default:
+		atomic.AddUint64(&s.dropped, 1)
+	}
+}
+
+// Dropped returns the number of errors that have been dropped.
+func (s *AsyncErrorSink) Dropped() uint64 { return atomic.LoadUint64(&s.dropped) }
+
+// Close delivers the buffered errors and stops the goroutine; errors reported afterwards are
+// dropped.
+func (s *AsyncErrorSink) Close() {
+	s.once.Do(func() { close(s.ch) })
+	<-s.done
+}
+
+// FallbackOptions customize a FallbackErrorSink.
+type FallbackOptions struct {
+	// Burst is the maximum number of errors written per Interval, defaults to 10.
+	Burst int
+	// Interval defaults to one second.
+	Interval time.Duration
+	// Clock timestamps errors, defaults to time.Now.
+	Clock func() time.Time
+}
+
+// FallbackErrorSink is an ErrorSink that writes errors, as timestamped lines, to a destination
+// that's independent of the failing sink, see FallbackErrors.
+type FallbackErrorSink struct {
+	w    stdio.Writer
+	opts FallbackOptions
+
+	mu         sync.Mutex
+	window     time.Time // window is the start of the current Interval
+	written    int       // written counts the errors written in the current Interval
+	suppressed uint64
+	total      uint64      // total counts suppressed errors, guarded by atomics
+	timer      *time.Timer // timer flushes the suppressed errors once the current Interval ends
+}
+
+// FallbackErrors returns an ErrorSink that writes errors to `w` (defaults to os.Stderr), for
+// example a dedicated file, one timestamped line per error. Errors in excess of Burst per
+// Interval are suppressed, so that a persistently failing sink doesn't flood `w`; the number of
+// suppressed errors is written once the Interval ends (see Flush). Unlike IgnoreErrors, it leaves
+// a trace of every marshaling and I/O failure.
+func FallbackErrors(w stdio.Writer, opts FallbackOptions) *FallbackErrorSink {
+	if w == nil {
+		w = os.Stderr
+	}
+	if opts.Burst <= 0 {
+		opts.Burst = 10
+	}
+	if opts.Interval <= 0 {
+		opts.Interval = time.Second
+	}
+	if opts.Clock == nil {
+		opts.Clock = time.Now
+	}
+	return &FallbackErrorSink{w: w, opts: opts}
+}
+
+// LogError implements ErrorSink
+func (s *FallbackErrorSink) LogError(_ context.Context, e Entry, err error) {
+	now := s.opts.Clock()
+	ts := now.UTC().Format(time.RFC3339Nano)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if now.Sub(s.window) >= s.opts.Interval {
+		s.flush(ts)
+		s.window, s.written = now, 0
+	}
+	if s.written >= s.opts.Burst {
+		if s.suppressed++; s.timer == nil {
+			s.timer = time.AfterFunc(s.window.Add(s.opts.Interval).Sub(now), s.Flush)
+		}
+		atomic.AddUint64(&s.total, 1)
+		return
+	}
+	s.written++
+	fmt.Fprintf(s.w, "%s gologs: failed to log event %q: %v\n", ts, e.Format, err)
+}
+
+// Flush writes the number of errors suppressed since the last summary, if any. It's invoked
+// once the Interval during which errors were suppressed ends, and should also be invoked upon
+// shutdown.
+func (s *FallbackErrorSink) Flush() {
+	ts := s.opts.Clock().UTC().Format(time.RFC3339Nano)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.flush(ts)
+}
+
+// flush expects s.mu to be locked
+func (s *FallbackErrorSink) flush(ts string) {
+	if s.timer != nil {
+		s.timer.Stop()
+		s.timer = nil
+	}
+	if s.suppressed > 0 {
+		fmt.Fprintf(s.w, "%s gologs: suppressed %d errors\n", ts, s.suppressed)
+		s.suppressed = 0
+	}
+}
+
+// Suppressed returns the number of errors that have been suppressed.
+func (s *FallbackErrorSink) Suppressed() uint64 { return atomic.LoadUint64(&s.total) }
+
+type captureKey struct{}
+
+type capture struct{ err error }
+
+// CaptureErrors returns a Context that instructs Loggers generated by WithStream (and similar
+// Builders) to record the error, if any, of a log event instead of reporting it to their
+// ErrorSink. The returned func yields the recorded error once the log event has been processed.
+// Errors of log events that are processed asynchronously are not captured.
+func CaptureErrors(c context.Context) (context.Context, func() error) {
+	if c == nil {
+		c = context.Background()
+	}
+	x := &capture{}
+	return context.WithValue(c, captureKey{}, x), func() error { return x.err }
+}
+
+// LogfE logs via the given Logger and returns the error of the log event, see CaptureErrors.
+func LogfE(logs Logger, c context.Context, m string, a ...interface{}) error {
+	c, errf := CaptureErrors(c)
+	logs.Logf(c, m, a...)
+	return errf()
+}
+
+// reportError records the error for CaptureErrors, or else reports it to the ErrorSink.
+func reportError(c context.Context, errs ErrorSink, e Entry, err error) {
+	if c != nil {
+		if x, ok := c.Value(captureKey{}).(*capture); ok {
+			x.err = err
+			return
+		}
+	}
+	if len(e.Args) > 0 {
+		// the args of a log event may be reused once it's processed, but sinks may retain them
+		e.Args = append([]interface{}(nil), e.Args...)
+	}
+	errs.LogError(c, e, err)
+}
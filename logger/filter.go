@@ -0,0 +1,78 @@
+/*
+Copyright 2016 James DeFelice
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logger
+
+import (
+	"regexp"
+
+	"github.com/gologs/log/context"
+	"github.com/gologs/log/encoding"
+)
+
+// Predicate returns true if a log event, as described by its context, message format, and
+// arguments, matches some criteria.
+type Predicate func(context.Context, string, []interface{}) bool
+
+// Or generates a Predicate that matches if either the receiver or the other Predicate matches.
+func (p Predicate) Or(other Predicate) Predicate {
+	return func(c context.Context, m string, a []interface{}) bool {
+		return p(c, m, a) || other(c, m, a)
+	}
+}
+
+// Reject returns a Decorator that discards log events that match the given Predicate.
+func Reject(pred Predicate) Decorator {
+	return func(logs Logger) Logger {
+		if pred == nil || IsNull(logs) {
+			return logs
+		}
+		return Func(func(c context.Context, m string, a ...interface{}) {
+			if !pred(c, m, a) {
+				logs.Logf(c, m, a...)
+			}
+		})
+	}
+}
+
+// FormatMatches generates a Predicate that matches log events whose message format (not the
+// formatted message) matches the regular expression. This is cheaper than MessageMatches.
+func FormatMatches(re *regexp.Regexp) Predicate {
+	return func(_ context.Context, m string, _ []interface{}) bool {
+		return re.MatchString(m)
+	}
+}
+
+// MessageMatches generates a Predicate that matches log events whose formatted message
+// matches the regular expression.
+func MessageMatches(re *regexp.Regexp) Predicate {
+	return func(_ context.Context, m string, a []interface{}) bool {
+		return re.MatchString(encoding.Message(m, a...))
+	}
+}
+
+// AnyArg generates a Predicate that matches log events for which `f` returns true for at
+// least one argument.
+func AnyArg(f func(interface{}) bool) Predicate {
+	return func(_ context.Context, _ string, a []interface{}) bool {
+		for _, x := range a {
+			if f(x) {
+				return true
+			}
+		}
+		return false
+	}
+}
@@ -0,0 +1,119 @@
+/*
+Copyright 2016 James DeFelice
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fingerprint
+
+import (
+	"container/list"
+	"sync"
+	"time"
+
+	"github.com/gologs/log/context"
+	"github.com/gologs/log/context/fields"
+	"github.com/gologs/log/context/timestamp"
+	"github.com/gologs/log/logger"
+)
+
+// OccurrencesKey is the key of the field with which Backoff counts the occurrences of a log event.
+const OccurrencesKey = "occurrences"
+
+const (
+	// sweepEvery is the number of log events after which Backoff and Sample forget quiet
+	// fingerprints
+	sweepEvery = 1024
+	// maxFingerprints bounds the number of fingerprints that Backoff (or Sample) tracks at once
+	maxFingerprints = 4096
+)
+
+type occurrences struct {
+	fp   string
+	n    uint64
+	last time.Time
+	elem *list.Element // elem is the position of the fingerprint in the LRU list
+}
+
+// Backoff returns a logger.Decorator that suppresses repeated log events, those with the same
+// fingerprint (see Of), with exponential backoff: only the 1st, 2nd, 4th, 8th, ... occurrence
+// is logged, with an OccurrencesKey field that counts all occurrences so far. It's intended for
+// retry loops that would otherwise flood the log, for example upon failing to reconnect. Counts
+// are forgotten once a fingerprint hasn't occurred for `quiet` (if positive), so that a problem
+// which recurs much later is logged again. At most maxFingerprints fingerprints are counted at
+// once, the least recently seen are forgotten first. It is safe for concurrent use.
+func Backoff(quiet time.Duration) logger.Decorator {
+	var (
+		mu     sync.Mutex
+		counts = map[string]*occurrences{}
+		lru    = list.New() // lru orders the counted fingerprints, most recently seen first
+		events int
+	)
+	forget := func(o *occurrences) {
+		delete(counts, o.fp)
+		lru.Remove(o.elem)
+	}
+	seen := func(fp string, now time.Time) uint64 {
+		mu.Lock()
+		defer mu.Unlock()
+		if events++; quiet > 0 && events%sweepEvery == 0 {
+			for _, o := range counts {
+				if now.Sub(o.last) >= quiet {
+					forget(o)
+				}
+			}
+		}
+		o, ok := counts[fp]
+		if ok && quiet > 0 && now.Sub(o.last) >= quiet {
+			o.n = 0
+		}
+		if !ok {
+			if len(counts) >= maxFingerprints {
+				forget(lru.Back().Value.(*occurrences))
+			}
+			o = &occurrences{fp: fp}
+			o.elem = lru.PushFront(o)
+			counts[fp] = o
+		}
+		lru.MoveToFront(o.elem)
+		o.n++
+		o.last = now
+		return o.n
+	}
+	return func(logs logger.Logger) logger.Logger {
+		if logger.IsNull(logs) {
+			return logs
+		}
+		return logger.Func(func(c context.Context, m string, a ...interface{}) {
+			if n := seen(fingerprintOf(c, m, a), now(c)); n&(n-1) == 0 { // a power of two
+				logs.Logf(fields.NewContext(c, fields.F(OccurrencesKey, n)), m, a...)
+			}
+		})
+	}
+}
+
+// now returns the timestamp of a log event, if any, otherwise the current time
+func now(c context.Context) time.Time {
+	if t, ok := timestamp.FromContext(c); ok {
+		return t
+	}
+	return time.Now()
+}
+
+// fingerprintOf returns the fingerprint stored in the context, if any, otherwise that of the log event
+func fingerprintOf(c context.Context, m string, a []interface{}) string {
+	if fp, ok := FromContext(c); ok {
+		return fp
+	}
+	return Of(c, m, a...)
+}
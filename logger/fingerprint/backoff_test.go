@@ -0,0 +1,97 @@
+/*
+Copyright 2016 James DeFelice
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fingerprint_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gologs/log/caller"
+	"github.com/gologs/log/config"
+	"github.com/gologs/log/context/fields"
+	"github.com/gologs/log/levels"
+	. "github.com/gologs/log/logger/fingerprint"
+	"github.com/gologs/log/logtest"
+)
+
+func TestBackoff(t *testing.T) {
+	var (
+		rec   = logtest.NewRecorder()
+		now   = time.Unix(0, 0)
+		clock = func() time.Time { return now }
+		logs  = rec.Interface(config.Decorate(Backoff(time.Minute)), config.Clock(clock))
+	)
+	for i := 0; i < 10; i++ {
+		logs.Errorf("reconnect failed: attempt %d", i)
+	}
+	now = now.Add(time.Minute)
+	logs.Errorf("reconnect failed: attempt %d", 10)
+
+	var counts []interface{}
+	for _, e := range rec.Entries() {
+		n, _ := fields.FromContext(e.Context).Get(OccurrencesKey)
+		counts = append(counts, n)
+	}
+	expected := []uint64{1, 2, 4, 8, 1}
+	if len(counts) != len(expected) {
+		t.Fatalf("expected occurrences %v instead of %v", expected, counts)
+	}
+	for i := range expected {
+		if counts[i] != expected[i] {
+			t.Fatalf("expected occurrences %v instead of %v", expected, counts)
+		}
+	}
+	if e := rec.Entries()[3]; e.Message != "reconnect failed: attempt 7" {
+		t.Errorf("unexpected message %q", e.Message)
+	}
+}
+
+func TestBackoff_Print(t *testing.T) {
+	var (
+		rec  = logtest.NewRecorder()
+		logs = rec.Interface(config.Decorate(Backoff(0)), config.CallTracking(caller.Tracking{}))
+	)
+	logs.Error("connection refused")
+	logs.Error("cert expired")
+	logs.Error("connection refused")
+	logs.Error("connection refused")
+
+	if n := len(rec.Entries()); n != 3 {
+		t.Fatalf("expected 3 log events instead of %d: %v", n, rec.Entries())
+	}
+	if _, ok := rec.Find(levels.Error, "cert expired"); !ok {
+		t.Errorf("expected distinct print-style log events to be logged: %v", rec.Entries())
+	}
+}
+
+func TestBackoff_Bounded(t *testing.T) {
+	var (
+		rec  = logtest.NewRecorder()
+		logs = rec.Interface(config.Decorate(Backoff(0)), config.CallTracking(caller.Tracking{}))
+	)
+	logs.Error("reconnect failed")
+	for i := 0; i < 5000; i++ {
+		logs.Error("event ", i)
+	}
+	rec.Reset()
+	logs.Error("reconnect failed")
+
+	// the least recently seen fingerprint was forgotten, and so counts from 1 again
+	if n, _ := fields.FromContext(rec.Entries()[0].Context).Get(OccurrencesKey); n != uint64(1) {
+		t.Errorf("expected the count to restart instead of %v", n)
+	}
+}
@@ -0,0 +1,125 @@
+/*
+Copyright 2016 James DeFelice
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package fingerprint computes stable fingerprints of log events, so that downstream systems
+// (Sentry, ELK) can group identical log events even when their interpolated values differ.
+package fingerprint
+
+import (
+	"encoding/hex"
+	"fmt"
+	"hash/fnv"
+
+	"github.com/gologs/log/caller"
+	"github.com/gologs/log/context"
+	"github.com/gologs/log/context/fields"
+	"github.com/gologs/log/levels"
+	"github.com/gologs/log/logger"
+	"github.com/gologs/log/logger/template"
+)
+
+type key int
+
+const (
+	fpKey key = iota
+)
+
+// DefaultKey is the default key of the field that captures the fingerprint.
+const DefaultKey = "fingerprint"
+
+// FromContext extracts a fingerprint from the provided context.
+func FromContext(ctx context.Context) (fp string, ok bool) {
+	if ctx == nil {
+		return
+	}
+	fp, ok = ctx.Value(fpKey).(string)
+	return
+}
+
+// NewContext returns a Context that contains the provided fingerprint.
+func NewContext(ctx context.Context, fp string) context.Context {
+	return context.WithValue(ctx, fpKey, fp)
+}
+
+// Of returns the fingerprint of a log event: a hash of its level, message format and caller.
+// The message template captured by the template package stands in for the (empty) format of a
+// rendered template; other print-style log events are fingerprinted by caller, or by their
+// rendered message `a` if call tracking is disabled. Callers are identified by file and function,
+// not line, so that fingerprints survive unrelated edits of the file; call tracking must be
+// enabled for them to be included.
+func Of(c context.Context, m string, a ...interface{}) string {
+	if m == "" {
+		if t, ok := fields.FromContext(c).Get(template.DefaultKey); ok {
+			m, _ = t.(string)
+		}
+	}
+	h := fnv.New64a()
+	if lvl, ok := levels.FromContext(c); ok {
+		h.Write([]byte(lvl.String()))
+		h.Write([]byte{0})
+	}
+	h.Write([]byte(m))
+	x, ok := caller.FromContext(c)
+	if ok {
+		h.Write([]byte{0})
+		h.Write([]byte(x.File))
+		h.Write([]byte{0})
+		h.Write([]byte(x.FuncName))
+	} else if m == "" {
+		h.Write([]byte{0})
+		fmt.Fprint(h, a...)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func annotate(key string, c context.Context, m string, a []interface{}) context.Context {
+	fp := Of(c, m, a...)
+	c = NewContext(c, fp)
+	if key != "-" {
+		c = fields.NewContext(c, fields.F(key, fp))
+	}
+	return c
+}
+
+func orDefault(key string) string {
+	if key == "" {
+		return DefaultKey
+	}
+	return key
+}
+
+// Hook returns a logger.Hook that stores the fingerprint (see Of) of each log event in its
+// context, and adds it as a field by the given key (DefaultKey if empty, omitted if "-"). It
+// should follow the template.Hook, if any.
+func Hook(key string) logger.Hook {
+	key = orDefault(key)
+	return logger.Hook{
+		Before: func(c context.Context, m string, a []interface{}) (context.Context, string, []interface{}) {
+			return annotate(key, c, m, a), m, a
+		},
+	}
+}
+
+// Decorator returns a logger.Decorator that fingerprints log events like Hook does, for
+// Logger-based sinks.
+func Decorator(key string) logger.Decorator {
+	key = orDefault(key)
+	return func(logs logger.Logger) logger.Logger {
+		return logger.Func(func(c context.Context, m string, a ...interface{}) {
+			logs.Logf(annotate(key, c, m, a), m, a...)
+		})
+	}
+}
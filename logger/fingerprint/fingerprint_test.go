@@ -0,0 +1,85 @@
+/*
+Copyright 2016 James DeFelice
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fingerprint_test
+
+import (
+	"testing"
+
+	"github.com/gologs/log/caller"
+	"github.com/gologs/log/config"
+	"github.com/gologs/log/context/fields"
+	"github.com/gologs/log/logger"
+	. "github.com/gologs/log/logger/fingerprint"
+	"github.com/gologs/log/logger/template"
+	"github.com/gologs/log/logtest"
+)
+
+func TestFingerprint(t *testing.T) {
+	var (
+		rec  = logtest.NewRecorder()
+		logs = rec.Interface(config.Decorate(logger.Hooks{template.Hook(""), Hook("")}.Decorator()))
+	)
+	for _, user := range []string{"alice", "bob"} {
+		logs.Infof("user %s logged in", user)
+		logs.Infof("user {user} logged in", user)
+	}
+	func() { logs.Infof("user %s logged in", "carol") }() // another caller
+	logs.Infof("user %s logged in", "dave")               // the same caller, on another line
+	logs.Infof("user %s logged out", "alice")
+
+	var fps []string
+	for _, e := range rec.Entries() {
+		fp, ok := FromContext(e.Context)
+		if !ok {
+			t.Fatalf("missing fingerprint for %v", e)
+		}
+		if f, _ := fields.FromContext(e.Context).Get(DefaultKey); f != fp {
+			t.Fatalf("expected field %q instead of %v", fp, f)
+		}
+		fps = append(fps, fp)
+	}
+	if fps[0] != fps[2] || fps[1] != fps[3] || fps[0] != fps[5] {
+		t.Errorf("expected the same fingerprints for the same formats and callers: %v", fps)
+	}
+	seen := map[string]bool{}
+	for _, fp := range []string{fps[0], fps[1], fps[4], fps[6]} {
+		if seen[fp] {
+			t.Errorf("expected distinct fingerprints: %v", fps)
+		}
+		seen[fp] = true
+	}
+}
+
+func TestFingerprint_Print(t *testing.T) {
+	var (
+		rec  = logtest.NewRecorder()
+		logs = rec.Interface(config.Decorate(Decorator("")), config.CallTracking(caller.Tracking{}))
+	)
+	logs.Error("cert expired")
+	logs.Error("disk full")
+	logs.Warn("disk full")
+	logs.Error("disk full")
+
+	var fps []string
+	for _, e := range rec.Entries() {
+		fp, _ := FromContext(e.Context)
+		fps = append(fps, fp)
+	}
+	if fps[0] == fps[1] || fps[1] == fps[2] || fps[1] != fps[3] {
+		t.Errorf("expected fingerprints to distinguish messages and levels: %v", fps)
+	}
+}
@@ -0,0 +1,141 @@
+/*
+Copyright 2016 James DeFelice
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fingerprint
+
+import (
+	"container/list"
+	"sync"
+	"time"
+
+	"github.com/gologs/log/context"
+	"github.com/gologs/log/context/fields"
+	"github.com/gologs/log/logger"
+)
+
+// SampleRateKey is the key of the field with which Sample annotates sampled log events: each
+// represents approximately that many log events.
+const SampleRateKey = "sample_rate"
+
+// Sample defaults
+const (
+	DefaultWindow    = 10 * time.Second
+	DefaultThreshold = 10
+)
+
+// SampleOptions determine how Sample adapts its rates.
+type SampleOptions struct {
+	// Window is the duration of the sliding window over which the rate of each fingerprint is
+	// measured, defaults to DefaultWindow.
+	Window time.Duration
+	// Threshold is the number of log events per Window, per fingerprint, beyond which log events
+	// are sampled; defaults to DefaultThreshold.
+	Threshold int
+}
+
+type window struct {
+	fp        string
+	start     time.Time     // start of the current window
+	cur, prev int           // counts of log events in the current and previous windows
+	seen      uint64        // seen counts log events while sampling
+	elem      *list.Element // elem is the position of the fingerprint in the LRU list
+}
+
+// rate returns the sampling rate after counting a log event at time `t`
+func (w *window) rate(t time.Time, opts *SampleOptions) int {
+	if d := t.Sub(w.start); d >= 2*opts.Window {
+		w.start, w.cur, w.prev = t, 0, 0
+	} else if d >= opts.Window {
+		w.start, w.cur, w.prev = w.start.Add(opts.Window), 0, w.cur
+	}
+	w.cur++
+	// approximate the count of the sliding window by weighting that of the previous one
+	elapsed := float64(t.Sub(w.start)) / float64(opts.Window)
+	count := float64(w.prev)*(1-elapsed) + float64(w.cur)
+	if count <= float64(opts.Threshold) {
+		w.seen = 0
+		return 1
+	}
+	return int(count/float64(opts.Threshold) + 0.5)
+}
+
+// Sample returns a logger.Decorator that adapts the sampling of log events to the rate of
+// their fingerprints (see Of): fingerprints that occur at most opts.Threshold times per sliding
+// opts.Window are logged in full, so that rare log events are never lost, while one in every
+// count/Threshold log events is kept of more frequent ones, such that about opts.Threshold log
+// events are logged per fingerprint per Window regardless of its rate. Log events logged at a
+// rate of more than one are annotated with a SampleRateKey field. At most maxFingerprints
+// fingerprints are tracked at once, the least recently seen are forgotten first. It is safe for
+// concurrent use.
+func Sample(opts SampleOptions) logger.Decorator {
+	if opts.Window <= 0 {
+		opts.Window = DefaultWindow
+	}
+	if opts.Threshold <= 0 {
+		opts.Threshold = DefaultThreshold
+	}
+	var (
+		mu      sync.Mutex
+		windows = map[string]*window{}
+		lru     = list.New() // lru orders the tracked fingerprints, most recently seen first
+		events  int
+	)
+	forget := func(w *window) {
+		delete(windows, w.fp)
+		lru.Remove(w.elem)
+	}
+	keep := func(fp string, t time.Time) (rate int, ok bool) {
+		mu.Lock()
+		defer mu.Unlock()
+		if events++; events%sweepEvery == 0 {
+			for _, w := range windows {
+				if t.Sub(w.start) >= 2*opts.Window {
+					forget(w)
+				}
+			}
+		}
+		w, found := windows[fp]
+		if !found {
+			if len(windows) >= maxFingerprints {
+				forget(lru.Back().Value.(*window))
+			}
+			w = &window{fp: fp, start: t}
+			w.elem = lru.PushFront(w)
+			windows[fp] = w
+		}
+		lru.MoveToFront(w.elem)
+		if rate = w.rate(t, &opts); rate == 1 {
+			return rate, true
+		}
+		w.seen++
+		return rate, w.seen%uint64(rate) == 1
+	}
+	return func(logs logger.Logger) logger.Logger {
+		if logger.IsNull(logs) {
+			return logs
+		}
+		return logger.Func(func(c context.Context, m string, a ...interface{}) {
+			rate, ok := keep(fingerprintOf(c, m, a), now(c))
+			if !ok {
+				return
+			}
+			if rate > 1 {
+				c = fields.NewContext(c, fields.F(SampleRateKey, rate))
+			}
+			logs.Logf(c, m, a...)
+		})
+	}
+}
@@ -0,0 +1,112 @@
+/*
+Copyright 2016 James DeFelice
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fingerprint_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gologs/log/caller"
+	"github.com/gologs/log/config"
+	"github.com/gologs/log/context/fields"
+	"github.com/gologs/log/levels"
+	. "github.com/gologs/log/logger/fingerprint"
+	"github.com/gologs/log/logtest"
+)
+
+func TestSample(t *testing.T) {
+	var (
+		rec   = logtest.NewRecorder()
+		now   = time.Unix(0, 0)
+		clock = func() time.Time { return now }
+		logs  = rec.Interface(config.Decorate(Sample(SampleOptions{Window: time.Second})), config.Clock(clock))
+		hot   = func() (n int) {
+			for i := 0; i < 1000; i++ {
+				logs.Debugf("cache miss %d", i)
+				if i%200 == 0 {
+					logs.Warnf("disk %d%% full", i/10)
+				}
+				now = now.Add(time.Millisecond)
+			}
+			for _, e := range rec.Entries() {
+				if e.Level == levels.Debug {
+					n++
+				}
+			}
+			return
+		}
+	)
+	n := hot()
+	if n < DefaultThreshold || n > 100 {
+		t.Fatalf("expected the hot log event to be sampled, but %d were logged", n)
+	}
+	var rated bool
+	for _, e := range rec.Entries() {
+		_, ok := fields.FromContext(e.Context).Get(SampleRateKey)
+		rated = rated || ok
+		if e.Level == levels.Warn && ok {
+			t.Errorf("unexpected sampling of a rare log event: %v", e)
+		}
+	}
+	if !rated {
+		t.Errorf("expected %s fields", SampleRateKey)
+	}
+	if err := rec.Expect(levels.Warn, "disk 80% full"); err != nil {
+		t.Error(err)
+	}
+	if _, ok := rec.Find(levels.Warn, "disk 0% full"); !ok {
+		t.Error("expected all rare log events to be logged")
+	}
+
+	// the rate is forgotten once the fingerprint is quiet
+	rec.Reset()
+	now = now.Add(2 * time.Second)
+	logs.Debugf("cache miss %d", 0)
+	if _, ok := fields.FromContext(rec.Entries()[0].Context).Get(SampleRateKey); ok {
+		t.Error("expected a full rate after a quiet period")
+	}
+}
+
+func TestSample_Bounded(t *testing.T) {
+	var (
+		rec   = logtest.NewRecorder()
+		now   = time.Unix(0, 0)
+		clock = func() time.Time { return now }
+		logs  = rec.Interface(
+			config.Decorate(Sample(SampleOptions{})),
+			config.Clock(clock),
+			config.CallTracking(caller.Tracking{}),
+		)
+	)
+	for i := 0; i < 100; i++ {
+		logs.Info("hot")
+	}
+	for i := 0; i < 5000; i++ {
+		logs.Info("event ", i)
+	}
+	rec.Reset()
+	logs.Info("hot")
+
+	// the least recently seen fingerprint was forgotten, and so is logged at a full rate again
+	entries := rec.Entries()
+	if len(entries) != 1 {
+		t.Fatalf("expected the hot log event to be logged instead of %v", entries)
+	}
+	if _, ok := fields.FromContext(entries[0].Context).Get(SampleRateKey); ok {
+		t.Error("expected a full rate for a forgotten fingerprint")
+	}
+}
@@ -0,0 +1,85 @@
+/*
+Copyright 2016 James DeFelice
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logger
+
+import (
+	"github.com/gologs/log/context"
+	"github.com/gologs/log/encoding"
+	"github.com/gologs/log/io"
+)
+
+// Hook intercepts log events on their way to a sink. Either func may be nil.
+type Hook struct {
+	// Before is invoked prior to marshaling a log event and may return a modified context,
+	// message format, and/or arguments.
+	Before func(context.Context, string, []interface{}) (context.Context, string, []interface{})
+
+	// After is invoked once the sink has processed a log event (as returned by Before) and
+	// observes the resulting error, if any.
+	After func(context.Context, string, []interface{}, error)
+}
+
+// Hooks aggregates Hook. Before funcs are invoked in order, After funcs in reverse order.
+type Hooks []Hook
+
+func (hh Hooks) before(c context.Context, m string, a []interface{}) (context.Context, string, []interface{}) {
+	for _, h := range hh {
+		if h.Before != nil {
+			c, m, a = h.Before(c, m, a)
+		}
+	}
+	return c, m, a
+}
+
+func (hh Hooks) after(c context.Context, m string, a []interface{}, err error) {
+	for i := len(hh) - 1; i >= 0; i-- {
+		if h := hh[i]; h.After != nil {
+			h.After(c, m, a, err)
+		}
+	}
+}
+
+// Encoding returns an encoding.Decorator that invokes the hooks around a Marshaler; After funcs
+// observe the error returned by the Marshaler.
+func (hh Hooks) Encoding() encoding.Decorator {
+	return func(op encoding.Marshaler) encoding.Marshaler {
+		if len(hh) == 0 {
+			return op
+		}
+		return func(c context.Context, s io.Stream, m string, a ...interface{}) (err error) {
+			c, m, a = hh.before(c, m, a)
+			err = op(c, s, m, a...)
+			hh.after(c, m, a, err)
+			return
+		}
+	}
+}
+
+// Decorator returns a Decorator that invokes the hooks around a Logger. Since a Logger does not
+// report errors, After funcs always observe a nil error.
+func (hh Hooks) Decorator() Decorator {
+	return func(logs Logger) Logger {
+		if len(hh) == 0 || IsNull(logs) {
+			return logs
+		}
+		return Func(func(c context.Context, m string, a ...interface{}) {
+			c, m, a = hh.before(c, m, a)
+			logs.Logf(c, m, a...)
+			hh.after(c, m, a, nil)
+		})
+	}
+}
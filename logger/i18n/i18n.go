@@ -0,0 +1,78 @@
+/*
+Copyright 2016 James DeFelice
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package i18n translates the message formats of log events, for products that must emit
+// operator-facing logs in languages other than that of their source code.
+package i18n
+
+import (
+	"github.com/gologs/log/context"
+	"github.com/gologs/log/logger"
+)
+
+// Translator looks up the translation of a message format.
+type Translator interface {
+	Translate(format string) (string, bool)
+}
+
+// Func is a functional adapter for Translator
+type Func func(string) (string, bool)
+
+// Translate implements Translator
+func (f Func) Translate(format string) (string, bool) { return f(format) }
+
+// Catalog is a Translator that maps message formats, as found in source code, to their
+// translations. Translations must consume the args of log events in the same order as the
+// original formats do, see fmt.
+type Catalog map[string]string
+
+// Translate implements Translator
+func (c Catalog) Translate(format string) (string, bool) {
+	t, ok := c[format]
+	return t, ok
+}
+
+// Hook returns a logger.Hook that replaces the message format of each log event with its
+// translation, if any, before it's marshaled (see config.Hooks). The args and context of log
+// events are left as-is, so that their fields remain stable for machines. Print-style log
+// events, which lack a message format, are not translated.
+func Hook(t Translator) logger.Hook {
+	return logger.Hook{
+		Before: func(c context.Context, m string, a []interface{}) (context.Context, string, []interface{}) {
+			return c, translate(t, m), a
+		},
+	}
+}
+
+// Decorator returns a logger.Decorator that translates message formats like Hook does, for
+// Logger-based sinks.
+func Decorator(t Translator) logger.Decorator {
+	return func(logs logger.Logger) logger.Logger {
+		return logger.Func(func(c context.Context, m string, a ...interface{}) {
+			logs.Logf(c, translate(t, m), a...)
+		})
+	}
+}
+
+func translate(t Translator, m string) string {
+	if m == "" {
+		return m
+	}
+	if x, ok := t.Translate(m); ok {
+		return x
+	}
+	return m
+}
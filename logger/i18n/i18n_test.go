@@ -0,0 +1,54 @@
+/*
+Copyright 2016 James DeFelice
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package i18n_test
+
+import (
+	"testing"
+
+	"github.com/gologs/log/config"
+	"github.com/gologs/log/context/fields"
+	"github.com/gologs/log/levels"
+	"github.com/gologs/log/logger"
+	. "github.com/gologs/log/logger/i18n"
+	"github.com/gologs/log/logtest"
+)
+
+var catalog = Catalog{"disk %s is %d%% full": "le disque %s est plein à %d%%"}
+
+func TestTranslate(t *testing.T) {
+	for name, d := range map[string]logger.Decorator{
+		"Hook":      logger.Hooks{Hook(catalog)}.Decorator(),
+		"Decorator": Decorator(catalog),
+	} {
+		var (
+			rec  = logtest.NewRecorder()
+			logs = rec.Interface(config.Decorate(d))
+		)
+		levels.WithContext(logs, fields.NewDecorator(fields.F("disk", "sda"))).Warnf("disk %s is %d%% full", "sda", 95)
+		logs.Infof("not translated: %d", 1)
+		logs.Info("disk %s is %d%% full")
+		entries := rec.Entries()
+		for i, expected := range []string{"le disque sda est plein à 95%", "not translated: 1", "disk %s is %d%% full"} {
+			if entries[i].Message != expected {
+				t.Errorf("%s: expected %q instead of %q", name, expected, entries[i].Message)
+			}
+		}
+		if ff := fields.FromContext(entries[0].Context); len(ff) != 1 || ff[0].Value != "sda" {
+			t.Errorf("%s: unexpected fields: %v", name, ff)
+		}
+	}
+}
@@ -18,17 +18,26 @@ package logger
 
 import (
 	"log"
+	"time"
 
+	"github.com/gologs/log/caller"
 	"github.com/gologs/log/context"
 	"github.com/gologs/log/encoding"
 	"github.com/gologs/log/io"
 )
 
-// Logger is a generic logging interface.
+// Logger is a generic logging interface. The args of a log event may be reused by the caller
+// once Logf returns: implementations that retain them (for example, to deliver the log event
+// later) must retain a copy.
 type Logger interface {
 	Logf(context.Context, string, ...interface{})
 }
 
+// Print logs a print-style log event: one without a message format, the args of which are
+// rendered as if by fmt.Sprint (see encoding.Message), so that a '%' in them is never mistaken
+// for a formatting directive. Loggers identify such events by their empty message format.
+func Print(logs Logger, c context.Context, a ...interface{}) { logs.Logf(c, "", a...) }
+
 // Func adapts the Logger interface to functional form.
 type Func func(context.Context, string, ...interface{})
 
@@ -37,8 +46,18 @@ func (f Func) Logf(c context.Context, msg string, args ...interface{}) {
 	f(c, msg, args...)
 }
 
+type nullLogger struct{}
+
+func (nullLogger) Logf(_ context.Context, _ string, _ ...interface{}) {}
+
 // Null discards all log events, akin to /dev/null
-func Null() Logger { return Func(func(_ context.Context, _ string, _ ...interface{}) {}) }
+func Null() Logger { return nullLogger{} }
+
+// IsNull returns true if the given Logger is known to discard all log events.
+func IsNull(logs Logger) bool {
+	_, ok := logs.(nullLogger)
+	return ok
+}
 
 // Multi returns a Logger that copies log events all those given as arguments
 func Multi(loggers ...Logger) Logger {
@@ -56,30 +75,88 @@ func Multi(loggers ...Logger) Logger {
 	}
 }
 
-// IgnoreErrors is a convenience func to improve readability of func invocations
-// that accept an error promise.
-func IgnoreErrors() chan<- error {
-	return nil
+// Builder generates a Logger
+type Builder func(io.Stream, encoding.Marshaler, ErrorSink) Logger
+
+// Then returns a Builder that applies the given Decorators, in order, to the Loggers generated
+// by the receiver.
+func (b Builder) Then(d ...Decorator) Builder {
+	return func(s io.Stream, op encoding.Marshaler, errs ErrorSink) Logger {
+		return Decorators(d).Decorate(b(s, op, errs))
+	}
 }
 
-// Builder generates a Logger
-type Builder func(io.Stream, encoding.Marshaler, chan<- error) Logger
+// Builders aggregates Builder
+type Builders []Builder
+
+// Multi returns a Builder that generates a Multi Logger from the Loggers generated by each of
+// the aggregated Builders, given the same Stream, Marshaler, and ErrorSink.
+func (bb Builders) Multi() Builder {
+	return func(s io.Stream, op encoding.Marshaler, errs ErrorSink) Logger {
+		loggers := make([]Logger, 0, len(bb))
+		for _, b := range bb {
+			loggers = append(loggers, b(s, op, errs))
+		}
+		return Multi(loggers...)
+	}
+}
 
 // WithStream generates a Logger that writes log events to the given
 // io.Stream using the given `op` marshaler. It is expected that a marshaler
-// will invoke EOM after processing each log event.
-func WithStream(s io.Stream, op encoding.Marshaler, errCh chan<- error) Logger {
+// will invoke EOM after processing each log event. Errors are reported to
+// `errs`, which may be nil.
+func WithStream(s io.Stream, op encoding.Marshaler, errs ErrorSink) Logger {
+	if errs == nil {
+		errs = IgnoreErrors()
+	}
 	return Func(func(ctx context.Context, m string, a ...interface{}) {
-		if err := op(ctx, s, m, a...); err != nil && errCh != nil {
+		if err := op(ctx, s, m, a...); err != nil {
 			// attempt to send back errors to the caller
-			select {
-			case errCh <- err:
-			case <-ctx.Done():
-			}
+			reportError(ctx, errs, Entry{Format: m, Args: a, Stream: s}, err)
 		}
 	})
 }
 
+// WithTimeout returns a Builder that generates Loggers like WithStream does, except that each
+// log event is written to the Stream via io.Timeout: a write is abandoned if it takes longer
+// than `d`, or if the context of the log event is done first.
+func WithTimeout(d time.Duration) Builder {
+	return func(s io.Stream, op encoding.Marshaler, errs ErrorSink) Logger {
+		if errs == nil {
+			errs = IgnoreErrors()
+		}
+		ts := io.Timeout(s, d)
+		return Func(func(ctx context.Context, m string, a ...interface{}) {
+			var done <-chan struct{}
+			if ctx != nil {
+				done = ctx.Done()
+			}
+			if err := op(ctx, ts.WithDone(done), m, a...); err != nil {
+				reportError(ctx, errs, Entry{Format: m, Args: a, Stream: s}, err)
+			}
+		})
+	}
+}
+
+// WithShards returns a Builder that generates Loggers which write log events to the given
+// io.ShardedWriter. The Stream given to the Builder is ignored: the ShardedWriter writes to the
+// Stream that it was created with, and reports errors of that Stream itself (see io.Sharded).
+// Such Loggers are safe for concurrent use and so may be configured without a lock guard (see
+// config.NoGuard). The ShardedWriter should be closed upon shutdown.
+func WithShards(w *io.ShardedWriter) Builder {
+	return func(_ io.Stream, op encoding.Marshaler, errs ErrorSink) Logger {
+		if errs == nil {
+			errs = IgnoreErrors()
+		}
+		return Func(func(ctx context.Context, m string, a ...interface{}) {
+			if err := op(ctx, w.Stream(), m, a...); err != nil {
+				// the per-event Stream is recycled, so it's not reported
+				reportError(ctx, errs, Entry{Format: m, Args: a}, err)
+			}
+		})
+	}
+}
+
 // Decorator functions typically generate a transformed version of the original Logger.
 type Decorator func(Logger) Logger
 
@@ -88,9 +165,34 @@ type Decorator func(Logger) Logger
 func NoDecorator() Decorator { return func(x Logger) Logger { return x } }
 */
 
+// Decorators aggregates Decorator
+type Decorators []Decorator
+
+// Decorate applies all of the decorators to the given Logger, in order. This means that the
+// last decorator in the collection will be the first decorator invoked upon calls to the
+// returned Logger.
+func (dd Decorators) Decorate(logs Logger) Logger {
+	for _, d := range dd {
+		if d != nil {
+			logs = d(logs)
+		}
+	}
+	return logs
+}
+
+// Copy returns a copy of the decorator slice; changes to the returned slice shall not modify
+// the original slice.
+func (dd Decorators) Copy() (clone Decorators) {
+	if dd != nil {
+		clone = make(Decorators, len(dd))
+		copy(clone, dd)
+	}
+	return
+}
+
 // WithContext decorates the given Logger by injecting additional context via `d`.
 func WithContext(d context.Decorator, logger Logger) Logger {
-	if d == nil {
+	if d == nil || IsNull(logger) {
 		return logger
 	}
 	return Func(func(c context.Context, m string, a ...interface{}) {
@@ -98,6 +200,58 @@ func WithContext(d context.Decorator, logger Logger) Logger {
 	})
 }
 
+type callDepth struct {
+	n    int
+	logs Logger
+}
+
+func (d callDepth) Logf(c context.Context, m string, a ...interface{}) {
+	d.logs.Logf(caller.Skip(c, d.n+1), m, a...) // +1 for this frame
+}
+
+// WithCallDepth returns a Decorator that adjusts the call depth at which the Caller of each log
+// event is looked up by `n` frames (see caller.Skip), which keeps caller information correct when
+// log events arrive via bridges that contribute `n` frames of their own to the call stack. The
+// frame of the returned Logger itself is accounted for. It must be applied upstream of caller
+// tracking, for example to a Logger that's handed to such a bridge; see also levels.WithCallDepth.
+func WithCallDepth(n int) Decorator {
+	return func(logs Logger) Logger {
+		if IsNull(logs) {
+			return logs
+		}
+		return callDepth{n, logs}
+	}
+}
+
+// Memoize returns a Decorator that memoizes the context of each log event (see
+// context.Memoize), which speeds up repeated lookups of the same context values by the
+// decorators and encoders downstream of it.
+func Memoize() Decorator {
+	return func(logs Logger) Logger {
+		if IsNull(logs) {
+			return logs
+		}
+		return Func(func(c context.Context, m string, a ...interface{}) {
+			logs.Logf(context.Memoize(c), m, a...)
+		})
+	}
+}
+
+// When returns a Decorator that forwards log events to the original Logger only if `pred`
+// returns true for the context of the event; other events are discarded.
+func When(pred func(context.Context) bool) Decorator {
+	return func(logs Logger) Logger {
+		if pred == nil || IsNull(logs) {
+			return logs
+		}
+		return Func(func(c context.Context, m string, a ...interface{}) {
+			if pred(c) {
+				logs.Logf(c, m, a...)
+			}
+		})
+	}
+}
+
 // SystemLogger generates a Logger that logs to the golang Print family
 // of functions.
 func SystemLogger() Logger {
@@ -17,19 +17,40 @@ limitations under the License.
 package logger_test
 
 import (
+	"bytes"
+	stdcontext "context"
 	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/gologs/log/caller"
 	"github.com/gologs/log/context"
+	"github.com/gologs/log/context/requestid"
+	"github.com/gologs/log/context/timestamp"
 	"github.com/gologs/log/encoding"
+	"github.com/gologs/log/encoding/structured"
 	"github.com/gologs/log/io"
 	. "github.com/gologs/log/logger"
 )
 
-func TestNull(_ *testing.T) {
+func TestNull(t *testing.T) {
 	// should execute without error
 	n := Null()
 	n.Logf(nil, "")
+
+	if !IsNull(n) {
+		t.Errorf("expected Null to be recognized by IsNull")
+	}
+	if !IsNull(WithContext(context.NoDecorator(), n)) {
+		t.Errorf("expected decorated Null to be recognized by IsNull")
+	}
+	if IsNull(Func(func(_ context.Context, _ string, _ ...interface{}) {})) {
+		t.Errorf("unexpected IsNull for Func")
+	}
 }
 
 func TestMulti(t *testing.T) {
@@ -112,7 +133,7 @@ func TestWithStream_WithError(t *testing.T) {
 			return nil
 		}}
 		errCh = make(chan error, 1)
-		logs  = WithStream(buf, marshaler, errCh)
+		logs  = WithStream(buf, marshaler, ErrorChan(errCh))
 	)
 	logs.Logf(context.TODO(), "foo") // can't use plain "nil" context if you want error handling
 	if output != "" {
@@ -130,3 +151,379 @@ func TestWithStream_WithError(t *testing.T) {
 		t.Errorf("expected error but got none")
 	}
 }
+
+func TestWithStream_Canceled(t *testing.T) {
+	var (
+		marshaler = encoding.Marshaler(
+			func(_ context.Context, w io.Stream, _ string, _ ...interface{}) error {
+				return w.EOM(errors.New("some error"))
+			})
+		errCh       = make(chan error) // nobody is listening
+		logs        = WithStream(&io.BufferedStream{}, marshaler, ErrorChan(errCh))
+		ctx, cancel = stdcontext.WithCancel(stdcontext.Background())
+	)
+	cancel()
+	logs.Logf(ctx, "foo") // should not block
+}
+
+func TestWhen(t *testing.T) {
+	var (
+		count int
+		logs  = When(func(c context.Context) bool {
+			_, ok := c.Value("debug").(bool)
+			return ok
+		})(Func(func(_ context.Context, _ string, _ ...interface{}) { count++ }))
+	)
+	logs.Logf(context.TODO(), "foo")
+	if count != 0 {
+		t.Fatalf("expected log event to be discarded")
+	}
+	logs.Logf(context.WithValue(context.TODO(), "debug", true), "foo")
+	if count != 1 {
+		t.Fatalf("expected log event to be delivered")
+	}
+	if !IsNull(When(nil)(Null())) {
+		t.Fatalf("expected Null to remain Null")
+	}
+}
+
+func TestReject(t *testing.T) {
+	var (
+		output []string
+		sink   = Func(func(_ context.Context, m string, a ...interface{}) {
+			output = append(output, fmt.Sprintf(m, a...))
+		})
+		healthz = regexp.MustCompile(`/healthz\b`)
+		logs    = Reject(
+			MessageMatches(healthz).Or(
+				FormatMatches(regexp.MustCompile(`^noisy`))).Or(
+				AnyArg(func(x interface{}) bool { return x == "secret" })))(sink)
+	)
+	logs.Logf(nil, "GET %s 200", "/healthz")
+	logs.Logf(nil, "noisy %d", 1)
+	logs.Logf(nil, "password %s", "secret")
+	logs.Logf(nil, "GET %s 200", "/index.html")
+	if len(output) != 1 || output[0] != "GET /index.html 200" {
+		t.Fatalf("unexpected output: %q", output)
+	}
+}
+
+func TestRouter(t *testing.T) {
+	type tenantKey struct{}
+	var (
+		got  = map[string][]string{}
+		sink = func(name string) Logger {
+			return Func(func(_ context.Context, m string, a ...interface{}) {
+				got[name] = append(got[name], fmt.Sprintf(m, a...))
+			})
+		}
+		r      = NewRouter(tenantKey{}, sink("default"))
+		tenant = func(v interface{}) context.Context {
+			return context.WithValue(context.Background(), tenantKey{}, v)
+		}
+	)
+	r.Route("acme", sink("acme"))
+	r.Route("globex", sink("globex"))
+
+	r.Logf(tenant("acme"), "a%d", 1)
+	r.Logf(tenant("globex"), "g%d", 1)
+	r.Logf(tenant("initech"), "i%d", 1)
+	r.Logf(tenant([]string{"x"}), "x%d", 1) // not comparable
+	r.Logf(nil, "n%d", 1)
+
+	if old := r.Route("globex", nil); old == nil {
+		t.Fatal("expected previously registered route")
+	}
+	r.Logf(tenant("globex"), "g%d", 2)
+
+	expected := map[string][]string{
+		"acme":    {"a1"},
+		"globex":  {"g1"},
+		"default": {"i1", "x1", "n1", "g2"},
+	}
+	if !reflect.DeepEqual(expected, got) {
+		t.Fatalf("expected %v instead of %v", expected, got)
+	}
+}
+
+func TestHooks(t *testing.T) {
+	var (
+		calls []string
+		hooks = Hooks{
+			{
+				Before: func(c context.Context, m string, a []interface{}) (context.Context, string, []interface{}) {
+					calls = append(calls, "before1")
+					return c, "[hooked] " + m, a
+				},
+				After: func(_ context.Context, m string, _ []interface{}, err error) {
+					calls = append(calls, fmt.Sprintf("after1 %q %v", m, err))
+				},
+			},
+			{
+				After: func(_ context.Context, _ string, _ []interface{}, err error) {
+					calls = append(calls, fmt.Sprintf("after2 %v", err))
+				},
+			},
+		}
+		errFoo = errors.New("foo")
+		op     = hooks.Encoding()(func(_ context.Context, _ io.Stream, m string, a ...interface{}) error {
+			calls = append(calls, "marshal "+fmt.Sprintf(m, a...))
+			return errFoo
+		})
+	)
+	if err := op(nil, nil, "hello %d", 1); err != errFoo {
+		t.Fatalf("expected %v instead of %v", errFoo, err)
+	}
+	expected := []string{
+		"before1",
+		"marshal [hooked] hello 1",
+		`after2 foo`,
+		`after1 "[hooked] hello %d" foo`,
+	}
+	if !reflect.DeepEqual(expected, calls) {
+		t.Fatalf("expected %q instead of %q", expected, calls)
+	}
+
+	calls = nil
+	hooks.Decorator()(Func(func(_ context.Context, m string, a ...interface{}) {
+		calls = append(calls, "log "+fmt.Sprintf(m, a...))
+	})).Logf(nil, "bye")
+	expected = []string{"before1", "log [hooked] bye", "after2 <nil>", `after1 "[hooked] bye" <nil>`}
+	if !reflect.DeepEqual(expected, calls) {
+		t.Fatalf("expected %q instead of %q", expected, calls)
+	}
+}
+
+func TestWithStream_ErrorSink(t *testing.T) {
+	var (
+		expectedErr = errors.New("some error")
+		buf         = &io.BufferedStream{}
+		got         []Entry
+		logs        = WithStream(buf, func(_ context.Context, w io.Stream, _ string, _ ...interface{}) error {
+			return w.EOM(expectedErr)
+		}, ErrorSinkFunc(func(_ context.Context, e Entry, err error) {
+			if err != expectedErr {
+				t.Errorf("expected %v instead of %v", expectedErr, err)
+			}
+			got = append(got, e)
+		}))
+	)
+	args := []interface{}{1}
+	logs.Logf(nil, "foo %d", args...)
+	args[0] = 2 // loggers may reuse args, see Logger
+	if len(got) != 1 {
+		t.Fatalf("expected 1 error instead of %d", len(got))
+	}
+	if e := got[0]; e.Format != "foo %d" || !reflect.DeepEqual(e.Args, []interface{}{1}) || e.Stream != buf {
+		t.Fatalf("unexpected entry: %+v", e)
+	}
+}
+
+func TestBuilder_Then(t *testing.T) {
+	var (
+		calls []string
+		tag   = func(name string) Decorator {
+			return func(logs Logger) Logger {
+				return Func(func(c context.Context, m string, a ...interface{}) {
+					calls = append(calls, name)
+					logs.Logf(c, m, a...)
+				})
+			}
+		}
+		b = Builders{
+			Builder(WithStream).Then(tag("a"), tag("b")),
+			Builder(WithStream).Then(tag("c")),
+		}.Multi()
+		buf  = &io.BufferedStream{}
+		logs = b(buf, encoding.Format(), IgnoreErrors())
+	)
+	logs.Logf(nil, "foo")
+	if expected := []string{"b", "a", "c"}; !reflect.DeepEqual(expected, calls) {
+		t.Fatalf("expected %q instead of %q", expected, calls)
+	}
+}
+
+func TestWithPrefix(t *testing.T) {
+	var (
+		output []string
+		sink   = Func(func(_ context.Context, m string, a ...interface{}) {
+			output = append(output, encoding.Message(m, a...))
+		})
+		logs = WithPrefix("[100%] ")(sink)
+		ctxf = WithPrefixFunc(func(c context.Context) string {
+			s, _ := c.Value("component").(string)
+			return s
+		})(sink)
+	)
+	logs.Logf(nil, "foo %d", 1)
+	logs.Logf(nil, "", 1, 2)
+	Print(logs, nil, "%d", 3)
+	ctxf.Logf(context.WithValue(context.TODO(), "component", "db: "), "bar")
+	ctxf.Logf(context.TODO(), "baz")
+	expected := []string{"[100%] foo 1", "[100%] 1 2", "[100%] %d3", "db: bar", "baz"}
+	if !reflect.DeepEqual(expected, output) {
+		t.Fatalf("expected %q instead of %q", expected, output)
+	}
+}
+
+func TestErrorChan_Background(t *testing.T) {
+	var (
+		errFoo = errors.New("foo")
+		ch     = make(chan error) // nobody is listening
+	)
+	ErrorChan(ch).LogError(context.Background(), Entry{}, errFoo) // should not block
+	ErrorChan(ch).LogError(nil, Entry{}, errFoo)                  // should not block
+}
+
+func TestTryErrorChan(t *testing.T) {
+	var (
+		errFoo = errors.New("foo")
+		ch     = make(chan error, 1)
+		es     = TryErrorChan(ch)
+	)
+	es.LogError(nil, Entry{}, errFoo)
+	es.LogError(nil, Entry{}, errFoo)
+	if err := <-ch; err != errFoo {
+		t.Fatalf("expected %v instead of %v", errFoo, err)
+	}
+	if n := es.Dropped(); n != 1 {
+		t.Fatalf("expected 1 dropped error instead of %d", n)
+	}
+}
+
+func TestFallbackErrors(t *testing.T) {
+	var (
+		buf  bytes.Buffer
+		now  = time.Date(2016, 1, 2, 15, 4, 5, 0, time.UTC)
+		oops = errors.New("oops")
+		es   = FallbackErrors(&buf, FallbackOptions{Burst: 2, Clock: func() time.Time { return now }})
+	)
+	for i := 0; i < 5; i++ {
+		es.LogError(nil, Entry{Format: "hello %d"}, oops)
+	}
+	now = now.Add(time.Second)
+	es.LogError(nil, Entry{Format: "bye"}, oops)
+
+	expected := `2016-01-02T15:04:05Z gologs: failed to log event "hello %d": oops
+2016-01-02T15:04:05Z gologs: failed to log event "hello %d": oops
+2016-01-02T15:04:06Z gologs: suppressed 3 errors
+2016-01-02T15:04:06Z gologs: failed to log event "bye": oops
+`
+	if buf.String() != expected {
+		t.Fatalf("unexpected output %q", buf.String())
+	}
+	if n := es.Suppressed(); n != 3 {
+		t.Fatalf("expected 3 suppressed errors instead of %d", n)
+	}
+
+	buf.Reset()
+	for i := 0; i < 3; i++ {
+		es.LogError(nil, Entry{Format: "bye"}, oops)
+	}
+	es.Flush()
+	es.Flush()
+	expected = `2016-01-02T15:04:06Z gologs: failed to log event "bye": oops
+2016-01-02T15:04:06Z gologs: suppressed 2 errors
+`
+	if buf.String() != expected {
+		t.Fatalf("unexpected output %q", buf.String())
+	}
+}
+
+type lineWriter chan string
+
+func (w lineWriter) Write(b []byte) (int, error) {
+	w <- string(b)
+	return len(b), nil
+}
+
+func TestFallbackErrors_Interval(t *testing.T) {
+	var (
+		lines = make(lineWriter, 3)
+		es    = FallbackErrors(lines, FallbackOptions{Burst: 1, Interval: 10 * time.Millisecond})
+	)
+	for i := 0; i < 3; i++ {
+		es.LogError(nil, Entry{Format: "hello"}, errors.New("oops"))
+	}
+	<-lines
+	select {
+	case s := <-lines:
+		if !strings.HasSuffix(s, " gologs: suppressed 2 errors\n") {
+			t.Fatalf("unexpected line %q", s)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the suppressed errors to be flushed")
+	}
+}
+
+func TestAsyncErrors(t *testing.T) {
+	var (
+		errFoo  = errors.New("foo")
+		started = make(chan struct{}, 1)
+		unblock = make(chan struct{})
+		got     []error
+		es      = AsyncErrors(ErrorSinkFunc(func(_ context.Context, _ Entry, err error) {
+			started <- struct{}{}
+			<-unblock
+			got = append(got, err)
+		}), 1)
+	)
+	// the first error is consumed by the (blocked) goroutine, the second is buffered, and the
+	// rest are dropped
+	es.LogError(nil, Entry{}, errFoo)
+	<-started
+	for i := 0; i < 3; i++ {
+		es.LogError(nil, Entry{}, errFoo)
+	}
+	close(unblock)
+	es.Close()
+	es.LogError(nil, Entry{}, errFoo)
+	if len(got) != 2 || es.Dropped() != 3 {
+		t.Fatalf("expected 2 delivered and 3 dropped errors instead of %d and %d", len(got), es.Dropped())
+	}
+}
+
+func TestNilContext(t *testing.T) {
+	var (
+		buf  bytes.Buffer
+		logs = WithStream(io.TextStream(&buf), structured.Logfmt(structured.Options{Annotations: true}), nil)
+	)
+	logs = Memoize()(logs)
+	logs = WithContext(caller.WithContext(caller.Tracking{Enabled: true}), logs)
+	logs = WithContext(timestamp.NewDecorator(timestamp.Clock(time.Now)), logs)
+	logs = WithContext(requestid.NewDecorator("abc"), logs)
+	logs = WithPrefix("> ")(logs)
+	logs.Logf(nil, "hello %d", 1)
+	LogfE(logs, nil, "hello %d", 2)
+	if matched, _ := regexp.MatchString(`^(time=\S+ msg="> hello \d" caller=\S+ request_id=abc\n){2}$`, buf.String()); !matched {
+		t.Fatalf("unexpected output %q", buf.String())
+	}
+}
+
+func TestWithCallDepth(t *testing.T) {
+	var (
+		got  []caller.Caller
+		logs = WithContext(caller.WithContext(caller.Tracking{Enabled: true, Depth: 3}),
+			Func(func(c context.Context, _ string, _ ...interface{}) {
+				x, _ := caller.FromContext(c)
+				got = append(got, x)
+			}))
+		bridge = func(logs Logger) { logs.Logf(context.TODO(), "bridged") }
+	)
+	logs.Logf(context.TODO(), "direct")
+	bridge(WithCallDepth(1)(logs))
+	bridge(logs)
+
+	if len(got) != 3 {
+		t.Fatalf("expected 3 callers instead of %d", len(got))
+	}
+	if f := got[0].FuncName; !strings.HasSuffix(f, ".TestWithCallDepth") {
+		t.Fatalf("unexpected caller %q", f)
+	}
+	if got[1].FuncName != got[0].FuncName {
+		t.Errorf("expected caller %q instead of %q", got[0].FuncName, got[1].FuncName)
+	}
+	if got[2].FuncName == got[0].FuncName {
+		t.Errorf("expected the bridge to be reported without WithCallDepth")
+	}
+}
@@ -0,0 +1,74 @@
+/*
+Copyright 2016 James DeFelice
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logger
+
+import (
+	"sync/atomic"
+
+	"github.com/gologs/log/context"
+)
+
+type pathKey struct{}
+
+var tracing int32
+
+// SetTracing enables, or disables, the recording of the names of the Named decorators (and of
+// levels.Named transforms) that log events pass through, see Path. It's a debugging aid for
+// complex pipelines: while enabled, each named step costs an allocation per log event.
+func SetTracing(enabled bool) {
+	var x int32
+	if enabled {
+		x = 1
+	}
+	atomic.StoreInt32(&tracing, x)
+}
+
+// Path returns the names of the Named decorators that a log event passed through, in order,
+// while tracing was enabled (see SetTracing).
+func Path(c context.Context) []string {
+	if c == nil {
+		return nil
+	}
+	p, _ := c.Value(pathKey{}).([]string)
+	return p
+}
+
+// Traced returns a Logger that, while tracing is enabled (see SetTracing), appends `name` to
+// the Path of each log event before handing it to `logs`.
+func Traced(name string, logs Logger) Logger {
+	if IsNull(logs) {
+		return logs
+	}
+	return Func(func(c context.Context, m string, a ...interface{}) {
+		if atomic.LoadInt32(&tracing) != 0 {
+			if c == nil {
+				c = context.Background()
+			}
+			p := Path(c)
+			c = context.WithValue(c, pathKey{}, append(p[:len(p):len(p)], name))
+		}
+		logs.Logf(c, m, a...)
+	})
+}
+
+// Named returns a Decorator that identifies `d` by `name` in the Path of log events. Log events
+// are attributed to `d` as they enter the Logger that it generates.
+func Named(name string, d Decorator) Decorator {
+	return func(logs Logger) Logger {
+		return Traced(name, d(logs))
+	}
+}
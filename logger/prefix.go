@@ -0,0 +1,82 @@
+/*
+Copyright 2016 James DeFelice
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logger
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/gologs/log/context"
+)
+
+// maxPooledArgs bounds the capacity of the arg slices that are returned to the argSlices pool
+const maxPooledArgs = 32
+
+var argSlices = sync.Pool{New: func() interface{} {
+	a := make([]interface{}, 0, 8)
+	return &a
+}}
+
+// logPrefixed logs a log event with the given prefix. The args of print-style log events are
+// prefixed by way of a pooled slice, which is reused once Logf returns.
+func logPrefixed(logs Logger, c context.Context, prefix, m string, a []interface{}) {
+	if m != "" {
+		logs.Logf(c, strings.Replace(prefix, "%", "%%", -1)+m, a...)
+		return
+	}
+	ap := argSlices.Get().(*[]interface{})
+	b := append(append((*ap)[:0], prefix), a...)
+	logs.Logf(c, m, b...)
+	for i := range b {
+		b[i] = nil // don't pin the args
+	}
+	if cap(b) <= maxPooledArgs {
+		*ap = b[:0]
+		argSlices.Put(ap)
+	}
+}
+
+// WithPrefix returns a Decorator that prepends the given prefix (for example a component tag
+// like "[scheduler] ") to the message of every log event. Unlike encoding.Prefix, this works
+// for any Logger, including those that don't write to a Stream.
+func WithPrefix(prefix string) Decorator {
+	return func(logs Logger) Logger {
+		if prefix == "" || IsNull(logs) {
+			return logs
+		}
+		return Func(func(c context.Context, m string, a ...interface{}) {
+			logPrefixed(logs, c, prefix, m, a)
+		})
+	}
+}
+
+// WithPrefixFunc is like WithPrefix, except that the prefix of each log event is generated
+// from its context. Empty prefixes are ignored.
+func WithPrefixFunc(f func(context.Context) string) Decorator {
+	return func(logs Logger) Logger {
+		if f == nil || IsNull(logs) {
+			return logs
+		}
+		return Func(func(c context.Context, m string, a ...interface{}) {
+			if prefix := f(c); prefix != "" {
+				logPrefixed(logs, c, prefix, m, a)
+				return
+			}
+			logs.Logf(c, m, a...)
+		})
+	}
+}
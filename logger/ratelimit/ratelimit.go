@@ -0,0 +1,233 @@
+/*
+Copyright 2016 James DeFelice
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package ratelimit limits the rate of log events with token buckets, optionally one bucket per
+// key (request ID, tenant, peer IP) so that one noisy client can't consume the log budget of
+// everyone else.
+package ratelimit
+
+import (
+	"container/list"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/gologs/log/context"
+	"github.com/gologs/log/context/fields"
+	"github.com/gologs/log/context/timestamp"
+	"github.com/gologs/log/logger"
+	"github.com/gologs/log/stats"
+)
+
+// Options defaults
+const (
+	DefaultRate    = 100
+	DefaultMaxKeys = 10000
+)
+
+// Key selects the bucket of a log event from its context; log events for which ok is false
+// share a global bucket.
+type Key func(context.Context) (key string, ok bool)
+
+// Value returns a Key that selects buckets by the value of the context for the given key, as
+// rendered by fmt.Sprint.
+func Value(key interface{}) Key {
+	return func(c context.Context) (string, bool) {
+		if c == nil {
+			return "", false
+		}
+		if v := c.Value(key); v != nil {
+			return fmt.Sprint(v), true
+		}
+		return "", false
+	}
+}
+
+// Field returns a Key that selects buckets by the value of the named field (see fields), or else
+// by that of the registered annotation with the given name (see context.RegisterField), for
+// example "request_id".
+func Field(name string) Key {
+	return func(c context.Context) (string, bool) {
+		if v, ok := fields.FromContext(c).Get(name); ok {
+			return fmt.Sprint(v), true
+		}
+		if c == nil {
+			return "", false
+		}
+		for _, a := range context.Annotations(c) {
+			if a.Name == name {
+				return fmt.Sprint(a.Value), true
+			}
+		}
+		return "", false
+	}
+}
+
+// Options customize the behavior of a Limiter.
+type Options struct {
+	// Rate is the number of log events per second that each bucket allows, defaults to
+	// DefaultRate.
+	Rate float64
+	// Burst is the capacity of each bucket, defaults to Rate (but at least 1).
+	Burst int
+	// Key selects the bucket of each log event; nil selects a single, global bucket.
+	Key Key
+	// MaxKeys limits the number of keyed buckets, the least recently used of which are
+	// forgotten; defaults to DefaultMaxKeys.
+	MaxKeys int
+	// Priority (optional) returns true for log events that must not be limited, for example
+	// async.WarnOrAbove.
+	Priority func(context.Context) bool
+	// Stats, if not nil, registers the counters of the Limiter (see Stats) as Name+".allowed"
+	// and Name+".dropped".
+	Stats *stats.Registry
+	// Name prefixes the names of registered counters, defaults to "ratelimit".
+	Name string
+}
+
+// Stats is a snapshot of the counters maintained by a Limiter.
+type Stats struct {
+	Allowed uint64 // Allowed counts log events that were logged
+	Dropped uint64 // Dropped counts log events that exceeded the rate of their bucket
+}
+
+type bucket struct {
+	key    string
+	tokens float64
+	last   time.Time
+}
+
+// Limiter is a set of token buckets. It is safe for concurrent use.
+type Limiter struct {
+	opts Options
+
+	mu      sync.Mutex
+	global  bucket
+	buckets map[string]*list.Element // buckets of the lru list, by key
+	lru     list.List                // lru lists keyed buckets, most recently used first
+
+	allowed, dropped stats.Counter
+}
+
+// New returns a Limiter with full buckets.
+func New(opts Options) *Limiter {
+	if opts.Rate <= 0 {
+		opts.Rate = DefaultRate
+	}
+	if opts.Burst <= 0 {
+		opts.Burst = int(opts.Rate)
+		if opts.Burst < 1 {
+			opts.Burst = 1
+		}
+	}
+	if opts.MaxKeys <= 0 {
+		opts.MaxKeys = DefaultMaxKeys
+	}
+	l := &Limiter{
+		opts:    opts,
+		global:  bucket{tokens: float64(opts.Burst)},
+		buckets: make(map[string]*list.Element),
+	}
+	if opts.Stats != nil {
+		if opts.Name == "" {
+			opts.Name = "ratelimit"
+		}
+		opts.Stats.Register(opts.Name+".allowed", l.allowed.Load)
+		opts.Stats.Register(opts.Name+".dropped", l.dropped.Load)
+	}
+	return l
+}
+
+// Stats returns a snapshot of the counters of the Limiter.
+func (l *Limiter) Stats() Stats {
+	return Stats{Allowed: l.allowed.Load(), Dropped: l.dropped.Load()}
+}
+
+// bucket returns the bucket for the given key; l.mu must be held
+func (l *Limiter) bucket(key string, ok bool) *bucket {
+	if !ok {
+		return &l.global
+	}
+	if e, found := l.buckets[key]; found {
+		l.lru.MoveToFront(e)
+		return e.Value.(*bucket)
+	}
+	if l.lru.Len() >= l.opts.MaxKeys {
+		e := l.lru.Back()
+		l.lru.Remove(e)
+		delete(l.buckets, e.Value.(*bucket).key)
+	}
+	b := &bucket{key: key, tokens: float64(l.opts.Burst)}
+	l.buckets[key] = l.lru.PushFront(b)
+	return b
+}
+
+// Allow returns true if a log event with the given context is within the rate of its bucket,
+// in which case a token is taken from the bucket.
+func (l *Limiter) Allow(c context.Context) bool {
+	if l.opts.Priority != nil && l.opts.Priority(c) {
+		l.allowed.Inc()
+		return true
+	}
+	var key string
+	ok := false
+	if l.opts.Key != nil {
+		key, ok = l.opts.Key(c)
+	}
+	t, found := timestamp.FromContext(c)
+	if !found {
+		t = time.Now()
+	}
+
+	l.mu.Lock()
+	b := l.bucket(key, ok)
+	if !b.last.IsZero() && t.After(b.last) {
+		b.tokens += t.Sub(b.last).Seconds() * l.opts.Rate
+		if burst := float64(l.opts.Burst); b.tokens > burst {
+			b.tokens = burst
+		}
+	}
+	if b.last.IsZero() || t.After(b.last) {
+		b.last = t
+	}
+	allow := b.tokens >= 1
+	if allow {
+		b.tokens--
+	}
+	l.mu.Unlock()
+
+	if allow {
+		l.allowed.Inc()
+	} else {
+		l.dropped.Inc()
+	}
+	return allow
+}
+
+// Decorator returns a logger.Decorator that discards log events that aren't allowed by the
+// Limiter (see Allow).
+func (l *Limiter) Decorator() logger.Decorator {
+	return func(logs logger.Logger) logger.Logger {
+		if logger.IsNull(logs) {
+			return logs
+		}
+		return logger.Func(func(c context.Context, m string, a ...interface{}) {
+			if l.Allow(c) {
+				logs.Logf(c, m, a...)
+			}
+		})
+	}
+}
@@ -0,0 +1,77 @@
+/*
+Copyright 2016 James DeFelice
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ratelimit_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gologs/log/config"
+	"github.com/gologs/log/context/fields"
+	"github.com/gologs/log/levels"
+	. "github.com/gologs/log/logger/ratelimit"
+	"github.com/gologs/log/logtest"
+	"github.com/gologs/log/stats"
+)
+
+func TestLimiter(t *testing.T) {
+	var (
+		rec   = logtest.NewRecorder()
+		now   = time.Unix(0, 0)
+		clock = func() time.Time { return now }
+		reg   = stats.NewRegistry()
+		l     = New(Options{Rate: 2, Key: Field("tenant"), MaxKeys: 2, Stats: reg})
+		logs  = rec.Interface(config.Decorate(l.Decorator()), config.Clock(clock))
+		as    = func(tenant string) levels.Interface {
+			return levels.WithContext(logs, fields.NewDecorator(fields.F("tenant", tenant)))
+		}
+		count = func(tenant string) (n int) {
+			for _, e := range rec.Entries() {
+				if v, _ := fields.FromContext(e.Context).Get("tenant"); v == tenant {
+					n++
+				}
+			}
+			return
+		}
+	)
+	noisy, quiet := as("noisy"), as("quiet")
+	for i := 0; i < 10; i++ {
+		noisy.Info("flood")
+	}
+	quiet.Info("hello")
+	logs.Info("global")
+	if n := count("noisy"); n != 2 {
+		t.Errorf("expected the burst of 2 log events for the noisy tenant instead of %d", n)
+	}
+	if n := count("quiet"); n != 1 {
+		t.Errorf("expected the quiet tenant to be unaffected, but %d were logged", n)
+	}
+	if err := rec.Expect(levels.Info, "global"); err != nil {
+		t.Error(err)
+	}
+
+	now = now.Add(500 * time.Millisecond) // refills a token
+	noisy.Info("flood")
+	noisy.Info("flood")
+	if n := count("noisy"); n != 3 {
+		t.Errorf("expected 3 log events for the noisy tenant instead of %d", n)
+	}
+	expected := stats.Snapshot{"ratelimit.allowed": 5, "ratelimit.dropped": 9}
+	if s := reg.Stats(); !s.Equal(expected) {
+		t.Errorf("expected stats %v instead of %v", expected, s)
+	}
+}
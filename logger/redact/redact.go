@@ -53,6 +53,10 @@ func Decorator() logger.Decorator {
 	})
 }
 
+// Builder returns a logger.Builder that generates redacting stream loggers, see
+// logger.WithStream.
+func Builder() logger.Builder { return logger.Builder(logger.WithStream).Then(Default) }
+
 // Simple impements Interface
 type Simple int
 
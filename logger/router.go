@@ -0,0 +1,84 @@
+/*
+Copyright 2016 James DeFelice
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logger
+
+import (
+	"reflect"
+	"sync"
+
+	"github.com/gologs/log/context"
+)
+
+// Router is a Logger that forwards each log event to a destination selected by the value that
+// the event's context associates with a routing key (for example a tenant ID or component
+// name). Events without a registered destination are forwarded to the default Logger. It is
+// safe for concurrent use.
+type Router struct {
+	key    interface{}
+	def    Logger
+	mu     sync.RWMutex
+	routes map[interface{}]Logger
+}
+
+var _ = Logger(&Router{}) // Router implements Logger
+
+// NewRouter returns a Router that selects destinations by the context value of `key`. A nil
+// default discards events that lack a registered destination.
+func NewRouter(key interface{}, def Logger) *Router {
+	if def == nil {
+		def = Null()
+	}
+	return &Router{key: key, def: def, routes: make(map[interface{}]Logger)}
+}
+
+// Route registers `logs` as the destination for events whose routing value equals `value`,
+// and returns the previously registered destination (if any). A nil `logs` removes the route.
+// Panics if `value` is not comparable.
+func (r *Router) Route(value interface{}, logs Logger) (old Logger) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	old = r.routes[value]
+	if logs == nil {
+		delete(r.routes, value)
+	} else {
+		r.routes[value] = logs
+	}
+	return
+}
+
+// Lookup returns the destination for log events generated with the given context.
+func (r *Router) Lookup(c context.Context) Logger {
+	if c == nil {
+		return r.def
+	}
+	v := c.Value(r.key)
+	if v == nil || !reflect.TypeOf(v).Comparable() {
+		return r.def
+	}
+	r.mu.RLock()
+	logs, ok := r.routes[v]
+	r.mu.RUnlock()
+	if !ok {
+		return r.def
+	}
+	return logs
+}
+
+// Logf implements Logger
+func (r *Router) Logf(c context.Context, m string, a ...interface{}) {
+	r.Lookup(c).Logf(c, m, a...)
+}
@@ -0,0 +1,164 @@
+/*
+Copyright 2016 James DeFelice
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package template renders message templates, message formats with named placeholders such as
+// "user {user} logged in from {ip}", à la Serilog. The values of placeholders, and the template
+// itself, are captured as structured fields (see fields) so that log events may be queried by
+// value and grouped by template.
+package template
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gologs/log/context"
+	"github.com/gologs/log/context/fields"
+	"github.com/gologs/log/logger"
+)
+
+// DefaultKey is the default key of the field that captures the message template.
+const DefaultKey = "template"
+
+type segment struct {
+	text string
+	hole bool // hole is true if text is the name of a placeholder
+}
+
+// parse splits a message template into literal text and placeholders; ok is false if there are
+// no placeholders. "{{" and "}}" are escaped braces.
+func parse(m string) (segs []segment, ok bool) {
+	var lit strings.Builder
+	for i := 0; i < len(m); i++ {
+		c := m[i]
+		if (c == '{' || c == '}') && i+1 < len(m) && m[i+1] == c {
+			lit.WriteByte(c)
+			i++
+			continue
+		}
+		if c == '{' {
+			if j := strings.IndexByte(m[i+1:], '}'); j > 0 && isName(m[i+1:i+1+j]) {
+				if lit.Len() > 0 {
+					segs = append(segs, segment{text: lit.String()})
+					lit.Reset()
+				}
+				segs = append(segs, segment{text: m[i+1 : i+1+j], hole: true})
+				i += j + 1
+				ok = true
+				continue
+			}
+		}
+		lit.WriteByte(c)
+	}
+	if lit.Len() > 0 {
+		segs = append(segs, segment{text: lit.String()})
+	}
+	return
+}
+
+func isName(s string) bool {
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if !(c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9') {
+			return false
+		}
+	}
+	return true
+}
+
+// render binds the placeholders of template `m` to args `a` and returns a print-style log event
+func render(key string, c context.Context, m string, a []interface{}) (context.Context, string, []interface{}) {
+	if m == "" {
+		return c, m, a
+	}
+	segs, ok := parse(m)
+	if !ok {
+		return c, m, a
+	}
+	var (
+		b     strings.Builder
+		bound fields.Fields
+		ctx   = fields.FromContext(c)
+	)
+	for _, s := range segs {
+		if !s.hole {
+			b.WriteString(s.text)
+			continue
+		}
+		v, ok := bound.Get(s.text)
+		if !ok && len(a) > 0 {
+			v, a, ok = a[0], a[1:], true
+			bound = append(bound, fields.F(s.text, v))
+		}
+		if !ok {
+			v, ok = ctx.Get(s.text)
+		}
+		if !ok {
+			b.WriteString("{" + s.text + "}")
+			continue
+		}
+		fmt.Fprint(&b, v)
+	}
+	if len(a) > 0 {
+		// like fmt, for args in excess of the placeholders
+		b.WriteString("%!(EXTRA ")
+		for i, v := range a {
+			if i > 0 {
+				b.WriteString(", ")
+			}
+			fmt.Fprintf(&b, "%T=%v", v, v)
+		}
+		b.WriteByte(')')
+	}
+	if key != "-" {
+		bound = append(fields.Fields{fields.F(key, m)}, bound...)
+	}
+	return fields.NewContext(c, bound...), "", []interface{}{b.String()}
+}
+
+func orDefault(key string) string {
+	if key == "" {
+		return DefaultKey
+	}
+	return key
+}
+
+// Hook returns a logger.Hook that renders message templates before log events are marshaled
+// (see config.Hooks), for example Infof("user {user} logged in from {ip}", name, addr).
+// Placeholders are bound, in order of first appearance, to the args of the log event, which are
+// added as fields by the names of the placeholders. Placeholders in excess of the args are
+// resolved from the fields of the context, or else rendered as-is. The template is added as a
+// field by the given key (DefaultKey if empty, omitted if "-"). Message formats without
+// placeholders, and print-style log events, are left as-is.
+func Hook(key string) logger.Hook {
+	key = orDefault(key)
+	return logger.Hook{
+		Before: func(c context.Context, m string, a []interface{}) (context.Context, string, []interface{}) {
+			return render(key, c, m, a)
+		},
+	}
+}
+
+// Decorator returns a logger.Decorator that renders message templates like Hook does, for
+// Logger-based sinks.
+func Decorator(key string) logger.Decorator {
+	key = orDefault(key)
+	return func(logs logger.Logger) logger.Logger {
+		return logger.Func(func(c context.Context, m string, a ...interface{}) {
+			c, m, a = render(key, c, m, a)
+			logs.Logf(c, m, a...)
+		})
+	}
+}
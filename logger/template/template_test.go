@@ -0,0 +1,70 @@
+/*
+Copyright 2016 James DeFelice
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package template_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/gologs/log/config"
+	"github.com/gologs/log/context/fields"
+	"github.com/gologs/log/levels"
+	"github.com/gologs/log/logger"
+	. "github.com/gologs/log/logger/template"
+	"github.com/gologs/log/logtest"
+)
+
+func TestTemplate(t *testing.T) {
+	for name, d := range map[string]logger.Decorator{
+		"Hook":      logger.Hooks{Hook("")}.Decorator(),
+		"Decorator": Decorator(""),
+	} {
+		var (
+			rec  = logtest.NewRecorder()
+			logs = rec.Interface(config.Decorate(d))
+		)
+		logs.Infof("user {user} logged in from {ip}", "alice", "10.0.0.1")
+		levels.WithContext(logs, fields.NewDecorator(fields.F("ip", "10.0.0.2"))).Infof("{user} {{x}} {user} from {ip} in {zone}", "bob")
+		logs.Infof("user {user}", "carol", 3)
+		logs.Infof("plain %d {}", 1)
+		logs.Info("print {user}")
+
+		entries := rec.Entries()
+		for i, expected := range []string{
+			"user alice logged in from 10.0.0.1",
+			"bob {x} bob from 10.0.0.2 in {zone}",
+			"user carol%!(EXTRA int=3)",
+			"plain 1 {}",
+			"print {user}",
+		} {
+			if entries[i].Message != expected {
+				t.Errorf("%s: expected %q instead of %q", name, expected, entries[i].Message)
+			}
+		}
+		expected := fields.Fields{
+			fields.F("template", "user {user} logged in from {ip}"),
+			fields.F("user", "alice"),
+			fields.F("ip", "10.0.0.1"),
+		}
+		if ff := fields.FromContext(entries[0].Context); !reflect.DeepEqual(ff, expected) {
+			t.Errorf("%s: expected fields %v instead of %v", name, expected, ff)
+		}
+		if ff := fields.FromContext(entries[3].Context); len(ff) != 0 {
+			t.Errorf("%s: unexpected fields %v", name, ff)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+/*
+Copyright 2016 James DeFelice
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logio
+
+import (
+	"bufio"
+	stdio "io"
+
+	"github.com/gologs/log/context/fields"
+	"github.com/gologs/log/levels"
+)
+
+// MaxLineLength is the length beyond which Copy splits lines into multiple log events.
+const MaxLineLength = 64 * 1024
+
+// scanLines is like bufio.ScanLines, except that lines longer than MaxLineLength are split
+func scanLines(data []byte, atEOF bool) (int, []byte, error) {
+	advance, token, err := bufio.ScanLines(data, atEOF)
+	if advance == 0 && token == nil && err == nil && len(data) >= MaxLineLength {
+		return MaxLineLength, data[:MaxLineLength], nil
+	}
+	return advance, token, err
+}
+
+// Copy reads lines from `r` until EOF, logging each line as a print-style event at the given
+// level via `i`, with the given fields (see fields.NewContext). Fatal and Panic are logged at
+// Error. Lines are read only as fast as they're logged, which exerts backpressure on the writer
+// of `r`, for example a subprocess, FIFO, or a file that's being migrated. It returns the number
+// of lines logged and the error, if any, that ended reading (EOF is not an error).
+func Copy(i levels.Interface, r stdio.Reader, lvl levels.Level, f ...fields.Field) (n int, err error) {
+	if len(f) > 0 {
+		i = levels.WithContext(i, fields.NewDecorator(f...))
+	}
+	s := bufio.NewScanner(r)
+	s.Buffer(make([]byte, 0, 4096), MaxLineLength)
+	s.Split(scanLines)
+	for s.Scan() {
+		printAt(i, lvl, s.Text())
+		n++
+	}
+	return n, s.Err()
+}
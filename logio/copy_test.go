@@ -0,0 +1,53 @@
+/*
+Copyright 2016 James DeFelice
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logio_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gologs/log/context/fields"
+	"github.com/gologs/log/levels"
+	. "github.com/gologs/log/logio"
+	"github.com/gologs/log/logtest"
+)
+
+func TestCopy(t *testing.T) {
+	var (
+		rec  = logtest.NewRecorder()
+		long = strings.Repeat("x", MaxLineLength+1)
+		in   = "first\r\nsecond\n" + long + "\nlast"
+	)
+	n, err := Copy(rec.Interface(), strings.NewReader(in), levels.Info, fields.F("src", "child"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 5 {
+		t.Fatalf("expected 5 lines instead of %d", n)
+	}
+	entries := rec.Entries()
+	for i, expected := range []string{"first", "second", long[:MaxLineLength], "x", "last"} {
+		e := entries[i]
+		if e.Level != levels.Info || e.Message != expected {
+			t.Errorf("unexpected entry %d: %v", i, e)
+		}
+		ff := fields.FromContext(e.Context)
+		if len(ff) != 1 || ff[0].Key != "src" || ff[0].Value != "child" {
+			t.Errorf("unexpected fields for entry %d: %v", i, ff)
+		}
+	}
+}
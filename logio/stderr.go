@@ -0,0 +1,58 @@
+/*
+Copyright 2016 James DeFelice
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package logio adopts output that's generated outside of the logging API, such as the stderr
+// of the process, into the logging pipeline.
+package logio
+
+import (
+	"errors"
+
+	"github.com/gologs/log/levels"
+)
+
+// ErrUnsupported is returned by CaptureStderr on platforms that don't support redirecting file
+// descriptors.
+var ErrUnsupported = errors.New("capturing stderr is not supported on this platform")
+
+// CaptureStderr redirects the stderr file descriptor (fd 2) of the process to a pipe and logs each
+// line read from it at the given level via `i`, so that output which bypasses the logging API (C
+// libraries, the runtime's own warnings) ends up in the log stream, for example on servers where
+// stderr is discarded. Runtime panics and fatal errors are not captured: the process exits before
+// their output could be logged, so it's lost; capture those from a parent process instead (see
+// Copy). os.Stderr is replaced by a duplicate of the original stderr so that Go code, and sinks
+// that write to os.Stderr (see io.Stderr), still reach it. Sinks that were created beforehand with
+// a reference to os.Stderr must not be used by `i`, otherwise their output is captured again,
+// endlessly. The returned func restores stderr, after logging any output that remains; it waits at
+// most a second for child processes that inherited stderr. Fatal and Panic levels are logged at
+// Error.
+func CaptureStderr(i levels.Interface, lvl levels.Level) (restore func() error, err error) {
+	return captureStderr(i, lvl)
+}
+
+// printAt logs a line, print-style, at the given level
+func printAt(i levels.Interface, lvl levels.Level, line string) {
+	switch lvl {
+	case levels.Debug:
+		i.Debug(line)
+	case levels.Info:
+		i.Info(line)
+	case levels.Warn:
+		i.Warn(line)
+	default:
+		i.Error(line)
+	}
+}
@@ -0,0 +1,107 @@
+//go:build linux || darwin
+
+/*
+Copyright 2016 James DeFelice
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logio_test
+
+import (
+	"os"
+	"os/exec"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/gologs/log/levels"
+	. "github.com/gologs/log/logio"
+	"github.com/gologs/log/logtest"
+)
+
+func TestCaptureStderr(t *testing.T) {
+	var (
+		rec    = logtest.NewRecorder()
+		stderr = os.Stderr
+	)
+	restore, err := CaptureStderr(rec.Interface(), levels.Warn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	syscall.Write(2, []byte("runtime: 100% broken\nsecond line\n"))
+	if err := restore(); err != nil {
+		t.Fatal(err)
+	}
+	if os.Stderr != stderr {
+		t.Fatalf("expected os.Stderr to be restored")
+	}
+	for _, line := range []string{"runtime: 100% broken", "second line"} {
+		if err := rec.Expect(levels.Warn, line); err != nil {
+			t.Error(err)
+		}
+	}
+	if n := len(rec.Entries()); n != 2 {
+		t.Fatalf("expected 2 entries instead of %d", n)
+	}
+}
+
+func TestCaptureStderr_LongLine(t *testing.T) {
+	rec := logtest.NewRecorder()
+	restore, err := CaptureStderr(rec.Interface(), levels.Warn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the reader must keep up with a line that exceeds its buffer, lest the writer block
+	syscall.Write(2, []byte(strings.Repeat("x", MaxLineLength+10)+"\nlast line\n"))
+	if err := restore(); err != nil {
+		t.Fatal(err)
+	}
+	if err := rec.Expect(levels.Warn, "last line"); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(rec.Entries()); n != 3 {
+		t.Fatalf("expected 3 entries instead of %d", n)
+	}
+}
+
+func TestCaptureStderr_Child(t *testing.T) {
+	rec := logtest.NewRecorder()
+	restore, err := CaptureStderr(rec.Interface(), levels.Warn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the child inherits the pipe, and so keeps it open after restore
+	path, err := exec.LookPath("sleep")
+	if err != nil {
+		restore()
+		t.Skip(err)
+	}
+	pid, err := syscall.ForkExec(path, []string{"sleep", "10"}, &syscall.ProcAttr{Files: []uintptr{0, 1, 2}})
+	if err != nil {
+		restore()
+		t.Fatal(err)
+	}
+	defer func() {
+		syscall.Kill(pid, syscall.SIGKILL)
+		syscall.Wait4(pid, nil, 0, nil)
+	}()
+	start := time.Now()
+	if err := restore(); err != nil {
+		t.Fatal(err)
+	}
+	if d := time.Since(start); d > 5*time.Second {
+		t.Fatalf("restore blocked for %v", d)
+	}
+}
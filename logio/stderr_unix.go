@@ -0,0 +1,73 @@
+//go:build darwin || dragonfly || freebsd || linux || netbsd || openbsd
+
+/*
+Copyright 2016 James DeFelice
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logio
+
+import (
+	"os"
+	"syscall"
+	"time"
+
+	"github.com/gologs/log/levels"
+)
+
+// drainTimeout bounds the time that restore waits for the reader to drain the pipe: a child
+// process that inherited stderr keeps the pipe open, in which case its output is cut short.
+const drainTimeout = time.Second
+
+func captureStderr(i levels.Interface, lvl levels.Level) (func() error, error) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		return nil, err
+	}
+	saved, err := syscall.Dup(2)
+	if err != nil {
+		r.Close()
+		w.Close()
+		return nil, err
+	}
+	if err = dup2(int(w.Fd()), 2); err != nil {
+		r.Close()
+		w.Close()
+		syscall.Close(saved)
+		return nil, err
+	}
+	w.Close() // fd 2 is now the only writer
+	var (
+		stderr = os.Stderr
+		done   = make(chan struct{})
+	)
+	os.Stderr = os.NewFile(uintptr(saved), "/dev/stderr")
+	go func() {
+		defer close(done)
+		defer r.Close()
+		_, _ = Copy(i, r, lvl)
+	}()
+	return func() error {
+		err := dup2(saved, 2) // closes the writer, so the reader drains the pipe and stops
+		select {
+		case <-done:
+		case <-time.After(drainTimeout):
+			r.Close() // unblocks the reader
+			<-done
+		}
+		os.Stderr.Close()
+		os.Stderr = stderr
+		return err
+	}, nil
+}
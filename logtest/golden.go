@@ -0,0 +1,143 @@
+/*
+Copyright 2016 James DeFelice
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logtest
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gologs/log/caller"
+	"github.com/gologs/log/config"
+	"github.com/gologs/log/context"
+	"github.com/gologs/log/context/timestamp"
+	"github.com/gologs/log/encoding"
+	"github.com/gologs/log/io"
+	"github.com/gologs/log/levels"
+)
+
+// Update, when true, instructs Golden.Compare to (re)write golden files instead of comparing
+// against them. Set via the -logtest.update flag, for example:
+//
+//	go test ./... -args -logtest.update
+var Update = flag.Bool("logtest.update", false, "update logtest golden files")
+
+var (
+	// Epoch is the fixed timestamp reported for every log event rendered by Golden.
+	Epoch = time.Date(2016, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	// FixedCaller is reported for every log event rendered by Golden.
+	FixedCaller = caller.Caller{File: "golden.go", Line: 1, FuncName: "golden"}
+)
+
+// Golden renders log events deterministically (fixed timestamp and caller) to an in-memory
+// text stream whose contents may be compared against a golden file. It is safe for concurrent
+// use.
+type Golden struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+// NewGolden returns an empty Golden.
+func NewGolden() *Golden { return &Golden{} }
+
+// Write implements io.Writer
+func (g *Golden) Write(b []byte) (int, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.buf.Write(b)
+}
+
+// String returns everything rendered so far.
+func (g *Golden) String() string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.buf.String()
+}
+
+// Interface returns a levels.Interface that logs all levels to the receiver, one line per log
+// event. Fatal and Panic log events do not exit or panic. The given Options are applied after
+// the defaults; the timestamp and caller of every event are fixed regardless (see Epoch and
+// FixedCaller).
+func (g *Golden) Interface(opt ...config.Option) levels.Interface {
+	opts := append([]config.Option{
+		config.Stream(io.TextStream(g)),
+		config.Level(levels.Debug),
+		config.OnExit(config.NoExit()),
+		config.OnPanic(config.NoPanic()),
+	}, opt...)
+	// the last encoding decorator is the first to be invoked, so this fixes things up before
+	// any user-specified decorators have a chance to read them.
+	opts = append(opts, config.Encoding(encoding.WithContext(func(c context.Context) context.Context {
+		c = timestamp.NewContext(c, Epoch)
+		return caller.NewContext(c, FixedCaller.File, FixedCaller.Line, FixedCaller.FuncName)
+	})))
+	return config.DefaultConfig.With(opts...)
+}
+
+// Compare returns an error that describes the differences between what's been rendered and the
+// contents of the golden file at `path`. If Update is true then the golden file is written
+// instead (creating parent directories as needed).
+func (g *Golden) Compare(path string) error {
+	actual := g.String()
+	if *Update {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return err
+		}
+		return ioutil.WriteFile(path, []byte(actual), 0644)
+	}
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	return diff(path, string(b), actual)
+}
+
+func diff(path, expected, actual string) error {
+	if expected == actual {
+		return nil
+	}
+	var (
+		el  = strings.Split(expected, "\n")
+		al  = strings.Split(actual, "\n")
+		buf bytes.Buffer
+		n   = len(el)
+	)
+	if len(al) > n {
+		n = len(al)
+	}
+	fmt.Fprintf(&buf, "output does not match golden file %s (rerun with -logtest.update to accept):", path)
+	for i := 0; i < n; i++ {
+		var e, a string
+		if i < len(el) {
+			e = el[i]
+		}
+		if i < len(al) {
+			a = al[i]
+		}
+		if e != a {
+			fmt.Fprintf(&buf, "\nline %d:\n\t-%s\n\t+%s", i+1, e, a)
+		}
+	}
+	return fmt.Errorf("%s", buf.String())
+}
@@ -0,0 +1,49 @@
+/*
+Copyright 2016 James DeFelice
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logtest_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gologs/log/config"
+	"github.com/gologs/log/io/ioutil"
+	. "github.com/gologs/log/logtest"
+)
+
+func TestGolden(t *testing.T) {
+	var (
+		g    = NewGolden()
+		logs = g.Interface(config.Encoding(ioutil.GlogHeader()))
+	)
+	logs.Debugf("hello %s", "world")
+	logs.Warn("the end is near")
+	logs.Fatal("the end")
+
+	if err := g.Compare("testdata/golden.txt"); err != nil {
+		t.Fatal(err)
+	}
+	if *Update {
+		return
+	}
+
+	logs.Info("unexpected")
+	err := g.Compare("testdata/golden.txt")
+	if err == nil || !strings.Contains(err.Error(), "+I0101 00:00:00.000000 unexpected") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,158 @@
+/*
+Copyright 2016 James DeFelice
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package logtest provides helpers for capturing and asserting upon log events in tests.
+package logtest
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gologs/log/caller"
+	"github.com/gologs/log/config"
+	"github.com/gologs/log/context"
+	"github.com/gologs/log/context/timestamp"
+	"github.com/gologs/log/encoding"
+	"github.com/gologs/log/levels"
+	"github.com/gologs/log/logger"
+)
+
+// CallerDepth is appropriate for log events generated by invoking the methods of a
+// levels.Interface produced by this package directly.
+const CallerDepth = config.DefaultCallerDepth - 1
+
+// Entry is a recorded log event.
+type Entry struct {
+	Level   levels.Level
+	Time    time.Time
+	Caller  caller.Caller // Caller is the zero value if call tracking was disabled
+	Format  string
+	Args    []interface{}
+	Message string          // Message is the result of formatting Args per Format
+	Context context.Context // Context is the original context of the log event
+}
+
+// String implements fmt.Stringer
+func (e Entry) String() string {
+	if e.Caller.File == "" {
+		return fmt.Sprintf("%v: %s", e.Level, e.Message)
+	}
+	return fmt.Sprintf("%v %s:%d: %s", e.Level, filepath.Base(e.Caller.File), e.Caller.Line, e.Message)
+}
+
+func newEntry(c context.Context, m string, a []interface{}) (e Entry) {
+	e.Format = m
+	e.Args = append([]interface{}(nil), a...)
+	e.Message = encoding.Message(m, a...)
+	if c != nil {
+		e.Context = c
+		e.Level, _ = levels.FromContext(c)
+		e.Time, _ = timestamp.FromContext(c)
+		e.Caller, _ = caller.FromContext(c)
+	}
+	return
+}
+
+// Recorder is a logger.Logger that captures log events in memory. It is safe for concurrent use.
+type Recorder struct {
+	mu      sync.Mutex
+	entries []Entry
+}
+
+var _ = logger.Logger(&Recorder{}) // Recorder implements logger.Logger
+
+// NewRecorder returns an empty Recorder.
+func NewRecorder() *Recorder { return &Recorder{} }
+
+// Logf implements logger.Logger
+func (r *Recorder) Logf(c context.Context, m string, a ...interface{}) {
+	e := newEntry(c, m, a)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.entries = append(r.entries, e)
+}
+
+// Interface returns a levels.Interface that logs all levels to the Recorder. Fatal and Panic
+// log events do not exit or panic. The given Options are applied after the defaults.
+func (r *Recorder) Interface(opt ...config.Option) levels.Interface {
+	return config.DefaultConfig.With(append([]config.Option{
+		config.Logger(r),
+		config.Level(levels.Debug),
+		config.OnExit(config.NoExit()),
+		config.OnPanic(config.NoPanic()),
+		config.CallTracking(caller.Tracking{Enabled: true, Depth: CallerDepth}),
+	}, opt...)...)
+}
+
+// Entries returns a copy of the recorded log events.
+func (r *Recorder) Entries() []Entry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Entry(nil), r.entries...)
+}
+
+// Reset discards all recorded log events.
+func (r *Recorder) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.entries = nil
+}
+
+// Find returns the first recorded event at the given level whose message contains `substr`.
+func (r *Recorder) Find(lvl levels.Level, substr string) (Entry, bool) {
+	for _, e := range r.Entries() {
+		if e.Level == lvl && strings.Contains(e.Message, substr) {
+			return e, true
+		}
+	}
+	return Entry{}, false
+}
+
+// Expect returns an error, listing all recorded events, if no event was recorded at the given
+// level with a message containing `substr`.
+func (r *Recorder) Expect(lvl levels.Level, substr string) error {
+	if _, ok := r.Find(lvl, substr); ok {
+		return nil
+	}
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "expected %v log event containing %q, recorded:", lvl, substr)
+	for _, e := range r.Entries() {
+		fmt.Fprintf(&buf, "\n\t%v", e)
+	}
+	return fmt.Errorf("%s", buf.String())
+}
+
+// WrapT returns a levels.Interface that routes all levels to t.Logf. Each message is prefixed
+// with the file and line of the original call site, since t.Logf can only report its own.
+// Fatal log events invoke t.FailNow and so must be generated by the test's own goroutine.
+// Panic log events do not panic. The given Options are applied after the defaults.
+func WrapT(t testing.TB, opt ...config.Option) levels.Interface {
+	logs := logger.Func(func(c context.Context, m string, a ...interface{}) {
+		t.Log(newEntry(c, m, a).String())
+	})
+	return config.DefaultConfig.With(append([]config.Option{
+		config.Logger(logs),
+		config.Level(levels.Debug),
+		config.OnExit(func(int) { t.FailNow() }),
+		config.OnPanic(config.NoPanic()),
+		config.CallTracking(caller.Tracking{Enabled: true, Depth: CallerDepth}),
+	}, opt...)...)
+}
@@ -0,0 +1,107 @@
+/*
+Copyright 2016 James DeFelice
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logtest_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/gologs/log/config"
+	"github.com/gologs/log/levels"
+	. "github.com/gologs/log/logtest"
+)
+
+func TestRecorder(t *testing.T) {
+	var (
+		r    = NewRecorder()
+		logs = r.Interface()
+	)
+	logs.Debugf("dialing %s", "localhost")
+	logs.Error("connection refused")
+	logs.Fatal("giving up")
+
+	if err := r.Expect(levels.Error, "connection refused"); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Expect(levels.Debug, "dialing localhost"); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Expect(levels.Warn, "connection refused"); err == nil {
+		t.Fatal("expected error for missing warning")
+	}
+
+	entries := r.Entries()
+	if len(entries) != 3 {
+		t.Fatalf("expected 3 entries instead of %d", len(entries))
+	}
+	e := entries[1]
+	if f := filepath.Base(e.Caller.File); f != "logtest_test.go" {
+		t.Errorf("unexpected caller file %q", f)
+	}
+	if e.Caller.Line != 34 {
+		t.Errorf("unexpected caller line %d", e.Caller.Line)
+	}
+	if e.Time.IsZero() {
+		t.Errorf("expected a timestamp")
+	}
+	if e.Format != "%s" && e.Format != "" {
+		t.Errorf("unexpected format %q", e.Format)
+	}
+
+	r.Reset()
+	if n := len(r.Entries()); n != 0 {
+		t.Fatalf("expected no entries after reset instead of %d", n)
+	}
+}
+
+func TestWrapT(t *testing.T) {
+	logs := WrapT(t)
+	logs.Infof("hello %s", "world")
+	logs.Panic("does not panic")
+}
+
+func TestQuiet(t *testing.T) {
+	var quiet *Recorder
+	t.Run("quiet", func(t *testing.T) {
+		r, logs := Quiet(t)
+		quiet = r
+		config.Logging().Error("global failure")
+		config.Logging().Fatal("does not exit")
+		logs.Warn("injected warning")
+
+		if err := r.Expect(levels.Error, "global failure"); err != nil {
+			t.Fatal(err)
+		}
+		if err := r.Expect(levels.Warn, "injected warning"); err != nil {
+			t.Fatal(err)
+		}
+		if n := len(r.Entries()); n != 3 {
+			t.Fatalf("expected 3 entries instead of %d", n)
+		}
+	})
+
+	// the global Logging has been restored once the subtest completed
+	r := NewRecorder()
+	defer config.Update(config.Update(config.Logger(r)))
+	config.Logging().Error("after")
+	if err := r.Expect(levels.Error, "after"); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := quiet.Find(levels.Error, "after"); ok {
+		t.Fatal("unexpected entry recorded after the test completed")
+	}
+}
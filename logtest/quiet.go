@@ -0,0 +1,81 @@
+/*
+Copyright 2016 James DeFelice
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logtest
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/gologs/log/config"
+	"github.com/gologs/log/context"
+	"github.com/gologs/log/levels"
+	"github.com/gologs/log/logger"
+)
+
+// quietActive tracks the Recorders of the tests that are Quiet, guarded by quietMu
+var (
+	quietMu      sync.Mutex
+	quietActive  = map[*Recorder]struct{}{}
+	quietRestore func()
+)
+
+// quietLogger copies the log events of the global Logging to the recorders of all Quiet tests,
+// since log events generated via the global Logging can't be attributed to any one test
+var quietLogger = logger.Func(func(c context.Context, m string, a ...interface{}) {
+	quietMu.Lock()
+	recs := make([]*Recorder, 0, len(quietActive))
+	for r := range quietActive {
+		recs = append(recs, r)
+	}
+	quietMu.Unlock()
+	for _, r := range recs {
+		r.Logf(c, m, a...)
+	}
+})
+
+// Quiet silences logging for the duration of a test: the global Logging (see config.Logging)
+// records all levels to the returned Recorder, instead of writing them out, until the test
+// completes (see testing.TB.Cleanup). Fatal and Panic log events neither exit nor panic. The
+// returned Interface records to the Recorder as well (see Recorder.Interface, to which the
+// given Options apply); parallel tests should inject it into the code under test, since log
+// events generated via the global Logging are recorded by the Recorders of all Quiet tests
+// that run concurrently. The global Logging is restored once the last such test completes.
+func Quiet(t testing.TB, opt ...config.Option) (*Recorder, levels.Interface) {
+	r := NewRecorder()
+	quietMu.Lock()
+	if len(quietActive) == 0 {
+		quietRestore = config.Scoped(
+			config.Logger(quietLogger),
+			config.Level(levels.Debug),
+			config.OnExit(config.NoExit()),
+			config.OnPanic(config.NoPanic()),
+		)
+	}
+	quietActive[r] = struct{}{}
+	quietMu.Unlock()
+
+	t.Cleanup(func() {
+		quietMu.Lock()
+		defer quietMu.Unlock()
+		delete(quietActive, r)
+		if len(quietActive) == 0 {
+			quietRestore()
+			quietRestore = nil
+		}
+	})
+	return r, r.Interface(opt...)
+}
@@ -0,0 +1,138 @@
+/*
+Copyright 2016 James DeFelice
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package replay captures log events in memory so that they may be re-emitted later, for
+// example those generated early in process startup, before logging has been configured.
+package replay
+
+import (
+	"sync"
+
+	"github.com/gologs/log/caller"
+	"github.com/gologs/log/config"
+	"github.com/gologs/log/context"
+	"github.com/gologs/log/context/timestamp"
+	"github.com/gologs/log/levels"
+	"github.com/gologs/log/logger"
+)
+
+// DefaultMax is the default number of log events retained by a Buffer.
+const DefaultMax = 1000
+
+type event struct {
+	c context.Context
+	m string
+	a []interface{}
+}
+
+// Buffer is a logger.Logger that retains log events in memory until they're replayed. When
+// full, the oldest log events are discarded. It is safe for concurrent use.
+type Buffer struct {
+	mu      sync.Mutex
+	max     int
+	events  []event
+	dropped int
+}
+
+var _ = logger.Logger(&Buffer{}) // Buffer implements logger.Logger
+
+// NewBuffer returns a Buffer that retains up to `max` log events (or DefaultMax, if not
+// positive).
+func NewBuffer(max int) *Buffer {
+	if max <= 0 {
+		max = DefaultMax
+	}
+	return &Buffer{max: max}
+}
+
+// Logf implements logger.Logger
+func (b *Buffer) Logf(c context.Context, m string, a ...interface{}) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if len(b.events) == b.max {
+		copy(b.events, b.events[1:])
+		b.events = b.events[:len(b.events)-1]
+		b.dropped++
+	}
+	b.events = append(b.events, event{c, m, append([]interface{}(nil), a...)})
+}
+
+// Interface returns a levels.Interface that captures all levels to the Buffer, along with
+// their timestamps and callers. It's intended to be installed via config.SetLogging; callers
+// are tracked at config.DefaultCallerDepth. The given Options are applied after the defaults.
+func (b *Buffer) Interface(opt ...config.Option) levels.Interface {
+	return config.DefaultConfig.With(append([]config.Option{
+		config.Logger(b),
+		config.Level(levels.Debug),
+	}, opt...)...)
+}
+
+// Dropped returns the number of log events that were discarded because the Buffer was full.
+func (b *Buffer) Dropped() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.dropped
+}
+
+// Replay re-emits, in order, the captured log events via `into` (subject to its level
+// threshold) with their original timestamps and callers, then empties the Buffer. Fatal and
+// Panic log events are replayed at levels.Error, so as not to exit or panic again. Returns the
+// number of log events replayed.
+//
+// The original timestamps and callers are carried by the context of each log event, see
+// levels.WithContext: they're lost unless `into` was generated by levels.WithLoggers, as are the
+// Interfaces generated by config.Config.With, in which case log events are timestamped anew.
+func (b *Buffer) Replay(into levels.Interface) int {
+	b.mu.Lock()
+	events := b.events
+	b.events = nil
+	b.mu.Unlock()
+
+	for _, e := range events {
+		orig := e.c
+		i := levels.WithContext(into, func(c context.Context) context.Context {
+			if t, ok := timestamp.FromContext(orig); ok {
+				c = timestamp.NewContext(c, t)
+			}
+			if x, ok := caller.FromContext(orig); ok {
+				c = caller.NewContext(c, x.File, x.Line, x.FuncName)
+			}
+			return c
+		})
+		lvl, _ := levels.FromContext(orig)
+		logAt(i, lvl, e.m, e.a)
+	}
+	return len(events)
+}
+
+func logAt(i levels.Interface, lvl levels.Level, m string, a []interface{}) {
+	var f, p func(string, ...interface{})
+	switch lvl {
+	case levels.Debug:
+		f, p = i.Debugf, func(_ string, a ...interface{}) { i.Debug(a...) }
+	case levels.Warn:
+		f, p = i.Warnf, func(_ string, a ...interface{}) { i.Warn(a...) }
+	case levels.Error, levels.Fatal, levels.Panic:
+		f, p = i.Errorf, func(_ string, a ...interface{}) { i.Error(a...) }
+	default:
+		f, p = i.Infof, func(_ string, a ...interface{}) { i.Info(a...) }
+	}
+	if m == "" {
+		p(m, a...)
+	} else {
+		f(m, a...)
+	}
+}
@@ -0,0 +1,71 @@
+/*
+Copyright 2016 James DeFelice
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package replay_test
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gologs/log/caller"
+	"github.com/gologs/log/config"
+	"github.com/gologs/log/levels"
+	"github.com/gologs/log/logtest"
+	. "github.com/gologs/log/replay"
+)
+
+func TestBuffer(t *testing.T) {
+	var (
+		early    = time.Date(2016, time.March, 1, 0, 0, 0, 0, time.UTC)
+		b        = NewBuffer(3)
+		earlyLog = b.Interface(
+			config.Clock(func() time.Time { return early }),
+			config.CallTracking(caller.Tracking{Enabled: true, Depth: logtest.CallerDepth}),
+		)
+	)
+	earlyLog.Debugf("parsing flags %d", 1)
+	earlyLog.Info("dropped")
+	earlyLog.Infof("flags parsed")
+	earlyLog.Warnf("deprecated flag")
+	earlyLog.Error("bad ", "flag")
+	if b.Dropped() != 2 {
+		t.Fatalf("expected 2 dropped events instead of %d", b.Dropped())
+	}
+
+	rec := logtest.NewRecorder()
+	if n := b.Replay(rec.Interface(config.Level(levels.Info))); n != 3 {
+		t.Fatalf("expected 3 replayed events instead of %d", n)
+	}
+	entries := rec.Entries()
+	if len(entries) != 3 {
+		t.Fatalf("unexpected entries: %v", entries)
+	}
+	for _, e := range entries {
+		if !e.Time.Equal(early) {
+			t.Errorf("expected original timestamp for %v instead of %v", e, e.Time)
+		}
+		if filepath.Base(e.Caller.File) != "replay_test.go" {
+			t.Errorf("expected original caller for %v", e)
+		}
+	}
+	if err := rec.Expect(levels.Error, "bad flag"); err != nil {
+		t.Fatal(err)
+	}
+	if n := b.Replay(rec.Interface()); n != 0 {
+		t.Fatalf("expected empty buffer after replay")
+	}
+}
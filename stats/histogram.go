@@ -0,0 +1,79 @@
+/*
+Copyright 2016 James DeFelice
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package stats
+
+import (
+	"sort"
+	"strconv"
+	"sync/atomic"
+)
+
+// Histogram counts observations in buckets, each of which counts the observations that are
+// less than or equal to its upper bound; it's safe for concurrent use.
+type Histogram struct {
+	bounds []uint64
+	counts []uint64 // counts has a final bucket for observations above all bounds
+	sum    uint64
+}
+
+// NewHistogram returns a Histogram with buckets for the given upper bounds.
+func NewHistogram(bounds ...uint64) *Histogram {
+	b := append([]uint64(nil), bounds...)
+	sort.Slice(b, func(i, j int) bool { return b[i] < b[j] })
+	return &Histogram{bounds: b, counts: make([]uint64, len(b)+1)}
+}
+
+// Observe records an observation.
+func (h *Histogram) Observe(v uint64) {
+	i := sort.Search(len(h.bounds), func(i int) bool { return v <= h.bounds[i] })
+	atomic.AddUint64(&h.counts[i], 1)
+	atomic.AddUint64(&h.sum, v)
+}
+
+// Count returns the number of observations.
+func (h *Histogram) Count() (n uint64) {
+	for i := range h.counts {
+		n += atomic.LoadUint64(&h.counts[i])
+	}
+	return
+}
+
+// Sum returns the sum of the observations.
+func (h *Histogram) Sum() uint64 { return atomic.LoadUint64(&h.sum) }
+
+// CountAtOrBelow returns the number of observations that are less than or equal to the i'th
+// bound.
+func (h *Histogram) CountAtOrBelow(i int) (n uint64) {
+	for j := 0; j <= i && j < len(h.bounds); j++ {
+		n += atomic.LoadUint64(&h.counts[j])
+	}
+	return
+}
+
+// Histogram returns a new Histogram that's registered under the given name, as the counters
+// name+".count", name+".sum", and name+".le_"+bound (cumulative, as per CountAtOrBelow) for
+// each of the given bounds.
+func (r *Registry) Histogram(name string, bounds ...uint64) *Histogram {
+	h := NewHistogram(bounds...)
+	r.Register(name+".count", h.Count)
+	r.Register(name+".sum", h.Sum)
+	for i, b := range h.bounds {
+		i := i
+		r.Register(name+".le_"+strconv.FormatUint(b, 10), func() uint64 { return h.CountAtOrBelow(i) })
+	}
+	return h
+}
@@ -0,0 +1,80 @@
+/*
+Copyright 2016 James DeFelice
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package stats
+
+import (
+	"sync"
+	"time"
+
+	"github.com/gologs/log/context"
+	"github.com/gologs/log/encoding"
+	"github.com/gologs/log/io"
+)
+
+var (
+	// DefaultLatencyBounds are the bucket bounds, in microseconds, of the latency Histograms
+	// registered by Instrument.
+	DefaultLatencyBounds = []uint64{10, 100, 1000, 10000, 100000, 1000000}
+	// DefaultSizeBounds are the bucket bounds, in bytes, of the size Histograms registered by
+	// Instrument.
+	DefaultSizeBounds = []uint64{128, 512, 2048, 8192, 32768, 131072}
+)
+
+// countingStream counts the bytes written to a Stream
+type countingStream struct {
+	io.Stream
+	n uint64
+}
+
+func (s *countingStream) Write(b []byte) (int, error) {
+	n, err := s.Stream.Write(b)
+	s.n += uint64(n)
+	return n, err
+}
+
+var countingStreams = sync.Pool{New: func() interface{} { return &countingStream{} }}
+
+// Instrument returns an encoding.Decorator that records, in Histograms registered with `r`, the
+// time taken to encode and write each log event, as name+".latency_us" (see
+// DefaultLatencyBounds), and the number of bytes written for it, as name+".bytes" (see
+// DefaultSizeBounds). Since it measures the decorators that it wraps, it should be the last of
+// the decorators of a Stream (see config.Encoding). The write time of buffering Streams is only
+// included to the extent that EOM flushes them. A nil Registry selects Default.
+func Instrument(r *Registry, name string) encoding.Decorator {
+	if r == nil {
+		r = Default
+	}
+	var (
+		latency = r.Histogram(name+".latency_us", DefaultLatencyBounds...)
+		size    = r.Histogram(name+".bytes", DefaultSizeBounds...)
+	)
+	return func(op encoding.Marshaler) encoding.Marshaler {
+		return func(c context.Context, w io.Stream, m string, a ...interface{}) error {
+			var (
+				start = time.Now()
+				s     = countingStreams.Get().(*countingStream)
+			)
+			s.Stream, s.n = w, 0
+			err := op(c, s, m, a...)
+			latency.Observe(uint64(time.Since(start) / time.Microsecond))
+			size.Observe(s.n)
+			s.Stream = nil
+			countingStreams.Put(s)
+			return err
+		}
+	}
+}
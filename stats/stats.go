@@ -0,0 +1,153 @@
+/*
+Copyright 2016 James DeFelice
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package stats aggregates the counters of logging components that may discard log events
+// (async queues, rate limiters, samplers, circuit breakers), so that log loss can be quantified,
+// as well as Histograms of the latency and size of encoded log events (see Instrument).
+package stats
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gologs/log/levels"
+)
+
+// Counter is a monotonically increasing count that is safe for concurrent use.
+type Counter struct {
+	n uint64
+}
+
+// Add increments the counter by `n`.
+func (c *Counter) Add(n uint64) { atomic.AddUint64(&c.n, n) }
+
+// Inc increments the counter by one.
+func (c *Counter) Inc() { atomic.AddUint64(&c.n, 1) }
+
+// Load returns the current value of the counter.
+func (c *Counter) Load() uint64 { return atomic.LoadUint64(&c.n) }
+
+// Snapshot maps counter names to their values at some point in time.
+type Snapshot map[string]uint64
+
+// String renders the snapshot as space-separated `name=value` pairs, sorted by name.
+func (s Snapshot) String() string {
+	names := make([]string, 0, len(s))
+	for name := range s {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var buf bytes.Buffer
+	for i, name := range names {
+		if i > 0 {
+			buf.WriteByte(' ')
+		}
+		fmt.Fprintf(&buf, "%s=%d", name, s[name])
+	}
+	return buf.String()
+}
+
+// Equal returns true if both snapshots report the same counter values.
+func (s Snapshot) Equal(other Snapshot) bool {
+	if len(s) != len(other) {
+		return false
+	}
+	for name, n := range s {
+		if m, ok := other[name]; !ok || m != n {
+			return false
+		}
+	}
+	return true
+}
+
+// Registry aggregates named counters. Multiple sources registered with the same name are summed.
+// It is safe for concurrent use.
+type Registry struct {
+	mu      sync.RWMutex
+	sources map[string][]func() uint64
+}
+
+// NewRegistry returns an empty Registry.
+func NewRegistry() *Registry { return &Registry{sources: make(map[string][]func() uint64)} }
+
+// Default is the Registry used by components that aren't configured with one.
+var Default = NewRegistry()
+
+// Register adds a source for the named counter.
+func (r *Registry) Register(name string, f func() uint64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.sources[name] = append(r.sources[name], f)
+}
+
+// Counter returns a new Counter that's registered under the given name.
+func (r *Registry) Counter(name string) *Counter {
+	c := &Counter{}
+	r.Register(name, c.Load)
+	return c
+}
+
+// Stats returns a snapshot of all registered counters.
+func (r *Registry) Stats() Snapshot {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	s := make(Snapshot, len(r.sources))
+	for name, ff := range r.sources {
+		var n uint64
+		for _, f := range ff {
+			n += f()
+		}
+		s[name] = n
+	}
+	return s
+}
+
+// Stats returns a snapshot of the counters of the Default registry.
+func Stats() Snapshot { return Default.Stats() }
+
+// Report logs a snapshot of the registry's counters to `logs` at levels.Info every `interval`,
+// but only when the counters have changed since the last report. The returned func stops
+// reporting. If `interval` isn't positive then nothing is reported.
+func Report(r *Registry, logs levels.Interface, interval time.Duration) (stop func()) {
+	if interval <= 0 {
+		return func() {}
+	}
+	var (
+		done   = make(chan struct{})
+		once   sync.Once
+		ticker = time.NewTicker(interval)
+	)
+	go func() {
+		defer ticker.Stop()
+		var last Snapshot
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				if s := r.Stats(); !s.Equal(last) {
+					last = s
+					logs.Infof("log stats: %v", s)
+				}
+			}
+		}
+	}()
+	return func() { once.Do(func() { close(done) }) }
+}
@@ -0,0 +1,99 @@
+/*
+Copyright 2016 James DeFelice
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package stats_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gologs/log/config"
+	"github.com/gologs/log/io"
+	"github.com/gologs/log/levels"
+	"github.com/gologs/log/logtest"
+	. "github.com/gologs/log/stats"
+)
+
+func TestRegistry(t *testing.T) {
+	var (
+		r = NewRegistry()
+		a = r.Counter("async.dropped")
+		b = r.Counter("async.dropped")
+	)
+	r.Register("sampler.dropped", func() uint64 { return 7 })
+	a.Inc()
+	b.Add(2)
+	s := r.Stats()
+	if expected := "async.dropped=3 sampler.dropped=7"; s.String() != expected {
+		t.Fatalf("expected %q instead of %q", expected, s)
+	}
+	if !s.Equal(r.Stats()) {
+		t.Fatal("expected equal snapshots")
+	}
+	a.Inc()
+	if s.Equal(r.Stats()) {
+		t.Fatal("expected snapshots to differ")
+	}
+}
+
+func TestReport(t *testing.T) {
+	var (
+		r    = NewRegistry()
+		rec  = logtest.NewRecorder()
+		c    = r.Counter("dropped")
+		stop = Report(r, rec.Interface(), time.Millisecond)
+	)
+	defer stop()
+	c.Inc()
+	for i := 0; rec.Expect(levels.Info, "log stats: dropped=1") != nil; i++ {
+		if i > 1000 {
+			t.Fatal(rec.Expect(levels.Info, "log stats: dropped=1"))
+		}
+		time.Sleep(time.Millisecond)
+	}
+	stop()
+	stop()
+
+	idle := logtest.NewRecorder()
+	stop = Report(r, idle.Interface(), 0)
+	time.Sleep(10 * time.Millisecond)
+	stop()
+	if entries := idle.Entries(); len(entries) != 0 {
+		t.Fatalf("expected no reports without an interval instead of %v", entries)
+	}
+}
+
+func TestInstrument(t *testing.T) {
+	var (
+		r    = NewRegistry()
+		logs = config.DefaultConfig.With(
+			config.Stream(io.Null()),
+			config.Encoding(Instrument(r, "stream")),
+		)
+	)
+	logs.Infof("%s", strings.Repeat("x", 100))
+	logs.Infof("%s", strings.Repeat("x", 1000))
+
+	s := r.Stats()
+	if s["stream.bytes.count"] != 2 || s["stream.bytes.sum"] != 1100 ||
+		s["stream.bytes.le_128"] != 1 || s["stream.bytes.le_2048"] != 2 {
+		t.Fatalf("unexpected size histogram %v", s)
+	}
+	if s["stream.latency_us.count"] != 2 {
+		t.Fatalf("unexpected latency histogram %v", s)
+	}
+}
@@ -0,0 +1,76 @@
+/*
+Copyright 2016 James DeFelice
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package log
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/gologs/log/config"
+)
+
+// TimingHook observes the completion of operations timed via Timed, for example to record the
+// elapsed times in a histogram. It's invoked whether or not the completion is actually logged.
+type TimingHook func(msg string, elapsed time.Duration, err error)
+
+type timingHolder struct{ hook TimingHook }
+
+var timingHook atomic.Value // timingHook holds a *timingHolder
+
+func init() {
+	timingHook.Store(&timingHolder{})
+}
+
+// SetTimingHook changes the TimingHook invoked by Timed and returns the previous one; nil
+// disables the hook.
+func SetTimingHook(h TimingHook) (old TimingHook) {
+	return timingHook.Swap(&timingHolder{h}).(*timingHolder).hook
+}
+
+// Timed returns a func that, when invoked, logs the completion of the operation described by
+// `msg` at levels.Info, along with the time elapsed since Timed was called per the configured
+// Clock (see config.Now). If the func is given a pointer to a non-nil error then the completion
+// is logged at levels.Error, along with the error. It's typically invoked via defer, so that the
+// log event is attributed to the timed function:
+//
+//	func sync() (err error) {
+//		defer log.Timed("sync")(&err)
+//		...
+//	}
+func Timed(msg string) func(errp ...*error) {
+	start := config.Now()
+	return func(errp ...*error) {
+		var (
+			elapsed = config.Now().Sub(start)
+			err     error
+		)
+		for _, p := range errp {
+			if p != nil && *p != nil {
+				err = *p
+				break
+			}
+		}
+		if h := timingHook.Load().(*timingHolder).hook; h != nil {
+			h(msg, elapsed, err)
+		}
+		if err != nil {
+			config.Logging().Errorf("%s failed after %v: %v", msg, elapsed, err)
+		} else {
+			config.Logging().Infof("%s took %v", msg, elapsed)
+		}
+	}
+}
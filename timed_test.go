@@ -0,0 +1,89 @@
+/*
+Copyright 2016 James DeFelice
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package log_test
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/gologs/log"
+	"github.com/gologs/log/caller"
+	"github.com/gologs/log/config"
+	"github.com/gologs/log/context"
+	"github.com/gologs/log/levels"
+	"github.com/gologs/log/logger"
+)
+
+func TestTimed(t *testing.T) {
+	type event struct {
+		lvl    levels.Level
+		msg    string
+		caller string
+	}
+	var (
+		events []event
+		hooked []error
+		sink   = logger.Func(func(c context.Context, m string, a ...interface{}) {
+			lvl, _ := levels.FromContext(c)
+			x, _ := caller.FromContext(c)
+			events = append(events, event{lvl, m, filepath.Base(x.File)})
+		})
+		now  = time.Date(2016, time.March, 1, 0, 0, 0, 0, time.UTC)
+		tick = func() time.Time {
+			now = now.Add(time.Second)
+			return now
+		}
+		oops = errors.New("oops")
+		work = func(fail bool) (err error) {
+			defer Timed("work")(&err)
+			if fail {
+				err = oops
+			}
+			return
+		}
+	)
+	restore := config.Update(config.Logger(sink), config.Level(levels.Info), config.Clock(tick))
+	defer config.Update(restore)
+	defer SetTimingHook(SetTimingHook(func(msg string, d time.Duration, err error) {
+		if (msg != "work" && msg != "other") || d != time.Second {
+			t.Errorf("unexpected timing %q %v", msg, d)
+		}
+		hooked = append(hooked, err)
+	}))
+
+	_ = work(false)
+	_ = work(true)
+	Timed("other")()
+
+	if len(events) != 3 || len(hooked) != 3 || hooked[0] != nil || hooked[1] != oops {
+		t.Fatalf("unexpected events %+v, hooked %v", events, hooked)
+	}
+	for i, e := range events {
+		if e.caller != "timed_test.go" {
+			t.Errorf("unexpected caller %q for event %d", e.caller, i)
+		}
+	}
+	if e := events[0]; e.lvl != levels.Info || !strings.HasSuffix(e.msg, " took %v") {
+		t.Errorf("unexpected event %+v", e)
+	}
+	if e := events[1]; e.lvl != levels.Error || !strings.Contains(e.msg, " failed after ") {
+		t.Errorf("unexpected event %+v", e)
+	}
+}